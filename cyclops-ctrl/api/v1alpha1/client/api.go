@@ -45,3 +45,17 @@ func (c *CyclopsV1Alpha1Client) TemplateStore(namespace string) TemplateStoreInt
 		ns:         namespace,
 	}
 }
+
+func (c *CyclopsV1Alpha1Client) Clusters(namespace string) ClusterInterface {
+	return &clusterClient{
+		restClient: c.restClient,
+		ns:         namespace,
+	}
+}
+
+func (c *CyclopsV1Alpha1Client) ModuleRevisions(namespace string) ModuleRevisionInterface {
+	return &moduleRevisionClient{
+		restClient: c.restClient,
+		ns:         namespace,
+	}
+}
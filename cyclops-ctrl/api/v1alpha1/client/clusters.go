@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+type ClusterInterface interface {
+	List(opts metav1.ListOptions) ([]cyclopsv1alpha1.Cluster, error)
+	Get(name string) (*cyclopsv1alpha1.Cluster, error)
+}
+
+type clusterClient struct {
+	restClient rest.Interface
+	ns         string
+}
+
+func (c *clusterClient) List(opts metav1.ListOptions) ([]cyclopsv1alpha1.Cluster, error) {
+	result := cyclopsv1alpha1.ClusterList{}
+	err := c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource("clusters").
+		Do(context.Background()).
+		Into(&result)
+
+	return result.Items, err
+}
+
+func (c *clusterClient) Get(name string) (*cyclopsv1alpha1.Cluster, error) {
+	result := cyclopsv1alpha1.Cluster{}
+	err := c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource("clusters").
+		Name(name).
+		Do(context.Background()).
+		Into(&result)
+
+	return &result, err
+}
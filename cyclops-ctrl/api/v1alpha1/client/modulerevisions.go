@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+type ModuleRevisionInterface interface {
+	List(opts metav1.ListOptions) ([]cyclopsv1alpha1.ModuleRevision, error)
+}
+
+type moduleRevisionClient struct {
+	restClient rest.Interface
+	ns         string
+}
+
+func (c *moduleRevisionClient) List(opts metav1.ListOptions) ([]cyclopsv1alpha1.ModuleRevision, error) {
+	result := cyclopsv1alpha1.ModuleRevisionList{}
+	err := c.restClient.
+		Get().
+		Namespace(c.ns).
+		Resource("modulerevisions").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do(context.Background()).
+		Into(&result)
+
+	return result.Items, err
+}
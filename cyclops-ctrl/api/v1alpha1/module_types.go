@@ -30,6 +30,191 @@ type ModuleSpec struct {
 	TargetNamespace string               `json:"targetNamespace"`
 	TemplateRef     TemplateRef          `json:"template"`
 	Values          apiextensionsv1.JSON `json:"values"`
+
+	// ValuesFrom lists keys of ConfigMaps and Secrets in the module namespace merged into the module values
+	// before rendering, in order. Values read from them are never stored in the module.
+	// +kubebuilder:validation:Optional
+	ValuesFrom []ValuesReference `json:"valuesFrom,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	SyncPolicy *SyncPolicy `json:"syncPolicy,omitempty"`
+
+	// DependsOn lists modules that have to be ready before this module is reconciled
+	// +kubebuilder:validation:Optional
+	DependsOn []ModuleReference `json:"dependsOn,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	DeletionPolicy *DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	UpgradePolicy *UpgradePolicy `json:"upgradePolicy,omitempty"`
+
+	// Patches are applied to resources rendered from the module template before they are applied to the cluster
+	// +kubebuilder:validation:Optional
+	Patches []Patch `json:"patches,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	RevisionHistory *RevisionHistoryPolicy `json:"revisionHistory,omitempty"`
+
+	// Cluster is the name of the Cluster the module resources are deployed to. Resources are deployed to the
+	// cluster Cyclops runs in if it is empty.
+	// +kubebuilder:validation:Optional
+	Cluster string `json:"cluster,omitempty"`
+}
+
+type ValuesReferenceKind string
+
+const (
+	ValuesReferenceKindConfigMap ValuesReferenceKind = "ConfigMap"
+	ValuesReferenceKindSecret    ValuesReferenceKind = "Secret"
+)
+
+// ValuesReference references a key of a ConfigMap or Secret in the module namespace holding module values
+type ValuesReference struct {
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind ValuesReferenceKind `json:"kind"`
+	Name string              `json:"name"`
+
+	// Key of the ConfigMap or Secret data. Defaults to values.yaml.
+	// +kubebuilder:validation:Optional
+	Key string `json:"key,omitempty"`
+
+	// TargetPath is a dot separated path of the module value the key is set to as a string, e.g.
+	// database.password. The key is parsed as YAML and merged into the module values if it is empty.
+	// +kubebuilder:validation:Optional
+	TargetPath string `json:"targetPath,omitempty"`
+
+	// Optional skips the reference if the ConfigMap, Secret or key do not exist instead of failing
+	// the reconciliation
+	// +kubebuilder:validation:Optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// Patch is a strategic merge or a JSON6902 patch of rendered module resources
+type Patch struct {
+	// Patch is a strategic merge patch, or a JSON6902 patch if it is a list of operations, in YAML or JSON
+	Patch string `json:"patch"`
+
+	// Target selects resources the patch is applied to. Strategic merge patches without a target are applied
+	// to the resource with the kind and name of the patch.
+	// +kubebuilder:validation:Optional
+	Target *PatchTarget `json:"target,omitempty"`
+}
+
+// PatchTarget selects rendered module resources by all of its set fields
+type PatchTarget struct {
+	// +kubebuilder:validation:Optional
+	Group string `json:"group,omitempty"`
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+	// +kubebuilder:validation:Optional
+	Kind string `json:"kind,omitempty"`
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	// +kubebuilder:validation:Optional
+	LabelSelector string `json:"labelSelector,omitempty"`
+}
+
+// ModuleReference references a module in the same namespace
+type ModuleReference struct {
+	Name string `json:"name"`
+}
+
+// SyncPolicy controls how the module controller applies rendered resources to the cluster
+type SyncPolicy struct {
+	// Prune deletes resources labeled as part of the module that are no longer rendered from its template.
+	// Individual resources can opt out by setting the cyclops-ui.com/prune annotation to "false".
+	// +kubebuilder:validation:Optional
+	Prune bool `json:"prune,omitempty"`
+
+	// ForceConflicts takes ownership of fields managed by other field managers (HPAs, other controllers,
+	// kubectl users) when applying module resources. Conflicts are reported in the module status otherwise.
+	// +kubebuilder:validation:Optional
+	ForceConflicts bool `json:"forceConflicts,omitempty"`
+
+	// SelfHeal reverts changes made to module resources outside of Cyclops. When disabled, such changes are
+	// only reported in the module status as drift and are kept until the module is reconciled again.
+	// +kubebuilder:validation:Optional
+	SelfHeal bool `json:"selfHeal,omitempty"`
+
+	// ProgressDeadlineSeconds is the time module resources have to become healthy after they were applied
+	// before the module is marked as degraded. Defaults to 600 seconds.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	ProgressDeadlineSeconds *int64 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+type DeletionPolicyType string
+
+const (
+	DeletionPolicyDelete DeletionPolicyType = "Delete"
+	DeletionPolicyOrphan DeletionPolicyType = "Orphan"
+)
+
+// DeletionPolicy controls what happens to module resources when the module is deleted
+type DeletionPolicy struct {
+	// Policy is Delete to delete module resources together with the module, or Orphan to keep all of them.
+	// Defaults to Delete.
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +kubebuilder:validation:Optional
+	Policy DeletionPolicyType `json:"policy,omitempty"`
+
+	// RetainKinds lists kinds of resources that are kept when the module is deleted with the Delete policy,
+	// e.g. PersistentVolumeClaim. Kinds can be qualified with their group as in apps/StatefulSet.
+	// +kubebuilder:validation:Optional
+	RetainKinds []string `json:"retainKinds,omitempty"`
+}
+
+type UpgradeMode string
+
+const (
+	UpgradeModeNone  UpgradeMode = "None"
+	UpgradeModePatch UpgradeMode = "Patch"
+	UpgradeModeMinor UpgradeMode = "Minor"
+	UpgradeModeAny   UpgradeMode = "Any"
+)
+
+// UpgradePolicy controls automatic upgrades of the module template to new versions matching its template
+// version, like new commits of a branch or new chart releases matching a semver constraint
+type UpgradePolicy struct {
+	// Mode limits automatic upgrades of semantic versions to patch or minor releases, or allows any upgrade.
+	// New git commits are upgraded to by every mode except None. Defaults to None.
+	// +kubebuilder:validation:Enum=None;Patch;Minor;Any
+	// +kubebuilder:validation:Optional
+	Mode UpgradeMode `json:"mode,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+}
+
+// RevisionHistoryPolicy controls how many ModuleRevisions of the module are kept. The revision of the current
+// module generation is always kept.
+type RevisionHistoryPolicy struct {
+	// Limit is the number of revisions of previous module generations kept. Defaults to 10.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	Limit *int32 `json:"limit,omitempty"`
+
+	// MaxAge deletes revisions older than it, e.g. 720h. Revisions are kept regardless of their age if it is empty.
+	// +kubebuilder:validation:Optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// MaintenanceWindow is a recurring time window in UTC automatic upgrades are limited to
+type MaintenanceWindow struct {
+	// Days of the week the window opens on. The window opens every day if empty.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:items:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+	Days []string `json:"days,omitempty"`
+
+	// Start is the time the window opens at in the 24-hour HH:MM format
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// Duration is how long the window stays open, e.g. 2h
+	Duration metav1.Duration `json:"duration"`
 }
 
 type ModuleValue struct {
@@ -54,6 +239,18 @@ const (
 	MCPServerModuleLabel = "cyclops-ui.com/mcp-server"
 
 	ResourceFinalizer = "cyclops-ui.com/module-resources"
+
+	PruneAnnotation = "cyclops-ui.com/prune"
+
+	// KeepAnnotation set to "true" on a module resource keeps it in the cluster when the module is deleted
+	// or the resource is pruned
+	KeepAnnotation = "cyclops-ui.com/keep"
+
+	// SyncWaveAnnotation sets the wave a rendered resource is applied in. Waves are applied in ascending order,
+	// each one after all resources of the previous waves are healthy. Resources without it are in wave 0.
+	SyncWaveAnnotation = "cyclops-ui.com/sync-wave"
+
+	ReconciledAtAnnotation = "cyclops/reconciled-at"
 )
 
 type GitOpsWriteDestination struct {
@@ -84,14 +281,43 @@ type TemplateGitRef struct {
 type ReconciliationStatusState string
 
 const (
-	Unknown   ReconciliationStatusState = "unknown"
-	Succeeded ReconciliationStatusState = "succeeded"
-	Failed    ReconciliationStatusState = "failed"
+	Unknown     ReconciliationStatusState = "unknown"
+	Succeeded   ReconciliationStatusState = "succeeded"
+	Failed      ReconciliationStatusState = "failed"
+	Drifted     ReconciliationStatusState = "drifted"
+	Progressing ReconciliationStatusState = "progressing"
+	Degraded    ReconciliationStatusState = "degraded"
+)
+
+// Condition types reported in the module status. DependenciesReady, TemplateFetched, Rendered and Applied follow
+// the stages of a reconciliation, Ready is true only if all of them succeeded and all applied resources are healthy.
+const (
+	ConditionDependenciesReady = "DependenciesReady"
+	ConditionTemplateFetched   = "TemplateFetched"
+	ConditionRendered          = "Rendered"
+	ConditionApplied           = "Applied"
+	ConditionReady             = "Ready"
+)
+
+// Condition reasons reported in the module status
+const (
+	ReasonSucceeded            = "Succeeded"
+	ReasonTemplateFetchFailed  = "TemplateFetchFailed"
+	ReasonRenderFailed         = "RenderFailed"
+	ReasonInvalidValues        = "InvalidValues"
+	ReasonApplyFailed          = "ApplyFailed"
+	ReasonDrifted              = "Drifted"
+	ReasonProgressing          = "Progressing"
+	ReasonDegraded             = "Degraded"
+	ReasonDependenciesNotReady = "DependenciesNotReady"
+	ReasonDependencyCycle      = "DependencyCycle"
+	ReasonDeletionBlocked      = "DeletionBlocked"
+	ReasonClusterUnavailable   = "ClusterUnavailable"
 )
 
 type ReconciliationStatus struct {
 	// +kubebuilder:validation:Optional
-	// +kubebuilder:validation:Enum=unknown;succeeded;failed
+	// +kubebuilder:validation:Enum=unknown;succeeded;failed;drifted;progressing;degraded
 	// +kubebuilder:default:=unknown
 	Status ReconciliationStatusState `json:"status,omitempty"`
 	// +kubebuilder:validation:Optional
@@ -106,6 +332,63 @@ type GroupVersionResource struct {
 	Resource string `json:"resource"`
 }
 
+// DriftedResource is a module resource whose live state differs from the state rendered from the module template
+type DriftedResource struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	// Fields lists paths of fields that differ from the rendered resource, e.g. .spec.replicas
+	// +kubebuilder:validation:Optional
+	Fields []string `json:"fields,omitempty"`
+	// Missing is set if the resource was deleted from the cluster
+	// +kubebuilder:validation:Optional
+	Missing bool `json:"missing,omitempty"`
+	// Conflicts lists fields of the rendered resource owned by other field managers with a different value. They
+	// are not reverted by self-heal unless the module forces conflicts.
+	// +kubebuilder:validation:Optional
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+type HookPhase string
+
+const (
+	HookPhaseRunning   HookPhase = "Running"
+	HookPhaseSucceeded HookPhase = "Succeeded"
+	HookPhaseFailed    HookPhase = "Failed"
+)
+
+// HookStatus is the outcome of the last run of a Helm hook rendered from the module template
+type HookStatus struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Event is the hook event the hook ran for, e.g. pre-install
+	Event string `json:"event"`
+	// +kubebuilder:validation:Enum=Running;Succeeded;Failed
+	Phase HookPhase `json:"phase"`
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+	// ManifestHash identifies the rendered manifest the hook ran for
+	ManifestHash string `json:"manifestHash"`
+	// StartedAt is the time the hook was created. Running hooks fail if they do not complete within the hook timeout.
+	// +kubebuilder:validation:Optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	// +kubebuilder:validation:Optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// SyncWaveStatus is a sync wave of the module manifest that was applied and is not healthy yet
+type SyncWaveStatus struct {
+	Wave int `json:"wave"`
+	// ManifestHash identifies the rendered manifest the wave was applied for
+	ManifestHash string `json:"manifestHash"`
+	// StartedAt is the time the wave was first applied. Resources of the wave have the progress deadline from
+	// this time to become healthy.
+	StartedAt metav1.Time `json:"startedAt"`
+}
+
 // ModuleStatus defines the observed state of Module
 type ModuleStatus struct {
 	ReconciliationStatus    *ReconciliationStatus `json:"reconciliationStatus,omitempty"`
@@ -114,6 +397,44 @@ type ModuleStatus struct {
 	ManagedGVRs []GroupVersionResource `json:"managedGVRs,omitempty"`
 	// +kubebuilder:validation:Optional
 	IconURL string `json:"iconURL,omitempty"`
+	// +kubebuilder:validation:Optional
+	DriftedResources []DriftedResource `json:"driftedResources,omitempty"`
+	// AppliedManifestHash identifies the rendered manifest that was last applied successfully
+	// +kubebuilder:validation:Optional
+	AppliedManifestHash string `json:"appliedManifestHash,omitempty"`
+	// LastAppliedTime is the time the manifest identified by AppliedManifestHash was first applied. Module
+	// resources have the progress deadline from this time to become healthy.
+	// +kubebuilder:validation:Optional
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+	// PendingSyncWave is the sync wave whose resources have to become healthy before the following waves are
+	// applied. It is cleared once all waves of the manifest were applied.
+	// +kubebuilder:validation:Optional
+	PendingSyncWave *SyncWaveStatus `json:"pendingSyncWave,omitempty"`
+	// ObservedGeneration is the module generation the status was computed for
+	// +kubebuilder:validation:Optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// +kubebuilder:validation:Optional
+	Hooks []HookStatus `json:"hooks,omitempty"`
+	// +kubebuilder:validation:Optional
+	TemplateUpdate *TemplateUpdateStatus `json:"templateUpdate,omitempty"`
+}
+
+// TemplateUpdateStatus is the outcome of the last check for new versions of the module template
+type TemplateUpdateStatus struct {
+	// AvailableVersion is the version the module template version currently resolves to, if it is newer
+	// than the resolved version of the module
+	// +kubebuilder:validation:Optional
+	AvailableVersion string `json:"availableVersion,omitempty"`
+	// Message describes why the check failed or the last automatic upgrade
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+	// LastCheckTime is the time of the check that found the current outcome. The status is updated only
+	// when the outcome of a check changes.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
 }
 
 type HistoryTemplateRef struct {
@@ -126,6 +447,8 @@ type HistoryTemplateRef struct {
 	SourceType TemplateSourceType `json:"sourceType,omitempty"`
 }
 
+// HistoryEntry is a previous module generation. Module revisions are stored as ModuleRevisions, history entries
+// are only read for modules updated before.
 type HistoryEntry struct {
 	Generation int64 `json:"generation"`
 
@@ -144,6 +467,8 @@ type HistoryEntry struct {
 //+kubebuilder:printcolumn:name="Template version",type=string,JSONPath=`.spec.template.version`,priority=1
 //+kubebuilder:printcolumn:name="Template resolved version",type=string,JSONPath=`.status.templateResolvedVersion`,priority=1
 //+kubebuilder:printcolumn:name="Reconciliation Status",type=string,JSONPath=`.status.reconciliationStatus.status`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Observed Generation",type=integer,JSONPath=`.status.observedGeneration`,priority=1
 
 // Module is the Schema for the modules API
 type Module struct {
@@ -0,0 +1,84 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ModuleRevisionModuleLabel is set on module revisions to the name of their module
+	ModuleRevisionModuleLabel = "cyclops-ui.com/module"
+
+	// AuthorAnnotation is set on modules to the user that last changed them through the Cyclops API, as
+	// reported by an authenticating proxy in front of it
+	AuthorAnnotation = "cyclops-ui.com/author"
+)
+
+// ModuleRevisionSpec is a snapshot of a module generation that was applied to the cluster
+type ModuleRevisionSpec struct {
+	// Module is the name of the module in the revision namespace
+	Module string `json:"module"`
+
+	// Generation is the module generation the snapshot was taken of
+	Generation int64 `json:"generation"`
+
+	// +kubebuilder:validation:Optional
+	TargetNamespace string `json:"targetNamespace"`
+
+	// TemplateRef references the template with the version it was resolved to
+	TemplateRef HistoryTemplateRef   `json:"template"`
+	Values      apiextensionsv1.JSON `json:"values"`
+
+	// ManifestHash identifies the manifest rendered from the revision that was applied
+	// +kubebuilder:validation:Optional
+	ManifestHash string `json:"manifestHash,omitempty"`
+
+	// Author is the user that changed the module to this revision through the Cyclops API
+	// +kubebuilder:validation:Optional
+	Author string `json:"author,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="Module",type=string,JSONPath=`.spec.module`
+//+kubebuilder:printcolumn:name="Generation",type=integer,JSONPath=`.spec.generation`
+//+kubebuilder:printcolumn:name="Template version",type=string,JSONPath=`.spec.template.version`
+//+kubebuilder:printcolumn:name="Author",type=string,JSONPath=`.spec.author`,priority=1
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ModuleRevision is a revision of a module the module can be rolled back to. Revisions are created by the module
+// controller once a module generation is applied, and are deleted together with their module.
+type ModuleRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ModuleRevisionSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ModuleRevisionList contains a list of ModuleRevision
+type ModuleRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModuleRevision `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModuleRevision{}, &ModuleRevisionList{})
+}
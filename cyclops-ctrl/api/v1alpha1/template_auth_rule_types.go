@@ -31,8 +31,20 @@ type TemplateAuthRuleSpec struct {
 
 	Repo string `json:"repo"`
 
-	Username v1.SecretKeySelector `json:"username"`
-	Password v1.SecretKeySelector `json:"password"`
+	// +kubebuilder:validation:Optional
+	Username v1.SecretKeySelector `json:"username,omitempty"`
+	// +kubebuilder:validation:Optional
+	Password v1.SecretKeySelector `json:"password,omitempty"`
+
+	// SSHPrivateKey references a PEM encoded private key, like a deploy key, used to access git repos over SSH.
+	// Repos with HTTP URLs are accessed over SSH too if the rule has no username and password.
+	// +kubebuilder:validation:Optional
+	SSHPrivateKey *v1.SecretKeySelector `json:"sshPrivateKey,omitempty"`
+
+	// SSHKnownHosts references known_hosts entries used to verify the host key of git servers accessed over SSH.
+	// The known_hosts file of the controller container is used if it is not set.
+	// +kubebuilder:validation:Optional
+	SSHKnownHosts *v1.SecretKeySelector `json:"sshKnownHosts,omitempty"`
 }
 
 //+kubebuilder:object:root=true
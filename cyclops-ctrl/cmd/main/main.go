@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/git"
@@ -14,6 +15,7 @@ import (
 	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/template/render"
 
 	_ "github.com/joho/godotenv/autoload"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -30,6 +32,7 @@ import (
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/modulecontroller"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/prometheus"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/telemetry"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/webhooks"
 	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
 
 	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
@@ -75,11 +78,13 @@ func main() {
 	telemetryClient.InstanceStart()
 
 	watchNamespace := getWatchNamespace()
+	watchNamespaces := getWatchNamespaces()
 	helmWatchNamespace := getHelmWatchNamespace()
 	moduleTargetNamespace := getModuleTargetNamespace()
 
 	k8sClient, err := k8sclient.New(
 		watchNamespace,
+		watchNamespaces,
 		helmWatchNamespace,
 		moduleTargetNamespace,
 		zap.New(zap.UseFlagOptions(&opts)),
@@ -91,9 +96,15 @@ func main() {
 
 	credsResolver := auth.NewTemplatesResolver(k8sClient)
 
+	templatesCache, err := getTemplatesCache()
+	if err != nil {
+		setupLog.Error(err, "unable to set up template cache")
+		os.Exit(1)
+	}
+
 	templatesRepo := template.NewRepo(
 		credsResolver,
-		cache.NewInMemoryTemplatesCache(),
+		templatesCache,
 	)
 
 	monitor, err := prometheus.NewMonitor(setupLog)
@@ -101,14 +112,14 @@ func main() {
 		setupLog.Error(err, "failed to set up prom monitor")
 	}
 
-	renderer := render.NewRenderer(k8sClient)
+	renderer := render.NewRenderer(k8sClient, getLookupKinds())
 
 	prometheus.StartCacheMetricsUpdater(&monitor, templatesRepo.ReturnCache(), 10*time.Second, setupLog)
 
 	helmReleaseClient := helm.NewReleaseClient(helmWatchNamespace, k8sClient)
 	gitWriteClient := git.NewWriteClient(credsResolver, getCommitMessageTemplate(), setupLog)
 
-	handler, err := handler.New(templatesRepo, k8sClient, helmReleaseClient, renderer, gitWriteClient, moduleTargetNamespace, telemetryClient, monitor)
+	handler, err := handler.New(templatesRepo, k8sClient, helmReleaseClient, renderer, gitWriteClient, moduleTargetNamespace, getTrustedAuthHeader(), telemetryClient, monitor)
 	if err != nil {
 		panic(err)
 	}
@@ -127,9 +138,7 @@ func main() {
 			Port: 9443,
 		}),
 		Cache: ctrlCache.Options{
-			DefaultNamespaces: map[string]ctrlCache.Config{
-				watchNamespace: {},
-			},
+			DefaultNamespaces: getCacheNamespaces(watchNamespace, watchNamespaces),
 		},
 	})
 	if err != nil {
@@ -146,10 +155,17 @@ func main() {
 		getMaxConcurrentReconciles(),
 		telemetryClient,
 		monitor,
+		mgr.GetEventRecorderFor("module-controller"),
 	)).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Module")
 		os.Exit(1)
 	}
+	if getEnvBool("ENABLE_WEBHOOKS") {
+		if err = webhooks.SetupWithManager(mgr, templatesRepo, renderer, moduleTargetNamespace); err != nil {
+			setupLog.Error(err, "unable to create webhooks")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -188,6 +204,74 @@ func getWatchNamespace() string {
 	return value
 }
 
+// getWatchNamespaces returns namespaces watched for modules in addition to WATCH_NAMESPACE. WATCH_NAMESPACES is
+// a comma separated list of namespaces, or * to watch modules in all namespaces.
+func getWatchNamespaces() []string {
+	namespaces := make([]string, 0)
+	for _, namespace := range strings.Split(os.Getenv("WATCH_NAMESPACES"), ",") {
+		namespace = strings.TrimSpace(namespace)
+		if len(namespace) == 0 {
+			continue
+		}
+
+		if namespace == "*" {
+			namespace = metav1.NamespaceAll
+		}
+
+		namespaces = append(namespaces, namespace)
+	}
+
+	return namespaces
+}
+
+// getCacheNamespaces returns namespaces cached by the manager. All namespaces are cached if modules are watched
+// in all namespaces.
+func getCacheNamespaces(watchNamespace string, watchNamespaces []string) map[string]ctrlCache.Config {
+	namespaces := map[string]ctrlCache.Config{
+		watchNamespace: {},
+	}
+
+	for _, namespace := range watchNamespaces {
+		if namespace == metav1.NamespaceAll {
+			return nil
+		}
+
+		namespaces[namespace] = ctrlCache.Config{}
+	}
+
+	return namespaces
+}
+
+// getLookupKinds returns kinds the Helm lookup function can read in module templates. LOOKUP_KINDS is a comma
+// separated list of kinds, optionally qualified with their group. Lookups return no resources unless kinds are
+// set, and are disabled with DISABLE_LOOKUP regardless of LOOKUP_KINDS.
+func getLookupKinds() []string {
+	if getEnvBool("DISABLE_LOOKUP") {
+		return nil
+	}
+
+	value := os.Getenv("LOOKUP_KINDS")
+	if value == "" {
+		return nil
+	}
+
+	kinds := make([]string, 0)
+	for _, kind := range strings.Split(value, ",") {
+		kind = strings.TrimSpace(kind)
+		if len(kind) != 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	return kinds
+}
+
+// getTrustedAuthHeader returns the request header the authenticating proxy in front of Cyclops sets to the user
+// name. Only set TRUSTED_AUTH_HEADER if all requests pass the proxy, which has to overwrite the header.
+func getTrustedAuthHeader() string {
+	return strings.TrimSpace(os.Getenv("TRUSTED_AUTH_HEADER"))
+}
+
 func getModuleTargetNamespace() string {
 	return os.Getenv("MODULE_TARGET_NAMESPACE")
 }
@@ -214,6 +298,43 @@ func getMaxConcurrentReconciles() int {
 	return value
 }
 
+// getTemplatesCache returns the template cache set with TEMPLATE_CACHE; memory by default, disk or redis.
+// Disk and Redis caches keep templates on immutable versions between restarts.
+func getTemplatesCache() (cache.Templates, error) {
+	switch os.Getenv("TEMPLATE_CACHE") {
+	case "", "memory":
+		return cache.NewInMemoryTemplatesCache(), nil
+	case "disk":
+		// the cache dir should be on a persistent volume, so there is no default in the ephemeral container filesystem
+		dir := os.Getenv("TEMPLATE_CACHE_DIR")
+		if dir == "" {
+			return cache.Templates{}, fmt.Errorf("TEMPLATE_CACHE_DIR is required with the disk template cache")
+		}
+
+		return cache.NewDiskTemplatesCache(dir, getTemplateCacheMaxSize())
+	case "redis":
+		return cache.NewRedisTemplatesCache(os.Getenv("TEMPLATE_CACHE_REDIS_URL"))
+	default:
+		return cache.Templates{}, fmt.Errorf("unknown TEMPLATE_CACHE %v; should be memory, disk or redis", os.Getenv("TEMPLATE_CACHE"))
+	}
+}
+
+// getTemplateCacheMaxSize returns the max size of the disk template cache in bytes, set in megabytes with
+// TEMPLATE_CACHE_MAX_SIZE_MB. Defaults to 1GB.
+func getTemplateCacheMaxSize() int64 {
+	strValue := os.Getenv("TEMPLATE_CACHE_MAX_SIZE_MB")
+	if strValue == "" {
+		return 1 << 30
+	}
+
+	value, err := strconv.ParseInt(strValue, 10, 64)
+	if err != nil || value <= 0 {
+		return 1 << 30
+	}
+
+	return value << 20
+}
+
 func getCommitMessageTemplate() string {
 	return os.Getenv("COMMIT_MESSAGE_TEMPLATE")
 }
@@ -62,6 +62,23 @@ func (c *Cluster) GetNode(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, dto)
 }
 
+func (c *Cluster) ListClusters(ctx *gin.Context) {
+	ctx.Header("Access-Control-Allow-Origin", "*")
+
+	clusters, err := c.kubernetesClient.ListClusters()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching clusters", err.Error()))
+		return
+	}
+
+	names := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		names = append(names, cluster.Name)
+	}
+
+	ctx.JSON(http.StatusOK, names)
+}
+
 func (c *Cluster) ListNamespaces(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
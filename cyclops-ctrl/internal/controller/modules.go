@@ -10,8 +10,11 @@ import (
 	"time"
 
 	json "github.com/json-iterator/go"
+	"helm.sh/helm/v3/pkg/release"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/template"
 	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/template/render"
@@ -39,6 +42,10 @@ type Modules struct {
 
 	moduleTargetNamespace string
 
+	// trustedAuthHeader is the request header the authenticating proxy in front of Cyclops sets to the user name.
+	// Module authors are not recorded if it is empty.
+	trustedAuthHeader string
+
 	telemetryClient telemetry.Client
 	monitor         prometheus.Monitor
 }
@@ -49,6 +56,7 @@ func NewModulesController(
 	renderer *render.Renderer,
 	gitWriteClient *git.WriteClient,
 	moduleTargetNamespace string,
+	trustedAuthHeader string,
 	telemetryClient telemetry.Client,
 	monitor prometheus.Monitor,
 ) *Modules {
@@ -58,6 +66,7 @@ func NewModulesController(
 		renderer:              renderer,
 		gitWriteClient:        gitWriteClient,
 		moduleTargetNamespace: moduleTargetNamespace,
+		trustedAuthHeader:     trustedAuthHeader,
 		telemetryClient:       telemetryClient,
 		monitor:               monitor,
 	}
@@ -66,7 +75,7 @@ func NewModulesController(
 func (m *Modules) GetModule(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
@@ -86,7 +95,7 @@ func (m *Modules) GetModule(ctx *gin.Context) {
 func (m *Modules) GetRawModuleManifest(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.Status(http.StatusInternalServerError)
@@ -121,12 +130,20 @@ func (m *Modules) ListModules(ctx *gin.Context) {
 
 	dtoModules := mapper.ModuleListToDTO(modules)
 
+	// a module on an unreachable cluster is listed with an unknown status instead of failing the whole list
 	for i, dtoModule := range dtoModules {
-		dtoModuleStatus, err := m.kubernetesClient.GetModuleResourcesHealth(dtoModule.Name)
+		kubernetesClient, err := m.kubernetesClient.ForCluster(dtoModule.Cluster)
 		if err != nil {
 			fmt.Println(err)
-			ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching modules", err.Error()))
-			return
+			dtoModules[i].Status = string(dto.Unknown)
+			continue
+		}
+
+		dtoModuleStatus, err := kubernetesClient.GetModuleResourcesHealth(dtoModule.Namespace, dtoModule.Name)
+		if err != nil {
+			fmt.Println(err)
+			dtoModules[i].Status = string(dto.Unknown)
+			continue
 		}
 
 		dtoModules[i].Status = dtoModuleStatus
@@ -142,7 +159,7 @@ func (m *Modules) DeleteModule(ctx *gin.Context) {
 	deleteMethod := ctx.Query("deleteMethod")
 
 	if deleteMethod == "git" {
-		module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+		module, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), ctx.Param("name"))
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, dto.NewError("Error fetching module for deletion", err.Error()))
 			return
@@ -163,7 +180,7 @@ func (m *Modules) DeleteModule(ctx *gin.Context) {
 		return
 	}
 
-	err := m.kubernetesClient.DeleteModule(ctx.Param("name"))
+	err := m.kubernetesClient.DeleteModule(ctx.Query("namespace"), ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error deleting module", err.Error()))
@@ -176,14 +193,21 @@ func (m *Modules) DeleteModule(ctx *gin.Context) {
 func (m *Modules) GetModuleHistory(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.Status(http.StatusInternalServerError)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, module.History)
+	revisions, err := m.moduleRevisions(module)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module revisions", err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapper.ModuleRevisionsToDTO(revisions))
 }
 
 func (m *Modules) Manifest(ctx *gin.Context) {
@@ -209,9 +233,24 @@ func (m *Modules) Manifest(ctx *gin.Context) {
 		return
 	}
 
-	manifest, err := m.renderer.HelmTemplate(v1alpha1.Module{
+	// patches and the release revision of an existing module are applied to the preview as they would be on update
+	var patches []v1alpha1.Patch
+	curr, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), ctx.Param("name"))
+	if err != nil && !errors.IsNotFound(err) {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
+		return
+	}
+	var generation int64
+	if err == nil {
+		patches = curr.Spec.Patches
+		generation = curr.Generation + 1
+	}
+
+	manifest, err := m.renderer.WithoutLookup().HelmTemplate(v1alpha1.Module{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: ctx.Param("name"),
+			Name:       ctx.Param("name"),
+			Generation: generation,
 		},
 		Spec: v1alpha1.ModuleSpec{
 			TemplateRef: v1alpha1.TemplateRef{
@@ -220,7 +259,8 @@ func (m *Modules) Manifest(ctx *gin.Context) {
 				Version:    request.TemplateRef.Version,
 				SourceType: request.TemplateRef.SourceType,
 			},
-			Values: request.Values,
+			Values:  request.Values,
+			Patches: patches,
 		},
 	}, targetTemplate)
 	if err != nil {
@@ -235,10 +275,192 @@ func (m *Modules) Manifest(ctx *gin.Context) {
 	ctx.String(http.StatusOK, manifest)
 }
 
+// PlanModule renders the module spec from the request and dry-run applies it to show how the module
+// resources would change if the module was updated to it
+func (m *Modules) PlanModule(ctx *gin.Context) {
+	ctx.Header("Access-Control-Allow-Origin", "*")
+
+	var request dto.Module
+	if err := ctx.BindJSON(&request); err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusBadRequest, dto.NewError("Error mapping module request", err.Error()))
+		return
+	}
+
+	curr, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), ctx.Param("name"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
+		return
+	}
+
+	module, err := mapper.RequestToModule(request)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error creating module", err.Error()))
+		return
+	}
+
+	module.Name = curr.Name
+	module.Namespace = curr.Namespace
+	module.Spec.TemplateRef.SourceType = curr.Spec.TemplateRef.SourceType
+	module.Spec.TargetNamespace = curr.Spec.TargetNamespace
+	module.Spec.Cluster = curr.Spec.Cluster
+	module.Spec.ValuesFrom = curr.Spec.ValuesFrom
+	module.Spec.Patches = curr.Spec.Patches
+	module.Spec.SyncPolicy = curr.Spec.SyncPolicy
+	module.Generation = curr.Generation + 1
+
+	kubernetesClient, err := m.kubernetesClient.ForCluster(module.Spec.Cluster)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error connecting to cluster", err.Error()))
+		return
+	}
+
+	targetTemplate, err := m.templatesRepo.GetTemplate(
+		module.Spec.TemplateRef.URL,
+		module.Spec.TemplateRef.Path,
+		module.Spec.TemplateRef.Version,
+		request.Template.ResolvedVersion,
+		module.Spec.TemplateRef.SourceType,
+	)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching template", err.Error()))
+		return
+	}
+
+	manifest, err := m.renderer.HelmTemplate(module, targetTemplate)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error rendering Module manifest", err.Error()))
+		return
+	}
+
+	plan, err := m.planManifest(kubernetesClient, module, manifest)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error planning module changes", err.Error()))
+		return
+	}
+
+	resources, err := kubernetesClient.GetResourcesForModule(module.Namespace, module.Name)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module resources", err.Error()))
+		return
+	}
+
+	resources, err = kubernetesClient.GetDeletedResources(resources, manifest, module.Spec.TargetNamespace)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching deleted module resources", err.Error()))
+		return
+	}
+
+	for _, resource := range resources {
+		if !resource.GetDeleted() {
+			continue
+		}
+
+		plan = append(plan, dto.ResourcePlan{
+			Group:     resource.GetGroup(),
+			Version:   resource.GetVersion(),
+			Kind:      resource.GetKind(),
+			Name:      resource.GetName(),
+			Namespace: resource.GetNamespace(),
+			Action:    dto.PlanDelete,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, plan)
+}
+
+// planManifest dry-run applies every resource of the manifest and compares the result with its live state
+func (m *Modules) planManifest(
+	kubernetesClient k8sclient.IKubernetesClient,
+	module v1alpha1.Module,
+	manifest string,
+) ([]dto.ResourcePlan, error) {
+	plan := make([]dto.ResourcePlan, 0)
+
+	for _, s := range strings.Split(manifest, "\n---\n") {
+		s := strings.TrimSpace(s)
+		if len(s) == 0 {
+			continue
+		}
+
+		var obj unstructured.Unstructured
+		decoder := yamlutil.NewYAMLOrJSONDecoder(strings.NewReader(s), len(s))
+		if err := decoder.Decode(&obj); err != nil {
+			return nil, err
+		}
+
+		if len(obj.UnstructuredContent()) == 0 {
+			continue
+		}
+
+		// hooks are run by the module controller instead of being applied with the rest of the manifest
+		if _, ok := obj.GetAnnotations()[release.HookAnnotation]; ok {
+			continue
+		}
+
+		k8sclient.SetModuleLabels(&obj, module.Namespace, module.Name)
+
+		resourcePlan := dto.ResourcePlan{
+			Group:   obj.GroupVersionKind().Group,
+			Version: obj.GroupVersionKind().Version,
+			Kind:    obj.GroupVersionKind().Kind,
+			Name:    obj.GetName(),
+		}
+
+		resourceName, err := kubernetesClient.GVKtoAPIResourceName(obj.GroupVersionKind().GroupVersion(), obj.GroupVersionKind().Kind)
+		if err != nil {
+			resourcePlan.Namespace = obj.GetNamespace()
+			resourcePlan.Error = err.Error()
+			plan = append(plan, resourcePlan)
+			continue
+		}
+
+		live, applied, err := kubernetesClient.DryRunDynamic(
+			v1alpha1.GroupVersionResource{
+				Group:    obj.GroupVersionKind().Group,
+				Version:  obj.GroupVersionKind().Version,
+				Resource: resourceName,
+			},
+			&obj,
+			module.Spec.TargetNamespace,
+			module.Spec.SyncPolicy != nil && module.Spec.SyncPolicy.ForceConflicts,
+		)
+		if err != nil {
+			resourcePlan.Namespace = obj.GetNamespace()
+			resourcePlan.Error = err.Error()
+			plan = append(plan, resourcePlan)
+			continue
+		}
+
+		resourcePlan.Namespace = applied.GetNamespace()
+
+		if live == nil {
+			resourcePlan.Action = dto.PlanCreate
+		} else if fields := k8sclient.DiffObjects(live, applied); len(fields) != 0 {
+			resourcePlan.Action = dto.PlanUpdate
+			resourcePlan.Fields = fields
+		} else {
+			resourcePlan.Action = dto.PlanUnchanged
+		}
+
+		plan = append(plan, resourcePlan)
+	}
+
+	return plan, nil
+}
+
 func (m *Modules) CurrentManifest(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.Status(http.StatusInternalServerError)
@@ -258,7 +480,7 @@ func (m *Modules) CurrentManifest(ctx *gin.Context) {
 		return
 	}
 
-	manifest, err := m.renderer.HelmTemplate(*module, targetTemplate)
+	manifest, err := m.renderer.WithoutLookup().HelmTemplate(*module, targetTemplate)
 	if err != nil {
 		fmt.Println(err)
 		ctx.Status(http.StatusInternalServerError)
@@ -281,7 +503,14 @@ func (m *Modules) DeleteModuleResource(ctx *gin.Context) {
 		return
 	}
 
-	if err := m.kubernetesClient.Delete(request); err != nil {
+	kubernetesClient, err := m.kubernetesClient.ForCluster(ctx.Query("cluster"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error connecting to cluster", err.Error()))
+		return
+	}
+
+	if err := kubernetesClient.Delete(request); err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error deleting module", err.Error()))
 		return
@@ -307,10 +536,19 @@ func (m *Modules) CreateModule(ctx *gin.Context) {
 		return
 	}
 
+	// the namespace field of create requests is the module target namespace
+	module.Namespace = ctx.Query("namespace")
+
 	if len(m.moduleTargetNamespace) > 0 {
 		module.Spec.TargetNamespace = m.moduleTargetNamespace
 	}
 
+	if !m.validateModuleValues(ctx, module, request.Template.ResolvedVersion) {
+		return
+	}
+
+	m.setAuthor(ctx, &module)
+
 	m.telemetryClient.ModuleCreation()
 
 	if module.GetAnnotations() != nil && len(module.GetAnnotations()[v1alpha1.GitOpsWriteRepoAnnotation]) != 0 {
@@ -333,6 +571,44 @@ func (m *Modules) CreateModule(ctx *gin.Context) {
 	ctx.Status(http.StatusOK)
 }
 
+// validateModuleValues validates module values against the values schema of the module template. The error
+// response is written if the values are invalid or could not be validated.
+func (m *Modules) validateModuleValues(ctx *gin.Context, module v1alpha1.Module, resolvedVersion string) bool {
+	moduleTemplate, err := m.templatesRepo.GetTemplate(
+		module.Spec.TemplateRef.URL,
+		module.Spec.TemplateRef.Path,
+		module.Spec.TemplateRef.Version,
+		resolvedVersion,
+		module.Spec.TemplateRef.SourceType,
+	)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching template", err.Error()))
+		return false
+	}
+
+	err = m.renderer.ValidateValues(module, moduleTemplate)
+	if validationErr, ok := err.(*render.ValuesValidationError); ok {
+		fields := make([]dto.FieldError, 0, len(validationErr.Fields))
+		for _, field := range validationErr.Fields {
+			fields = append(fields, dto.FieldError{
+				Field:   field.Field,
+				Message: field.Message,
+			})
+		}
+
+		ctx.JSON(http.StatusBadRequest, dto.NewValidationError("Invalid module values", err.Error(), fields))
+		return false
+	}
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error validating module values", err.Error()))
+		return false
+	}
+
+	return true
+}
+
 func (m *Modules) UpdateModule(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
@@ -343,7 +619,7 @@ func (m *Modules) UpdateModule(ctx *gin.Context) {
 		return
 	}
 
-	curr, err := m.kubernetesClient.GetModule(request.Name)
+	curr, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), request.Name)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
@@ -357,6 +633,7 @@ func (m *Modules) UpdateModule(ctx *gin.Context) {
 		return
 	}
 
+	module.Namespace = curr.Namespace
 	module.Spec.TemplateRef.SourceType = curr.Spec.TemplateRef.SourceType
 
 	module.Status.TemplateResolvedVersion = request.Template.ResolvedVersion
@@ -365,8 +642,20 @@ func (m *Modules) UpdateModule(ctx *gin.Context) {
 	module.Status.ManagedGVRs = curr.Status.ManagedGVRs
 
 	module.Spec.TargetNamespace = curr.Spec.TargetNamespace
+	module.Spec.Cluster = curr.Spec.Cluster
+	module.Spec.ValuesFrom = curr.Spec.ValuesFrom
+	module.Spec.Patches = curr.Spec.Patches
+	module.Spec.SyncPolicy = curr.Spec.SyncPolicy
+	module.Spec.DependsOn = curr.Spec.DependsOn
+	module.Spec.DeletionPolicy = curr.Spec.DeletionPolicy
+	module.Spec.UpgradePolicy = curr.Spec.UpgradePolicy
+	module.Spec.RevisionHistory = curr.Spec.RevisionHistory
 	module.SetLabels(curr.GetLabels())
 
+	if !m.validateModuleValues(ctx, module, request.Template.ResolvedVersion) {
+		return
+	}
+
 	annotations := curr.GetAnnotations()
 	moduleAnnotations := module.GetAnnotations()
 
@@ -398,6 +687,7 @@ func (m *Modules) UpdateModule(ctx *gin.Context) {
 
 	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
 	module.SetAnnotations(annotations)
+	m.setAuthor(ctx, &module)
 
 	if len(module.GetAnnotations()[v1alpha1.GitOpsWriteRepoAnnotation]) != 0 {
 		err := m.gitWriteClient.Write(module)
@@ -408,26 +698,8 @@ func (m *Modules) UpdateModule(ctx *gin.Context) {
 		return
 	}
 
-	history := curr.History
-	if curr.History == nil {
-		history = make([]v1alpha1.HistoryEntry, 0)
-	}
-
-	module.History = append([]v1alpha1.HistoryEntry{{
-		Generation:      curr.Generation,
-		TargetNamespace: curr.Spec.TargetNamespace,
-		TemplateRef: v1alpha1.HistoryTemplateRef{
-			URL:        curr.Spec.TemplateRef.URL,
-			Path:       curr.Spec.TemplateRef.Path,
-			Version:    curr.Status.TemplateResolvedVersion,
-			SourceType: curr.Spec.TemplateRef.SourceType,
-		},
-		Values: curr.Spec.Values,
-	}}, history...)
-
-	if len(module.History) > 10 {
-		module.History = module.History[:len(module.History)-1]
-	}
+	// revisions of previous generations are recorded as ModuleRevisions by the module controller
+	module.History = curr.History
 
 	module.SetResourceVersion(curr.GetResourceVersion())
 
@@ -459,19 +731,18 @@ func (m *Modules) HistoryEntryManifest(ctx *gin.Context) {
 		return
 	}
 
-	curr, err := m.kubernetesClient.GetModule(request.ModuleName)
+	curr, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), request.ModuleName)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
 		return
 	}
 
-	var targetGeneration *v1alpha1.HistoryEntry
-	for _, entry := range curr.History {
-		if entry.Generation == request.Generation {
-			targetGeneration = &entry
-			break
-		}
+	targetGeneration, err := m.moduleRevision(curr, request.Generation, request.ManifestHash)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module revisions", err.Error()))
+		return
 	}
 
 	if targetGeneration == nil {
@@ -480,11 +751,11 @@ func (m *Modules) HistoryEntryManifest(ctx *gin.Context) {
 	}
 
 	targetTemplate, err := m.templatesRepo.GetTemplate(
-		targetGeneration.TemplateRef.URL,
-		targetGeneration.TemplateRef.Path,
-		targetGeneration.TemplateRef.Version,
+		targetGeneration.Spec.TemplateRef.URL,
+		targetGeneration.Spec.TemplateRef.Path,
+		targetGeneration.Spec.TemplateRef.Version,
 		"",
-		targetGeneration.TemplateRef.SourceType,
+		targetGeneration.Spec.TemplateRef.SourceType,
 	)
 	if err != nil {
 		fmt.Println(err)
@@ -492,19 +763,21 @@ func (m *Modules) HistoryEntryManifest(ctx *gin.Context) {
 		return
 	}
 
-	manifest, err := m.renderer.HelmTemplate(v1alpha1.Module{
+	manifest, err := m.renderer.WithoutLookup().HelmTemplate(v1alpha1.Module{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: request.ModuleName,
+			Name:       request.ModuleName,
+			Generation: curr.Generation + 1,
 		},
 		Spec: v1alpha1.ModuleSpec{
-			TargetNamespace: targetGeneration.TargetNamespace,
+			TargetNamespace: targetGeneration.Spec.TargetNamespace,
 			TemplateRef: v1alpha1.TemplateRef{
-				URL:        targetGeneration.TemplateRef.URL,
-				Path:       targetGeneration.TemplateRef.Path,
-				Version:    targetGeneration.TemplateRef.Version,
-				SourceType: targetGeneration.TemplateRef.SourceType,
+				URL:        targetGeneration.Spec.TemplateRef.URL,
+				Path:       targetGeneration.Spec.TemplateRef.Path,
+				Version:    targetGeneration.Spec.TemplateRef.Version,
+				SourceType: targetGeneration.Spec.TemplateRef.SourceType,
 			},
-			Values: targetGeneration.Values,
+			Values:  targetGeneration.Spec.Values,
+			Patches: curr.Spec.Patches,
 		},
 	}, targetTemplate)
 	if err != nil {
@@ -529,19 +802,18 @@ func (m *Modules) RollbackModule(ctx *gin.Context) {
 		return
 	}
 
-	curr, err := m.kubernetesClient.GetModule(request.ModuleName)
+	curr, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), request.ModuleName)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
 		return
 	}
 
-	var targetGeneration *v1alpha1.HistoryEntry
-	for _, entry := range curr.History {
-		if entry.Generation == request.Generation {
-			targetGeneration = &entry
-			break
-		}
+	targetGeneration, err := m.moduleRevision(curr, request.Generation, request.ManifestHash)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module revisions", err.Error()))
+		return
 	}
 
 	if targetGeneration == nil {
@@ -554,35 +826,16 @@ func (m *Modules) RollbackModule(ctx *gin.Context) {
 	module.Kind = "Module"
 	module.APIVersion = "cyclops-ui.com/v1alpha1"
 
-	history := module.History
-	if module.History == nil {
-		history = make([]v1alpha1.HistoryEntry, 0)
-	}
-
-	module.History = append([]v1alpha1.HistoryEntry{{
-		Generation:      curr.Generation,
-		TargetNamespace: curr.Spec.TargetNamespace,
-		TemplateRef: v1alpha1.HistoryTemplateRef{
-			URL:        curr.Spec.TemplateRef.URL,
-			Path:       curr.Spec.TemplateRef.Path,
-			Version:    curr.Status.TemplateResolvedVersion,
-			SourceType: curr.Spec.TemplateRef.SourceType,
-		},
-		Values: curr.Spec.Values,
-	}}, history...)
-
-	if len(module.History) > 10 {
-		module.History = module.History[:len(module.History)-1]
-	}
+	m.setAuthor(ctx, module)
 
-	module.Spec.Values = targetGeneration.Values
+	module.Spec.Values = targetGeneration.Spec.Values
 	module.Spec.TemplateRef = v1alpha1.TemplateRef{
-		URL:        targetGeneration.TemplateRef.URL,
-		Path:       targetGeneration.TemplateRef.Path,
-		Version:    targetGeneration.TemplateRef.Version,
-		SourceType: targetGeneration.TemplateRef.SourceType,
+		URL:        targetGeneration.Spec.TemplateRef.URL,
+		Path:       targetGeneration.Spec.TemplateRef.Path,
+		Version:    targetGeneration.Spec.TemplateRef.Version,
+		SourceType: targetGeneration.Spec.TemplateRef.SourceType,
 	}
-	module.Spec.TargetNamespace = targetGeneration.TargetNamespace
+	module.Spec.TargetNamespace = targetGeneration.Spec.TargetNamespace
 
 	module.SetResourceVersion(curr.GetResourceVersion())
 
@@ -609,7 +862,7 @@ func (m *Modules) ReconcileModule(ctx *gin.Context) {
 
 	moduleName := ctx.Param("name")
 
-	module, err := m.kubernetesClient.GetModule(moduleName)
+	module, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), moduleName)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
@@ -621,7 +874,7 @@ func (m *Modules) ReconcileModule(ctx *gin.Context) {
 		annotations = make(map[string]string)
 	}
 
-	annotations["cyclops/reconciled-at"] = time.Now().Format(time.RFC3339)
+	annotations[v1alpha1.ReconciledAtAnnotation] = time.Now().Format(time.RFC3339)
 	module.SetAnnotations(annotations)
 
 	module.Kind = "Module"
@@ -640,7 +893,7 @@ func (m *Modules) ReconcileModule(ctx *gin.Context) {
 func (m *Modules) ResourcesForModule(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), ctx.Param("name"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, dto.NewError("Error mapping module request", err.Error()))
 		return
@@ -663,7 +916,14 @@ func (m *Modules) ResourcesForModule(ctx *gin.Context) {
 		return
 	}
 
-	resources, err := m.kubernetesClient.GetResourcesForModule(ctx.Param("name"))
+	kubernetesClient, err := m.kubernetesClient.ForCluster(module.Spec.Cluster)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error connecting to cluster", err.Error()))
+		return
+	}
+
+	resources, err := kubernetesClient.GetResourcesForModule(module.Namespace, module.Name)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module resources", err.Error()))
@@ -677,7 +937,7 @@ func (m *Modules) ResourcesForModule(ctx *gin.Context) {
 		return
 	}
 
-	resources, err = m.kubernetesClient.GetDeletedResources(resources, manifest, module.Spec.TargetNamespace)
+	resources, err = kubernetesClient.GetDeletedResources(resources, manifest, module.Spec.TargetNamespace)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching deleted module resources", err.Error()))
@@ -695,10 +955,58 @@ func (m *Modules) ResourcesForModule(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, resources)
 }
 
+func (m *Modules) GetModuleEvents(ctx *gin.Context) {
+	ctx.Header("Access-Control-Allow-Origin", "*")
+
+	events, err := m.kubernetesClient.GetModuleEvents(ctx.Query("namespace"), ctx.Param("name"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module events", err.Error()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapper.EventsToDTO(events))
+}
+
+func (m *Modules) TemplateUpdates(ctx *gin.Context) {
+	ctx.Header("Access-Control-Allow-Origin", "*")
+
+	module, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), ctx.Param("name"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
+		return
+	}
+
+	availableVersion, err := m.templatesRepo.ResolveVersion(
+		module.Spec.TemplateRef.URL,
+		module.Spec.TemplateRef.Path,
+		module.Spec.TemplateRef.Version,
+		module.Spec.TemplateRef.SourceType,
+	)
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error resolving template version", err.Error()))
+		return
+	}
+
+	update := dto.TemplateUpdate{
+		CurrentVersion: module.Status.TemplateResolvedVersion,
+	}
+
+	if template.IsUpgrade(module.Status.TemplateResolvedVersion, availableVersion) {
+		update.AvailableVersion = availableVersion
+		update.UpgradeAllowed = module.Spec.UpgradePolicy != nil &&
+			template.IsUpgradeAllowed(module.Status.TemplateResolvedVersion, availableVersion, module.Spec.UpgradePolicy.Mode)
+	}
+
+	ctx.JSON(http.StatusOK, update)
+}
+
 func (m *Modules) Template(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
@@ -718,7 +1026,7 @@ func (m *Modules) Template(ctx *gin.Context) {
 		return
 	}
 
-	currentManifest, err := m.renderer.HelmTemplate(*module, currentTemplate)
+	currentManifest, err := m.renderer.WithoutLookup().HelmTemplate(*module, currentTemplate)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error templating current", err.Error()))
@@ -738,7 +1046,7 @@ func (m *Modules) Template(ctx *gin.Context) {
 		return
 	}
 
-	proposedManifest, err := m.renderer.HelmTemplate(*module, proposedTemplate)
+	proposedManifest, err := m.renderer.WithoutLookup().HelmTemplate(*module, proposedTemplate)
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error templating proposed", err.Error()))
@@ -756,7 +1064,7 @@ func (m *Modules) Template(ctx *gin.Context) {
 func (m *Modules) HelmTemplate(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
-	module, err := m.kubernetesClient.GetModule(ctx.Param("name"))
+	module, err := m.kubernetesClient.GetModule(ctx.Query("namespace"), ctx.Param("name"))
 	if err != nil {
 		fmt.Println(err)
 		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error fetching module", err.Error()))
@@ -809,8 +1117,15 @@ func (m *Modules) HelmTemplate(ctx *gin.Context) {
 func (m *Modules) GetLogs(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
+	kubernetesClient, err := m.kubernetesClient.ForCluster(ctx.Query("cluster"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error connecting to cluster", err.Error()))
+		return
+	}
+
 	logCount := int64(100)
-	rawLogs, err := m.kubernetesClient.GetPodLogs(
+	rawLogs, err := kubernetesClient.GetPodLogs(
 		ctx.Param("namespace"),
 		ctx.Param("container"),
 		ctx.Param("name"),
@@ -833,6 +1148,13 @@ func (m *Modules) GetLogs(ctx *gin.Context) {
 func (m *Modules) GetLogsStream(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
+	kubernetesClient, err := m.kubernetesClient.ForCluster(ctx.Query("cluster"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error connecting to cluster", err.Error()))
+		return
+	}
+
 	logCount := int64(100)
 
 	logChan := make(chan string)
@@ -840,7 +1162,7 @@ func (m *Modules) GetLogsStream(ctx *gin.Context) {
 	go func() {
 		defer close(logChan)
 
-		err := m.kubernetesClient.GetStreamedPodLogs(
+		err := kubernetesClient.GetStreamedPodLogs(
 			ctx.Request.Context(), // we will have to pass the context for the k8s podClient - so it can stop the stream when the client disconnects
 			ctx.Param("namespace"),
 			ctx.Param("container"),
@@ -876,8 +1198,15 @@ func (m *Modules) GetLogsStream(ctx *gin.Context) {
 func (m *Modules) GetDeploymentLogs(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
+	kubernetesClient, err := m.kubernetesClient.ForCluster(ctx.Query("cluster"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error connecting to cluster", err.Error()))
+		return
+	}
+
 	logCount := int64(100)
-	logs, err := m.kubernetesClient.GetDeploymentLogs(
+	logs, err := kubernetesClient.GetDeploymentLogs(
 		ctx.Param("namespace"),
 		ctx.Param("container"),
 		ctx.Param("deployment"),
@@ -894,8 +1223,15 @@ func (m *Modules) GetDeploymentLogs(ctx *gin.Context) {
 func (m *Modules) GetStatefulSetsLogs(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", "*")
 
+	kubernetesClient, err := m.kubernetesClient.ForCluster(ctx.Query("cluster"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error connecting to cluster", err.Error()))
+		return
+	}
+
 	logCount := int64(100)
-	logs, err := m.kubernetesClient.GetStatefulSetsLogs(
+	logs, err := kubernetesClient.GetStatefulSetsLogs(
 		ctx.Param("namespace"),
 		ctx.Param("container"),
 		ctx.Param("name"),
@@ -917,7 +1253,14 @@ func (m *Modules) DownloadLogs(ctx *gin.Context) {
 	container := ctx.Param("container")
 	name := ctx.Param("name")
 
-	logs, err := m.kubernetesClient.GetPodLogs(
+	kubernetesClient, err := m.kubernetesClient.ForCluster(ctx.Query("cluster"))
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, dto.NewError("Error connecting to cluster", err.Error()))
+		return
+	}
+
+	logs, err := kubernetesClient.GetPodLogs(
 		namespace,
 		container,
 		name,
@@ -961,7 +1304,16 @@ func (m *Modules) GetManifest(ctx *gin.Context) {
 	namespace := ctx.Query("namespace")
 	includeManagedFields := ctx.Query("includeManagedFields") == "true"
 
-	manifest, err := m.kubernetesClient.GetManifest(group, version, kind, name, namespace, includeManagedFields)
+	kubernetesClient, err := m.kubernetesClient.ForCluster(ctx.Query("cluster"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "Failed to connect to cluster",
+			"reason": err.Error(),
+		})
+		return
+	}
+
+	manifest, err := kubernetesClient.GetManifest(group, version, kind, name, namespace, includeManagedFields)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":  "Failed to fetch resource manifest",
@@ -982,7 +1334,16 @@ func (m *Modules) Restart(ctx *gin.Context) {
 	name := ctx.Query("name")
 	namespace := ctx.Query("namespace")
 
-	err := m.kubernetesClient.Restart(group, version, kind, name, namespace)
+	kubernetesClient, err := m.kubernetesClient.ForCluster(ctx.Query("cluster"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "Failed to connect to cluster",
+			"reason": err.Error(),
+		})
+		return
+	}
+
+	err = kubernetesClient.Restart(group, version, kind, name, namespace)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":  "Failed to restart resource",
@@ -1003,7 +1364,16 @@ func (m *Modules) GetResource(ctx *gin.Context) {
 	name := ctx.Query("name")
 	namespace := ctx.Query("namespace")
 
-	resource, err := m.kubernetesClient.GetResource(group, version, kind, name, namespace)
+	kubernetesClient, err := m.kubernetesClient.ForCluster(ctx.Query("cluster"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":  "Failed to connect to cluster",
+			"reason": err.Error(),
+		})
+		return
+	}
+
+	resource, err := kubernetesClient.GetResource(group, version, kind, name, namespace)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":  "Failed to fetch resource",
@@ -1078,7 +1448,7 @@ func (m *Modules) MCPServerStatus(ctx *gin.Context) {
 		Installed bool `json:"installed"`
 	}
 
-	module, err := m.kubernetesClient.GetModule("mcp-cyclops")
+	module, err := m.kubernetesClient.GetModule("", "mcp-cyclops")
 	if err != nil {
 		if errors.IsNotFound(err) {
 			ctx.JSON(http.StatusOK, MCPServerStatus{Installed: false})
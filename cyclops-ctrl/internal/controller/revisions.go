@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// moduleRevisions returns previous revisions of the module, newest first; revisions of previous generations, and
+// revisions of the current generation applied before its template version resolved to a new commit. History entries
+// of modules updated before revisions were stored as ModuleRevisions are returned as revisions too.
+func (m *Modules) moduleRevisions(module *v1alpha1.Module) ([]v1alpha1.ModuleRevision, error) {
+	revisions, err := m.kubernetesClient.ListModuleRevisions(module.Namespace, module.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]v1alpha1.ModuleRevision, 0, len(revisions)+len(module.History))
+	generations := make(map[int64]struct{}, len(revisions))
+	for _, revision := range revisions {
+		if isCurrentRevision(module, revision) {
+			continue
+		}
+
+		generations[revision.Spec.Generation] = struct{}{}
+		out = append(out, revision)
+	}
+
+	for _, entry := range module.History {
+		if _, ok := generations[entry.Generation]; ok {
+			continue
+		}
+
+		out = append(out, v1alpha1.ModuleRevision{
+			Spec: v1alpha1.ModuleRevisionSpec{
+				Module:          module.Name,
+				Generation:      entry.Generation,
+				TargetNamespace: entry.TargetNamespace,
+				TemplateRef:     entry.TemplateRef,
+				Values:          entry.Values,
+			},
+		})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Spec.Generation != out[j].Spec.Generation {
+			return out[i].Spec.Generation > out[j].Spec.Generation
+		}
+
+		return out[j].CreationTimestamp.Before(&out[i].CreationTimestamp)
+	})
+
+	return out, nil
+}
+
+// isCurrentRevision returns true if the revision is of the manifest currently applied for the module. All revisions
+// of the current generation are treated as current if the module has no applied manifest.
+func isCurrentRevision(module *v1alpha1.Module, revision v1alpha1.ModuleRevision) bool {
+	if revision.Spec.Generation != module.Generation {
+		return false
+	}
+
+	return len(module.Status.AppliedManifestHash) == 0 || revision.Spec.ManifestHash == module.Status.AppliedManifestHash
+}
+
+// moduleRevision returns the previous revision of the module with the given generation and manifest hash, or nil
+// if there is none. The newest revision of the generation is returned if the manifest hash is empty.
+func (m *Modules) moduleRevision(module *v1alpha1.Module, generation int64, manifestHash string) (*v1alpha1.ModuleRevision, error) {
+	revisions, err := m.moduleRevisions(module)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, revision := range revisions {
+		if revision.Spec.Generation != generation {
+			continue
+		}
+
+		if len(manifestHash) == 0 || revision.Spec.ManifestHash == manifestHash {
+			return &revision, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// setAuthor sets the author annotation of the module to the user authenticated by the proxy in front of Cyclops.
+// The user is read only from the configured trusted header, since any client can set headers on requests that
+// don't pass the proxy. The annotation is removed if the request has no authenticated user, so the author of the
+// previous generation is not recorded as the author of the new one.
+func (m *Modules) setAuthor(ctx *gin.Context, module *v1alpha1.Module) {
+	author := ""
+	if len(m.trustedAuthHeader) != 0 {
+		author = ctx.GetHeader(m.trustedAuthHeader)
+	}
+
+	annotations := module.GetAnnotations()
+	if len(author) == 0 {
+		delete(annotations, v1alpha1.AuthorAnnotation)
+		return
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	annotations[v1alpha1.AuthorAnnotation] = author
+	module.SetAnnotations(annotations)
+}
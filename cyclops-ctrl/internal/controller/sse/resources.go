@@ -16,13 +16,25 @@ import (
 )
 
 func (s *Server) Resources(ctx *gin.Context) {
-	resources, err := s.k8sClient.GetWorkloadsForModule(ctx.Param("name"))
+	module, err := s.k8sClient.GetModule(ctx.Query("namespace"), ctx.Param("name"))
 	if err != nil {
 		ctx.String(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	s.streamResources(ctx, resources)
+	k8sClient, err := s.k8sClient.ForCluster(module.Spec.Cluster)
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resources, err := k8sClient.GetWorkloadsForModule(module.Namespace, module.Name)
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.streamResources(ctx, k8sClient, resources)
 }
 
 func (s *Server) ReleaseResources(ctx *gin.Context) {
@@ -32,10 +44,10 @@ func (s *Server) ReleaseResources(ctx *gin.Context) {
 		return
 	}
 
-	s.streamResources(ctx, resources)
+	s.streamResources(ctx, s.k8sClient, resources)
 }
 
-func (s *Server) streamResources(ctx *gin.Context, resources []*dto.Resource) {
+func (s *Server) streamResources(ctx *gin.Context, k8sClient k8sclient.IKubernetesClient, resources []*dto.Resource) {
 	watchSpecs := make([]k8sclient.ResourceWatchSpec, 0, len(resources))
 	for _, resource := range resources {
 		if !k8sclient.IsWorkload(resource.GetGroup(), resource.GetVersion(), resource.GetKind()) {
@@ -61,7 +73,7 @@ func (s *Server) streamResources(ctx *gin.Context, resources []*dto.Resource) {
 
 	stopCh := make(chan struct{})
 
-	watchResource, err := s.k8sClient.WatchKubernetesResources(watchSpecs, stopCh)
+	watchResource, err := k8sClient.WatchKubernetesResources(watchSpecs, stopCh)
 	if err != nil {
 		ctx.String(http.StatusInternalServerError, err.Error())
 		return
@@ -75,7 +87,7 @@ func (s *Server) streamResources(ctx *gin.Context, resources []*dto.Resource) {
 					return false
 				}
 
-				res, err := s.k8sClient.GetResource(
+				res, err := k8sClient.GetResource(
 					u.GroupVersionKind().Group,
 					u.GroupVersionKind().Version,
 					u.GroupVersionKind().Kind,
@@ -114,7 +126,13 @@ func (s *Server) SingleResource(ctx *gin.Context) {
 		return
 	}
 
-	resourceName, err := s.k8sClient.GVKtoAPIResourceName(
+	k8sClient, err := s.k8sClient.ForCluster(ctx.Query("cluster"))
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resourceName, err := k8sClient.GVKtoAPIResourceName(
 		schema.GroupVersion{
 			Group:   r.Group,
 			Version: r.Version,
@@ -122,7 +140,7 @@ func (s *Server) SingleResource(ctx *gin.Context) {
 		r.Kind,
 	)
 
-	watchResource, err := s.k8sClient.WatchResource(r.Group, r.Version, resourceName, r.Name, r.Namespace)
+	watchResource, err := k8sClient.WatchResource(r.Group, r.Version, resourceName, r.Name, r.Namespace)
 	if err != nil {
 		ctx.String(http.StatusInternalServerError, err.Error())
 		return
@@ -138,7 +156,7 @@ func (s *Server) SingleResource(ctx *gin.Context) {
 					return false
 				}
 
-				res, err := s.k8sClient.GetResource(
+				res, err := k8sClient.GetResource(
 					r.Group,
 					r.Version,
 					r.Kind,
@@ -11,6 +11,7 @@ import (
 
 	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/mapper"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/dto"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/telemetry"
 	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
@@ -213,20 +214,27 @@ func (c *Templates) GetTemplateRevisions(ctx *gin.Context) {
 
 	repo := ctx.Query("repo")
 	path := ctx.Query("path")
+	sourceType := ctx.Query("sourceType")
+	prerelease := ctx.Query("prerelease") == "true"
 
 	if repo == "" {
-		ctx.JSON(http.StatusOK, []string{})
+		ctx.JSON(http.StatusOK, []models.TemplateRevision{})
 		return
 	}
 
-	revisions, err := c.templatesRepo.GetTemplateRevisions(repo, path)
+	revisions, err := c.templatesRepo.GetTemplateRevisions(
+		repo,
+		path,
+		cyclopsv1alpha1.TemplateSourceType(sourceType),
+		prerelease,
+	)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, dto.NewError("Error loading template", err.Error()))
 		return
 	}
 
 	if revisions == nil {
-		ctx.JSON(http.StatusOK, []string{})
+		ctx.JSON(http.StatusOK, []models.TemplateRevision{})
 		return
 	}
 
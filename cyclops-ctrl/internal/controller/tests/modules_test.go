@@ -0,0 +1,506 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	json "github.com/json-iterator/go"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/mock"
+	helmchart "helm.sh/helm/v3/pkg/chart"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/controller"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/dto"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/helm"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/prometheus"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/telemetry"
+	"github.com/andersan81/cyclops/cyclops-ctrl/mocks"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+	k8smocks "github.com/andersan81/cyclops/cyclops-ctrl/pkg/mocks"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/template/render"
+)
+
+var _ = Describe("Modules controller test", func() {
+	var modulesController *controller.Modules
+	var w *httptest.ResponseRecorder
+	var ctx *gin.Context
+	var templatesRepo *mocks.ITemplateRepo
+	var k8sClient *k8smocks.IKubernetesClient
+	var r *gin.Engine
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		k8sClient = &k8smocks.IKubernetesClient{}
+		templatesRepo = &mocks.ITemplateRepo{}
+		modulesController = controller.NewModulesController(
+			templatesRepo,
+			k8sClient,
+			render.NewRenderer(k8sClient, nil),
+			nil,
+			"",
+			"X-Forwarded-User",
+			telemetry.MockClient{},
+			prometheus.Monitor{},
+		)
+		w = httptest.NewRecorder()
+		ctx, r = gin.CreateTestContext(w)
+	})
+
+	Describe("ListModules method", func() {
+		BeforeEach(func() {
+			r.GET("/modules/list", modulesController.ListModules)
+
+			k8sClient.On("ListModules").Return([]v1alpha1.Module{
+				{ObjectMeta: v1.ObjectMeta{Name: "api", Namespace: "team-a"}},
+				{ObjectMeta: v1.ObjectMeta{Name: "worker", Namespace: "team-a"}, Spec: v1alpha1.ModuleSpec{Cluster: "staging"}},
+			}, nil)
+			k8sClient.On("ForCluster", "").Return(k8sClient, nil)
+			k8sClient.On("ForCluster", "staging").Return(func(string) (k8sclient.IKubernetesClient, error) {
+				return nil, errors.New("failed to get cluster staging")
+			}, nil)
+			k8sClient.On("GetModuleResourcesHealth", "team-a", "api").Return("healthy", nil)
+		})
+
+		It("lists modules on unreachable clusters with an unknown status", func() {
+			req, _ := http.NewRequest(http.MethodGet, "/modules/list", nil)
+			ctx.Request = req
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+
+			var actual []dto.Module
+			Expect(json.Unmarshal(w.Body.Bytes(), &actual)).To(BeNil())
+			Expect(actual).To(HaveLen(2))
+			Expect(actual[0].Status).To(BeEquivalentTo("healthy"))
+			Expect(actual[1].Status).To(BeEquivalentTo("unknown"))
+		})
+	})
+
+	Describe("PlanModule method", func() {
+		BeforeEach(func() {
+			r.POST("/modules/:name/plan", modulesController.PlanModule)
+
+			k8sClient.On("GetModule", "", "demo").Return(&v1alpha1.Module{
+				ObjectMeta: v1.ObjectMeta{Name: "demo"},
+				Spec: v1alpha1.ModuleSpec{
+					TargetNamespace: "apps",
+					TemplateRef: v1alpha1.TemplateRef{
+						SourceType: v1alpha1.TemplateSourceTypeGit,
+					},
+					SyncPolicy: &v1alpha1.SyncPolicy{ForceConflicts: true},
+				},
+			}, nil)
+
+			templatesRepo.On("GetTemplate", "https://github.com/cyclops-ui/templates", "demo", "main", "", v1alpha1.TemplateSourceTypeGit).Return(&models.Template{
+				HelmChartMetadata: &helm.Metadata{Name: "demo"},
+				Templates: []*helmchart.File{
+					{
+						Name: "templates/configmaps.yaml",
+						Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: created
+data:
+  replicas: "{{ .Values.replicas }}"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: updated
+data:
+  replicas: "{{ .Values.replicas }}"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: migration
+  annotations:
+    helm.sh/hook: pre-upgrade
+data:
+  replicas: "{{ .Values.replicas }}"
+`),
+					},
+				},
+			}, nil)
+
+			k8sClient.On("ForCluster", "").Return(k8sClient, nil)
+			k8sClient.On("VersionInfo").Return(&version.Info{Major: "1", Minor: "30"}, nil)
+			k8sClient.On("APIVersions").Return([]string{"v1", "v1/ConfigMap"}, nil)
+			k8sClient.On("GVKtoAPIResourceName", schema.GroupVersion{Version: "v1"}, "ConfigMap").Return("configmaps", nil)
+
+			k8sClient.On("DryRunDynamic", mock.Anything, mock.MatchedBy(func(obj *unstructured.Unstructured) bool {
+				return obj.GetName() == "created"
+			}), "apps", true).Return(nil, configMap("created", "3"), nil)
+			k8sClient.On("DryRunDynamic", mock.Anything, mock.MatchedBy(func(obj *unstructured.Unstructured) bool {
+				return obj.GetName() == "updated"
+			}), "apps", true).Return(configMap("updated", "1"), configMap("updated", "3"), nil)
+
+			resources := []*dto.Resource{{Version: "v1", Kind: "ConfigMap", Name: "removed", Namespace: "apps", Deleted: true}}
+			k8sClient.On("GetResourcesForModule", "", "demo").Return(resources, nil)
+			k8sClient.On("GetDeletedResources", resources, mock.Anything, "apps").Return(resources, nil)
+		})
+
+		It("returns changes to module resources", func() {
+			body, _ := json.Marshal(dto.Module{
+				Name: "demo",
+				Template: dto.Template{
+					URL:     "https://github.com/cyclops-ui/templates",
+					Path:    "demo",
+					Version: "main",
+				},
+				Values: map[string]interface{}{"replicas": 3},
+			})
+
+			req, _ := http.NewRequest(http.MethodPost, "/modules/demo/plan", bytes.NewBuffer(body))
+			ctx.Request = req
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+
+			b, err := io.ReadAll(w.Result().Body)
+			Expect(err).To(BeNil())
+
+			var actual []dto.ResourcePlan
+			Expect(json.Unmarshal(b, &actual)).To(BeNil())
+			Expect(actual).To(BeEquivalentTo([]dto.ResourcePlan{
+				{Version: "v1", Kind: "ConfigMap", Name: "created", Namespace: "apps", Action: dto.PlanCreate},
+				{Version: "v1", Kind: "ConfigMap", Name: "updated", Namespace: "apps", Action: dto.PlanUpdate, Fields: []string{".data.replicas"}},
+				{Version: "v1", Kind: "ConfigMap", Name: "removed", Namespace: "apps", Action: dto.PlanDelete},
+			}))
+		})
+	})
+
+	Describe("CurrentManifest method", func() {
+		BeforeEach(func() {
+			r.GET("/modules/:name/currentManifest", modulesController.CurrentManifest)
+
+			k8sClient.On("GetModule", "", "demo").Return(&v1alpha1.Module{
+				ObjectMeta: v1.ObjectMeta{Name: "demo", Generation: 3},
+				Spec: v1alpha1.ModuleSpec{
+					TemplateRef: v1alpha1.TemplateRef{
+						URL:        "https://github.com/cyclops-ui/templates",
+						Path:       "demo",
+						Version:    "main",
+						SourceType: v1alpha1.TemplateSourceTypeGit,
+					},
+					Values: apiextensionsv1.JSON{Raw: []byte(`{"replicas": 3}`)},
+					Patches: []v1alpha1.Patch{
+						{
+							Patch: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: demo
+  labels:
+    team: a
+`,
+						},
+						{
+							Patch: `- op: replace
+  path: /data/replicas
+  value: "5"
+`,
+							Target: &v1alpha1.PatchTarget{Kind: "ConfigMap", Name: "demo"},
+						},
+					},
+				},
+			}, nil)
+
+			templatesRepo.On("GetTemplate", "https://github.com/cyclops-ui/templates", "demo", "main", "", v1alpha1.TemplateSourceTypeGit).Return(&models.Template{
+				HelmChartMetadata: &helm.Metadata{Name: "demo"},
+				Templates: []*helmchart.File{
+					{
+						Name: "templates/configmap.yaml",
+						Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: demo
+data:
+  replicas: "{{ .Values.replicas }}"
+  revision: "{{ .Release.Revision }}"
+  upgrade: "{{ .Release.IsUpgrade }}"
+  configMaps: "{{ .Capabilities.APIVersions.Has "v1/ConfigMap" }}"
+`),
+					},
+				},
+			}, nil)
+
+			k8sClient.On("ForCluster", "").Return(k8sClient, nil)
+			k8sClient.On("VersionInfo").Return(&version.Info{Major: "1", Minor: "30"}, nil)
+			k8sClient.On("APIVersions").Return([]string{"v1", "v1/ConfigMap"}, nil)
+		})
+
+		It("returns the manifest with module patches and release metadata", func() {
+			req, _ := http.NewRequest(http.MethodGet, "/modules/demo/currentManifest", nil)
+			ctx.Request = req
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+			Expect(w.Body.String()).To(MatchYAML(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: demo
+  labels:
+    team: a
+data:
+  replicas: "5"
+  revision: "3"
+  upgrade: "true"
+  configMaps: "true"
+`))
+		})
+	})
+
+	Describe("CreateModule method", func() {
+		BeforeEach(func() {
+			r.POST("/modules/new", modulesController.CreateModule)
+
+			templatesRepo.On("GetTemplate", "https://github.com/cyclops-ui/templates", "demo", "main", "", v1alpha1.TemplateSourceTypeGit).Return(&models.Template{
+				HelmChartMetadata: &helm.Metadata{Name: "demo"},
+				RawSchema:         []byte(`{"type": "object", "properties": {"replicas": {"type": "integer"}}}`),
+				Dependencies: []*models.Template{
+					{
+						HelmChartMetadata: &helm.Metadata{Name: "redis"},
+						RawSchema:         []byte(`{"type": "object", "required": ["port"]}`),
+					},
+				},
+			}, nil)
+		})
+
+		It("returns invalid values of the module and its dependencies", func() {
+			body, _ := json.Marshal(dto.Module{
+				Name: "demo",
+				Template: dto.Template{
+					URL:        "https://github.com/cyclops-ui/templates",
+					Path:       "demo",
+					Version:    "main",
+					SourceType: string(v1alpha1.TemplateSourceTypeGit),
+				},
+				Values: map[string]interface{}{"replicas": "three", "redis": map[string]interface{}{}},
+			})
+
+			req, _ := http.NewRequest(http.MethodPost, "/modules/new", bytes.NewBuffer(body))
+			ctx.Request = req
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusBadRequest))
+
+			var actual dto.ValidationError
+			Expect(json.Unmarshal(w.Body.Bytes(), &actual)).To(BeNil())
+			Expect(actual.Fields).To(BeEquivalentTo([]dto.FieldError{
+				{Field: "replicas", Message: "Invalid type. Expected: integer, given: string"},
+				{Field: "redis", Message: "port is required"},
+			}))
+			k8sClient.AssertNotCalled(GinkgoT(), "CreateModule", mock.Anything)
+		})
+
+		It("records the author only from the trusted header", func() {
+			modulesController = controller.NewModulesController(
+				templatesRepo,
+				k8sClient,
+				render.NewRenderer(k8sClient, nil),
+				nil,
+				"",
+				"X-Forwarded-User",
+				telemetry.MockClient{},
+				prometheus.Monitor{ModulesDeployed: promclient.NewGauge(promclient.GaugeOpts{Name: "modules_deployed"})},
+			)
+			r.POST("/modules/author", modulesController.CreateModule)
+
+			k8sClient.On("CreateModule", mock.Anything).Return(nil)
+
+			body, _ := json.Marshal(dto.Module{
+				Name: "demo",
+				Template: dto.Template{
+					URL:        "https://github.com/cyclops-ui/templates",
+					Path:       "demo",
+					Version:    "main",
+					SourceType: string(v1alpha1.TemplateSourceTypeGit),
+				},
+				Values: map[string]interface{}{"replicas": 3, "redis": map[string]interface{}{"port": 6379}},
+			})
+
+			req, _ := http.NewRequest(http.MethodPost, "/modules/author", bytes.NewBuffer(body))
+			req.Header.Set("X-Forwarded-User", "jane")
+			req.Header.Set("X-Auth-Request-User", "mallory")
+			ctx.Request = req
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+
+			created := k8sClient.Calls[len(k8sClient.Calls)-1].Arguments.Get(0).(v1alpha1.Module)
+			Expect(created.GetAnnotations()[v1alpha1.AuthorAnnotation]).To(BeEquivalentTo("jane"))
+		})
+	})
+
+	Describe("UpdateModule method", func() {
+		revisionLimit := int32(3)
+
+		BeforeEach(func() {
+			r.POST("/modules/update", modulesController.UpdateModule)
+
+			k8sClient.On("GetModule", "team-a", "demo").Return(&v1alpha1.Module{
+				ObjectMeta: v1.ObjectMeta{Name: "demo", Namespace: "team-a", ResourceVersion: "7"},
+				Spec: v1alpha1.ModuleSpec{
+					TargetNamespace: "apps",
+					TemplateRef: v1alpha1.TemplateRef{
+						URL:        "https://github.com/cyclops-ui/templates",
+						Path:       "demo",
+						Version:    "main",
+						SourceType: v1alpha1.TemplateSourceTypeGit,
+					},
+					RevisionHistory: &v1alpha1.RevisionHistoryPolicy{
+						Limit:  &revisionLimit,
+						MaxAge: &v1.Duration{Duration: 720 * time.Hour},
+					},
+				},
+			}, nil)
+
+			templatesRepo.On("GetTemplate", "https://github.com/cyclops-ui/templates", "demo", "main", "", v1alpha1.TemplateSourceTypeGit).Return(&models.Template{
+				HelmChartMetadata: &helm.Metadata{Name: "demo"},
+			}, nil)
+
+			k8sClient.On("UpdateModuleStatus", mock.Anything).Return(&v1alpha1.Module{ObjectMeta: v1.ObjectMeta{ResourceVersion: "8"}}, nil)
+			k8sClient.On("UpdateModule", mock.Anything).Return(nil)
+		})
+
+		It("keeps the revision history policy of the module", func() {
+			body, _ := json.Marshal(dto.Module{
+				Name: "demo",
+				Template: dto.Template{
+					URL:     "https://github.com/cyclops-ui/templates",
+					Path:    "demo",
+					Version: "main",
+				},
+				Values: map[string]interface{}{"replicas": 3},
+			})
+
+			req, _ := http.NewRequest(http.MethodPost, "/modules/update?namespace=team-a", bytes.NewBuffer(body))
+			ctx.Request = req
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+
+			updated := k8sClient.Calls[len(k8sClient.Calls)-1].Arguments.Get(0).(*v1alpha1.Module)
+			Expect(updated.Spec.RevisionHistory).To(BeEquivalentTo(&v1alpha1.RevisionHistoryPolicy{
+				Limit:  &revisionLimit,
+				MaxAge: &v1.Duration{Duration: 720 * time.Hour},
+			}))
+		})
+	})
+
+	Describe("TemplateUpdates method", func() {
+		BeforeEach(func() {
+			r.GET("/modules/:name/template/updates", modulesController.TemplateUpdates)
+
+			k8sClient.On("GetModule", "team-a", "demo").Return(&v1alpha1.Module{
+				ObjectMeta: v1.ObjectMeta{Name: "demo", Namespace: "team-a"},
+				Spec: v1alpha1.ModuleSpec{
+					TemplateRef: v1alpha1.TemplateRef{
+						URL:        "https://charts.example.com",
+						Path:       "demo",
+						Version:    "^1.2.0",
+						SourceType: v1alpha1.TemplateSourceTypeHelm,
+					},
+					UpgradePolicy: &v1alpha1.UpgradePolicy{Mode: v1alpha1.UpgradeModePatch},
+				},
+				Status: v1alpha1.ModuleStatus{TemplateResolvedVersion: "1.2.0"},
+			}, nil)
+		})
+
+		It("returns a newer version outside of the upgrade policy", func() {
+			templatesRepo.On("ResolveVersion", "https://charts.example.com", "demo", "^1.2.0", v1alpha1.TemplateSourceTypeHelm).Return("1.3.1", nil)
+
+			req, _ := http.NewRequest(http.MethodGet, "/modules/demo/template/updates?namespace=team-a", nil)
+			ctx.Request = req
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+
+			var actual dto.TemplateUpdate
+			Expect(json.Unmarshal(w.Body.Bytes(), &actual)).To(BeNil())
+			Expect(actual).To(BeEquivalentTo(dto.TemplateUpdate{
+				CurrentVersion:   "1.2.0",
+				AvailableVersion: "1.3.1",
+				UpgradeAllowed:   false,
+			}))
+		})
+
+		It("returns a patch release allowed by the upgrade policy", func() {
+			templatesRepo.On("ResolveVersion", "https://charts.example.com", "demo", "^1.2.0", v1alpha1.TemplateSourceTypeHelm).Return("1.2.4", nil)
+
+			req, _ := http.NewRequest(http.MethodGet, "/modules/demo/template/updates?namespace=team-a", nil)
+			ctx.Request = req
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+
+			var actual dto.TemplateUpdate
+			Expect(json.Unmarshal(w.Body.Bytes(), &actual)).To(BeNil())
+			Expect(actual).To(BeEquivalentTo(dto.TemplateUpdate{
+				CurrentVersion:   "1.2.0",
+				AvailableVersion: "1.2.4",
+				UpgradeAllowed:   true,
+			}))
+		})
+	})
+
+	Describe("GetModuleHistory method", func() {
+		BeforeEach(func() {
+			r.GET("/modules/:name/history", modulesController.GetModuleHistory)
+
+			k8sClient.On("GetModule", "team-a", "demo").Return(&v1alpha1.Module{
+				ObjectMeta: v1.ObjectMeta{Name: "demo", Namespace: "team-a", Generation: 4},
+				History: []v1alpha1.HistoryEntry{
+					{Generation: 2, TemplateRef: v1alpha1.HistoryTemplateRef{Version: "1.0.0"}},
+					{Generation: 1, TemplateRef: v1alpha1.HistoryTemplateRef{Version: "0.9.0"}},
+				},
+			}, nil)
+			k8sClient.On("ListModuleRevisions", "team-a", "demo").Return([]v1alpha1.ModuleRevision{
+				{Spec: v1alpha1.ModuleRevisionSpec{Module: "demo", Generation: 2, TemplateRef: v1alpha1.HistoryTemplateRef{Version: "1.1.0"}, Author: "jane"}},
+				{Spec: v1alpha1.ModuleRevisionSpec{Module: "demo", Generation: 4, TemplateRef: v1alpha1.HistoryTemplateRef{Version: "1.3.0"}}},
+				{Spec: v1alpha1.ModuleRevisionSpec{Module: "demo", Generation: 3, TemplateRef: v1alpha1.HistoryTemplateRef{Version: "1.2.0"}}},
+			}, nil)
+		})
+
+		It("returns revisions of previous generations and legacy history entries, newest first", func() {
+			req, _ := http.NewRequest(http.MethodGet, "/modules/demo/history?namespace=team-a", nil)
+			ctx.Request = req
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(BeEquivalentTo(http.StatusOK))
+
+			var actual []dto.ModuleRevision
+			Expect(json.Unmarshal(w.Body.Bytes(), &actual)).To(BeNil())
+			Expect(actual).To(HaveLen(3))
+			Expect(actual[0].Generation).To(BeEquivalentTo(3))
+			Expect(actual[1].Generation).To(BeEquivalentTo(2))
+			Expect(actual[1].Template.Version).To(BeEquivalentTo("1.1.0"))
+			Expect(actual[1].Author).To(BeEquivalentTo("jane"))
+			Expect(actual[2].Generation).To(BeEquivalentTo(1))
+			Expect(actual[2].Template.Version).To(BeEquivalentTo("0.9.0"))
+		})
+	})
+})
+
+func configMap(name, replicas string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "apps"},
+		"data":       map[string]interface{}{"replicas": replicas},
+	}}
+}
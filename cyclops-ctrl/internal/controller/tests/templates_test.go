@@ -150,6 +150,119 @@ var _ = Describe("Templates controller test", func() {
 		}
 	})
 
+	Describe("GetTemplateRevisions method", func() {
+		BeforeEach(func() {
+			r.GET("/templates/revisions", templatesController.GetTemplateRevisions)
+		})
+
+		type caseInput struct {
+			repo       string
+			path       string
+			sourceType string
+			prerelease string
+			mockCalls  func()
+		}
+
+		type caseOutput struct {
+			revisions  []models.TemplateRevision
+			statusCode int
+		}
+
+		type testCase struct {
+			description string
+			in          caseInput
+			out         caseOutput
+		}
+
+		expectedRevisions := []models.TemplateRevision{
+			{
+				Name: "1.2.0",
+				Type: models.TemplateRevisionTypeVersion,
+			},
+			{
+				Name: "1.1.0",
+				Type: models.TemplateRevisionTypeVersion,
+			},
+		}
+
+		testCases := []testCase{
+			{
+				description: "returns no revisions on empty template repo",
+				in: caseInput{
+					repo:      "",
+					mockCalls: func() {},
+				},
+				out: caseOutput{
+					revisions:  []models.TemplateRevision{},
+					statusCode: http.StatusOK,
+				},
+			},
+			{
+				description: "fails to list revisions",
+				in: caseInput{
+					repo:       "https://charts.my-org.com",
+					path:       "api",
+					sourceType: "helm",
+					mockCalls: func() {
+						templatesRepo.On(
+							"GetTemplateRevisions", "https://charts.my-org.com", "api", v1alpha1.TemplateSourceTypeHelm, false,
+						).Return(nil, errors.New("some index error"))
+					},
+				},
+				out: caseOutput{
+					statusCode: http.StatusBadRequest,
+				},
+			},
+			{
+				description: "successfully lists revisions with prereleases",
+				in: caseInput{
+					repo:       "https://charts.my-org.com",
+					path:       "api",
+					sourceType: "helm",
+					prerelease: "true",
+					mockCalls: func() {
+						templatesRepo.On(
+							"GetTemplateRevisions", "https://charts.my-org.com", "api", v1alpha1.TemplateSourceTypeHelm, true,
+						).Return(expectedRevisions, nil)
+					},
+				},
+				out: caseOutput{
+					revisions:  expectedRevisions,
+					statusCode: http.StatusOK,
+				},
+			},
+		}
+
+		for _, t := range testCases {
+			Describe(t.description, func() {
+				BeforeEach(func() {
+					t.in.mockCalls()
+				})
+
+				It("returns correct revisions and status code", func() {
+					req, _ := http.NewRequest(http.MethodGet,
+						fmt.Sprintf("/templates/revisions?repo=%v&path=%v&sourceType=%v&prerelease=%v",
+							t.in.repo, t.in.path, t.in.sourceType, t.in.prerelease),
+						nil)
+					ctx.Request = req
+					r.ServeHTTP(w, req)
+
+					Expect(w.Code).To(BeEquivalentTo(t.out.statusCode))
+					if t.out.revisions != nil {
+						b, err := io.ReadAll(w.Result().Body)
+						Expect(err).To(BeNil())
+
+						var actual []models.TemplateRevision
+						err = json.Unmarshal(b, &actual)
+						Expect(err).To(BeNil())
+
+						Expect(actual).To(BeEquivalentTo(t.out.revisions))
+					}
+				})
+			})
+		}
+	})
+
 	Describe("GetTemplateInitialValues method", func() {
 		BeforeEach(func() {
 			r.GET("/templates/initial", templatesController.GetTemplateInitialValues)
@@ -26,7 +26,14 @@ func (s *Server) ExecCommand(c *gin.Context) {
 	}
 	defer conn.Close()
 
-	exec, err := s.k8sClient.CommandExecutor(namespace, pod, container)
+	kubernetesClient, err := s.k8sClient.ForCluster(c.Query("cluster"))
+	if err != nil {
+		fmt.Println(err)
+		c.JSON(http.StatusInternalServerError, dto.NewError("failed to connect to cluster", err.Error()))
+		return
+	}
+
+	exec, err := kubernetesClient.CommandExecutor(namespace, pod, container)
 	if err != nil {
 		fmt.Println(err)
 		c.JSON(http.StatusInternalServerError, dto.NewError("failed to init command exector", err.Error()))
@@ -16,7 +16,7 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"sigs.k8s.io/yaml"
 
@@ -103,15 +103,20 @@ func (c *WriteClient) Write(module cyclopsv1alpha1.Module) error {
 		return errors.New(fmt.Sprintf("failed to fetch creds for repo %v: check template auth rules", repoURL))
 	}
 
+	gitURL, gitAuth, err := auth.GitAuth(repoURL, creds)
+	if err != nil {
+		return err
+	}
+
 	storer := memory.NewStorage()
 	fs := memfs.New()
 
-	repo, worktree, err := cloneRepo(repoURL, revision, storer, &fs, creds)
+	repo, worktree, err := cloneRepo(gitURL, revision, storer, &fs, gitAuth)
 	if err != nil {
 		if errors.Is(err, git.NoMatchingRefSpecError{}) {
 			storer = memory.NewStorage()
 			fs = memfs.New()
-			repo, worktree, err = cloneRepo(repoURL, "", storer, &fs, creds)
+			repo, worktree, err = cloneRepo(gitURL, "", storer, &fs, gitAuth)
 			if err != nil {
 				return err
 			}
@@ -158,7 +163,7 @@ func (c *WriteClient) Write(module cyclopsv1alpha1.Module) error {
 
 	_, err = worktree.Commit(o.String(), &git.CommitOptions{
 		Author: &object.Signature{
-			Name: creds.Username,
+			Name: commitAuthor(creds),
 			When: time.Now(),
 		},
 	})
@@ -167,7 +172,7 @@ func (c *WriteClient) Write(module cyclopsv1alpha1.Module) error {
 	}
 
 	if err := repo.Push(&git.PushOptions{
-		Auth: httpBasicAuthCredentials(creds),
+		Auth: gitAuth,
 	}); err != nil {
 		return fmt.Errorf("failed to push changes: %w", err)
 	}
@@ -189,15 +194,20 @@ func (c *WriteClient) DeleteModule(module cyclopsv1alpha1.Module) error {
 		return err
 	}
 
+	gitURL, gitAuth, err := auth.GitAuth(repoURL, creds)
+	if err != nil {
+		return err
+	}
+
 	storer := memory.NewStorage()
 	fs := memfs.New()
 
-	repo, worktree, err := cloneRepo(repoURL, revision, storer, &fs, creds)
+	repo, worktree, err := cloneRepo(gitURL, revision, storer, &fs, gitAuth)
 	if err != nil {
 		if errors.Is(err, git.NoMatchingRefSpecError{}) {
 			storer = memory.NewStorage()
 			fs = memfs.New()
-			repo, worktree, err = cloneRepo(repoURL, "", storer, &fs, creds)
+			repo, worktree, err = cloneRepo(gitURL, "", storer, &fs, gitAuth)
 			if err != nil {
 				return err
 			}
@@ -233,7 +243,7 @@ func (c *WriteClient) DeleteModule(module cyclopsv1alpha1.Module) error {
 
 	_, err = worktree.Commit(o.String(), &git.CommitOptions{
 		Author: &object.Signature{
-			Name: creds.Username,
+			Name: commitAuthor(creds),
 			When: time.Now(),
 		},
 	})
@@ -242,7 +252,7 @@ func (c *WriteClient) DeleteModule(module cyclopsv1alpha1.Module) error {
 	}
 
 	if err := repo.Push(&git.PushOptions{
-		Auth: httpBasicAuthCredentials(creds),
+		Auth: gitAuth,
 	}); err != nil {
 		return fmt.Errorf("failed to push changes: %w", err)
 	}
@@ -258,10 +268,10 @@ func moduleFilePath(path, moduleName string) string {
 	return path
 }
 
-func cloneRepo(url, revision string, storer *memory.Storage, fs *billy.Filesystem, creds *auth.Credentials) (*git.Repository, *git.Worktree, error) {
+func cloneRepo(url, revision string, storer *memory.Storage, fs *billy.Filesystem, gitAuth transport.AuthMethod) (*git.Repository, *git.Worktree, error) {
 	cloneOpts := git.CloneOptions{
 		URL:          url,
-		Auth:         httpBasicAuthCredentials(creds),
+		Auth:         gitAuth,
 		SingleBranch: true,
 	}
 
@@ -282,13 +292,12 @@ func cloneRepo(url, revision string, storer *memory.Storage, fs *billy.Filesyste
 	return repo, worktree, nil
 }
 
-func httpBasicAuthCredentials(creds *auth.Credentials) *http.BasicAuth {
-	if creds == nil {
-		return nil
+// commitAuthor returns the author of commits pushed with the credentials. Credentials of SSH deploy keys have no
+// username.
+func commitAuthor(creds *auth.Credentials) string {
+	if creds == nil || len(creds.Username) == 0 {
+		return "cyclops"
 	}
 
-	return &http.BasicAuth{
-		Username: creds.Username,
-		Password: creds.Password,
-	}
+	return creds.Username
 }
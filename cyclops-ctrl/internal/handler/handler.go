@@ -27,6 +27,7 @@ type Handler struct {
 	gitWriteClient *git.WriteClient
 
 	moduleTargetNamespace string
+	trustedAuthHeader     string
 
 	telemetryClient telemetry.Client
 	monitor         prometheus.Monitor
@@ -39,6 +40,7 @@ func New(
 	renderer *render.Renderer,
 	gitWriteClient *git.WriteClient,
 	moduleTargetNamespace string,
+	trustedAuthHeader string,
 	telemetryClient telemetry.Client,
 	monitor prometheus.Monitor,
 ) (*Handler, error) {
@@ -49,6 +51,7 @@ func New(
 		releaseClient:         releaseClient,
 		gitWriteClient:        gitWriteClient,
 		moduleTargetNamespace: moduleTargetNamespace,
+		trustedAuthHeader:     trustedAuthHeader,
 		telemetryClient:       telemetryClient,
 		monitor:               monitor,
 	}, nil
@@ -58,7 +61,7 @@ func (h *Handler) Start() error {
 	gin.SetMode(gin.DebugMode)
 
 	templatesController := controller.NewTemplatesController(h.templatesRepo, h.k8sClient, h.telemetryClient)
-	modulesController := controller.NewModulesController(h.templatesRepo, h.k8sClient, h.renderer, h.gitWriteClient, h.moduleTargetNamespace, h.telemetryClient, h.monitor)
+	modulesController := controller.NewModulesController(h.templatesRepo, h.k8sClient, h.renderer, h.gitWriteClient, h.moduleTargetNamespace, h.trustedAuthHeader, h.telemetryClient, h.monitor)
 	clusterController := controller.NewClusterController(h.k8sClient)
 	helmController := controller.NewHelmController(h.k8sClient, h.releaseClient, h.telemetryClient)
 
@@ -99,9 +102,12 @@ func (h *Handler) Start() error {
 	h.router.POST("/modules/:name/reconcile", modulesController.ReconcileModule)
 	h.router.GET("/modules/:name/history", modulesController.GetModuleHistory)
 	h.router.POST("/modules/:name/manifest", modulesController.Manifest)
+	h.router.POST("/modules/:name/plan", modulesController.PlanModule)
 	h.router.GET("/modules/:name/currentManifest", modulesController.CurrentManifest)
 	h.router.GET("/modules/:name/resources", modulesController.ResourcesForModule)
+	h.router.GET("/modules/:name/events", modulesController.GetModuleEvents)
 	h.router.GET("/modules/:name/template", modulesController.Template)
+	h.router.GET("/modules/:name/template/updates", modulesController.TemplateUpdates)
 	h.router.GET("/modules/:name/helm-template", modulesController.HelmTemplate)
 	//h.router.POST("/modules/resources", modulesController.ModuleToResources)
 
@@ -123,6 +129,8 @@ func (h *Handler) Start() error {
 
 	h.router.GET("/namespaces", clusterController.ListNamespaces)
 
+	h.router.GET("/clusters", clusterController.ListClusters)
+
 	// region helm migrator
 	h.router.GET("/helm/releases", helmController.ListReleases)
 	h.router.GET("/helm/releases/:namespace/:name", helmController.GetRelease)
@@ -1,9 +1,12 @@
 package mapper
 
 import (
+	"sort"
 	"strings"
+	"time"
 
 	json "github.com/json-iterator/go"
+	apiv1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -38,6 +41,7 @@ func RequestToModule(req dto.Module) (cyclopsv1alpha1.Module, error) {
 		},
 		Spec: cyclopsv1alpha1.ModuleSpec{
 			TargetNamespace: mapTargetNamespace(req.Namespace),
+			Cluster:         req.Cluster,
 			TemplateRef:     DtoTemplateRefToK8s(req.Template),
 			Values: apiextensionsv1.JSON{
 				Raw: data,
@@ -52,15 +56,119 @@ func ModuleToDTO(module cyclopsv1alpha1.Module) (dto.Module, error) {
 		Name:                 module.Name,
 		Namespace:            module.Namespace,
 		TargetNamespace:      mapTargetNamespace(module.Spec.TargetNamespace),
+		Cluster:              module.Spec.Cluster,
 		Version:              module.Spec.TemplateRef.Version,
 		Template:             k8sTemplateRefToDTO(module.Spec.TemplateRef, module.Status.TemplateResolvedVersion),
 		Values:               module.Spec.Values,
 		IconURL:              module.Status.IconURL,
 		GitOpsWrite:          mapGitOpsWrite(module),
 		ReconciliationStatus: ReconciliationStatusToDTO(module.Status.ReconciliationStatus),
+		ObservedGeneration:   module.Status.ObservedGeneration,
+		Conditions:           ConditionsToDTO(module.Status.Conditions),
 	}, nil
 }
 
+func ConditionsToDTO(conditions []metav1.Condition) []dto.Condition {
+	out := make([]dto.Condition, 0, len(conditions))
+
+	for _, condition := range conditions {
+		out = append(out, dto.Condition{
+			Type:               condition.Type,
+			Status:             string(condition.Status),
+			ObservedGeneration: condition.ObservedGeneration,
+			LastTransitionTime: condition.LastTransitionTime.Format(time.RFC3339),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+		})
+	}
+
+	return out
+}
+
+// EventsToDTO maps module events ordered from the most recently seen one
+func EventsToDTO(events []apiv1.Event) []dto.Event {
+	sorted := make([]apiv1.Event, len(events))
+	copy(sorted, events)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return eventLastSeen(sorted[i]).After(eventLastSeen(sorted[j]))
+	})
+
+	out := make([]dto.Event, 0, len(sorted))
+	for _, event := range sorted {
+		count := event.Count
+		if event.Series != nil {
+			count = event.Series.Count
+		}
+		if count == 0 {
+			count = 1
+		}
+
+		out = append(out, dto.Event{
+			Type:      event.Type,
+			Reason:    event.Reason,
+			Message:   event.Message,
+			Count:     count,
+			FirstSeen: formatEventTime(eventFirstSeen(event)),
+			LastSeen:  formatEventTime(eventLastSeen(event)),
+		})
+	}
+
+	return out
+}
+
+// eventFirstSeen and eventLastSeen fall back to the event time for events recorded through the events.k8s.io API
+func eventFirstSeen(event apiv1.Event) time.Time {
+	if !event.FirstTimestamp.IsZero() {
+		return event.FirstTimestamp.Time
+	}
+
+	return event.EventTime.Time
+}
+
+func eventLastSeen(event apiv1.Event) time.Time {
+	if event.Series != nil && !event.Series.LastObservedTime.IsZero() {
+		return event.Series.LastObservedTime.Time
+	}
+
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+
+	return eventFirstSeen(event)
+}
+
+func formatEventTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func ModuleRevisionsToDTO(revisions []cyclopsv1alpha1.ModuleRevision) []dto.ModuleRevision {
+	out := make([]dto.ModuleRevision, 0, len(revisions))
+
+	for _, revision := range revisions {
+		out = append(out, dto.ModuleRevision{
+			Generation:      revision.Spec.Generation,
+			TargetNamespace: revision.Spec.TargetNamespace,
+			Template: dto.Template{
+				URL:        revision.Spec.TemplateRef.URL,
+				Path:       revision.Spec.TemplateRef.Path,
+				Version:    revision.Spec.TemplateRef.Version,
+				SourceType: string(revision.Spec.TemplateRef.SourceType),
+			},
+			Values:       revision.Spec.Values,
+			ManifestHash: revision.Spec.ManifestHash,
+			Author:       revision.Spec.Author,
+			CreatedAt:    formatEventTime(revision.CreationTimestamp.Time),
+		})
+	}
+
+	return out
+}
+
 func ReconciliationStatusToDTO(status *cyclopsv1alpha1.ReconciliationStatus) dto.ReconciliationStatus {
 	if status == nil {
 		return dto.ReconciliationStatus{Status: dto.Unknown}
@@ -79,13 +187,16 @@ func ModuleListToDTO(modules []cyclopsv1alpha1.Module) []dto.Module {
 	for _, module := range modules {
 		values := make(map[string]interface{})
 		out = append(out, dto.Module{
-			Name:            module.Name,
-			Namespace:       module.Namespace,
-			TargetNamespace: mapTargetNamespace(module.Spec.TargetNamespace),
-			Version:         module.Spec.TemplateRef.Version,
-			Template:        k8sTemplateRefToDTO(module.Spec.TemplateRef, module.Status.TemplateResolvedVersion),
-			Values:          values,
-			IconURL:         module.Status.IconURL,
+			Name:               module.Name,
+			Namespace:          module.Namespace,
+			TargetNamespace:    mapTargetNamespace(module.Spec.TargetNamespace),
+			Cluster:            module.Spec.Cluster,
+			Version:            module.Spec.TemplateRef.Version,
+			Template:           k8sTemplateRefToDTO(module.Spec.TemplateRef, module.Status.TemplateResolvedVersion),
+			Values:             values,
+			IconURL:            module.Status.IconURL,
+			ObservedGeneration: module.Status.ObservedGeneration,
+			Conditions:         ConditionsToDTO(module.Status.Conditions),
 		})
 	}
 
@@ -2,9 +2,12 @@ package mapper
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/dto"
@@ -108,4 +111,77 @@ var _ = Describe("Module mapper test", func() {
 			}
 		})
 	})
+
+	Describe("ConditionsToDTO", func() {
+		It("maps conditions correctly", func() {
+			transitionTime := metav1.NewTime(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+
+			actual := ConditionsToDTO([]metav1.Condition{
+				{
+					Type:               cyclopsv1alpha1.ConditionReady,
+					Status:             metav1.ConditionFalse,
+					ObservedGeneration: 3,
+					LastTransitionTime: transitionTime,
+					Reason:             cyclopsv1alpha1.ReasonApplyFailed,
+					Message:            "error decoding/applying resources",
+				},
+			})
+
+			Expect(actual).To(BeEquivalentTo([]dto.Condition{
+				{
+					Type:               "Ready",
+					Status:             "False",
+					ObservedGeneration: 3,
+					LastTransitionTime: "2024-05-01T12:00:00Z",
+					Reason:             "ApplyFailed",
+					Message:            "error decoding/applying resources",
+				},
+			}))
+		})
+	})
+
+	Describe("EventsToDTO", func() {
+		It("maps events from the most recently seen one", func() {
+			older := metav1.NewTime(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+			newer := metav1.NewTime(time.Date(2024, 5, 1, 12, 5, 0, 0, time.UTC))
+
+			actual := EventsToDTO([]apiv1.Event{
+				{
+					Type:           apiv1.EventTypeNormal,
+					Reason:         "Pruned",
+					Message:        "apps/v1, Kind=Deployment default/app: removed from the module template",
+					Count:          1,
+					FirstTimestamp: older,
+					LastTimestamp:  older,
+				},
+				{
+					Type:           apiv1.EventTypeWarning,
+					Reason:         "RenderFailed",
+					Message:        "template: invalid values",
+					Count:          3,
+					FirstTimestamp: older,
+					LastTimestamp:  newer,
+				},
+			})
+
+			Expect(actual).To(BeEquivalentTo([]dto.Event{
+				{
+					Type:      "Warning",
+					Reason:    "RenderFailed",
+					Message:   "template: invalid values",
+					Count:     3,
+					FirstSeen: "2024-05-01T12:00:00Z",
+					LastSeen:  "2024-05-01T12:05:00Z",
+				},
+				{
+					Type:      "Normal",
+					Reason:    "Pruned",
+					Message:   "apps/v1, Kind=Deployment default/app: removed from the module template",
+					Count:     1,
+					FirstSeen: "2024-05-01T12:00:00Z",
+					LastSeen:  "2024-05-01T12:00:00Z",
+				},
+			}))
+		})
+	})
 })
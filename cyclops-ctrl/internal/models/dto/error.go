@@ -21,3 +21,22 @@ func NewResponse(message string) Response {
 		Message: message,
 	}
 }
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Message     string       `json:"message"`
+	Description string       `json:"description"`
+	Fields      []FieldError `json:"fields"`
+}
+
+func NewValidationError(message, description string, fields []FieldError) ValidationError {
+	return ValidationError{
+		Message:     message,
+		Description: description,
+		Fields:      fields,
+	}
+}
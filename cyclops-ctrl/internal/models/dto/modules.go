@@ -4,6 +4,7 @@ type Module struct {
 	Name                 string               `json:"name"`
 	Namespace            string               `json:"namespace"`
 	TargetNamespace      string               `json:"targetNamespace"`
+	Cluster              string               `json:"cluster,omitempty"`
 	GitOpsWrite          *GitOpsWrite         `json:"gitOpsWrite,omitempty"`
 	Template             Template             `json:"template"`
 	Version              string               `json:"version"`
@@ -11,14 +12,19 @@ type Module struct {
 	Status               string               `json:"status"`
 	IconURL              string               `json:"iconURL"`
 	ReconciliationStatus ReconciliationStatus `json:"reconciliationStatus"`
+	ObservedGeneration   int64                `json:"observedGeneration"`
+	Conditions           []Condition          `json:"conditions,omitempty"`
 }
 
 type ReconciliationStatusState string
 
 const (
-	Unknown   ReconciliationStatusState = "unknown"
-	Succeeded ReconciliationStatusState = "succeeded"
-	Failed    ReconciliationStatusState = "failed"
+	Unknown     ReconciliationStatusState = "unknown"
+	Succeeded   ReconciliationStatusState = "succeeded"
+	Failed      ReconciliationStatusState = "failed"
+	Drifted     ReconciliationStatusState = "drifted"
+	Progressing ReconciliationStatusState = "progressing"
+	Degraded    ReconciliationStatusState = "degraded"
 )
 
 // ReconciliationStatus represents the status of the reconciliation process.
@@ -28,6 +34,67 @@ type ReconciliationStatus struct {
 	Errors []string                  `json:"errors,omitempty"`
 }
 
+// Condition is a Kubernetes-style condition of the module reconciliation
+type Condition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+}
+
+// Event is a Kubernetes event recorded on a module
+type Event struct {
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+	Count     int32  `json:"count"`
+	FirstSeen string `json:"firstSeen,omitempty"`
+	LastSeen  string `json:"lastSeen,omitempty"`
+}
+
+// ModuleRevision is a previous generation of a module the module can be rolled back to
+type ModuleRevision struct {
+	Generation      int64       `json:"generation"`
+	TargetNamespace string      `json:"targetNamespace"`
+	Template        Template    `json:"template"`
+	Values          interface{} `json:"values"`
+	ManifestHash    string      `json:"manifestHash,omitempty"`
+	Author          string      `json:"author,omitempty"`
+	CreatedAt       string      `json:"createdAt,omitempty"`
+}
+
+type PlanAction string
+
+const (
+	PlanCreate    PlanAction = "create"
+	PlanUpdate    PlanAction = "update"
+	PlanDelete    PlanAction = "delete"
+	PlanUnchanged PlanAction = "unchanged"
+)
+
+// ResourcePlan is the change applying a module spec would make to one of the module resources. Fields lists
+// the paths of fields that would change on update, Error is set if the dry-run apply of the resource failed.
+type ResourcePlan struct {
+	Group     string     `json:"group"`
+	Version   string     `json:"version"`
+	Kind      string     `json:"kind"`
+	Name      string     `json:"name"`
+	Namespace string     `json:"namespace"`
+	Action    PlanAction `json:"action"`
+	Fields    []string   `json:"fields,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// TemplateUpdate is a newer version of the module template. UpgradeAllowed is true if the module upgrade policy
+// allows upgrading to it automatically.
+type TemplateUpdate struct {
+	CurrentVersion   string `json:"currentVersion"`
+	AvailableVersion string `json:"availableVersion,omitempty"`
+	UpgradeAllowed   bool   `json:"upgradeAllowed"`
+}
+
 type Template struct {
 	URL             string `json:"repo" binding:"required"`
 	Path            string `json:"path" binding:"required"`
@@ -50,6 +117,8 @@ type TemplatesResponse struct {
 type RollbackRequest struct {
 	ModuleName string `json:"moduleName"`
 	Generation int64  `json:"generation"`
+	// ManifestHash selects one of the revisions of the generation; the newest one is used if it is empty
+	ManifestHash string `json:"manifestHash,omitempty"`
 }
 
 type DeleteResource struct {
@@ -1,10 +1,13 @@
 package helm
 
+import "time"
+
 type Index struct {
 	Entries map[string][]IndexEntry `json:"entries" yaml:"entries"`
 }
 
 type IndexEntry struct {
-	Version string   `json:"version" yaml:"version"`
-	URLs    []string `json:"urls" yaml:"urls"`
+	Version string    `json:"version" yaml:"version"`
+	URLs    []string  `json:"urls" yaml:"urls"`
+	Created time.Time `json:"created" yaml:"created"`
 }
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/helm"
 	"helm.sh/helm/v3/pkg/chart"
 
@@ -29,6 +31,22 @@ type Template struct {
 	Condition    string      `json:"condition"`
 }
 
+type TemplateRevisionType string
+
+const (
+	TemplateRevisionTypeBranch  TemplateRevisionType = "branch"
+	TemplateRevisionTypeTag     TemplateRevisionType = "tag"
+	TemplateRevisionTypeVersion TemplateRevisionType = "version"
+)
+
+// TemplateRevision is a version a template reference can point to; a git branch or tag, or a chart version
+type TemplateRevision struct {
+	Name string               `json:"name"`
+	Type TemplateRevisionType `json:"type"`
+	// Date is set for chart versions of Helm repos, as listed in the repo index
+	Date *time.Time `json:"date,omitempty"`
+}
+
 type Field struct {
 	Name          string        `json:"name"`
 	Description   string        `json:"description"`
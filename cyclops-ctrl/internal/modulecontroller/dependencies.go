@@ -0,0 +1,113 @@
+package modulecontroller
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// notReadyDependencies returns the names of modules from dependsOn that are missing or not ready. A module is
+// ready once its Ready condition is true for its latest generation.
+func (r *ModuleReconciler) notReadyDependencies(ctx context.Context, module cyclopsv1alpha1.Module) ([]string, error) {
+	notReady := make([]string, 0)
+
+	for _, dependency := range module.Spec.DependsOn {
+		var dependencyModule cyclopsv1alpha1.Module
+		err := r.Get(ctx, types.NamespacedName{Namespace: module.Namespace, Name: dependency.Name}, &dependencyModule)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				notReady = append(notReady, fmt.Sprintf("%v (not found)", dependency.Name))
+				continue
+			}
+
+			return nil, err
+		}
+
+		if !isModuleReady(dependencyModule) {
+			notReady = append(notReady, dependency.Name)
+		}
+	}
+
+	return notReady, nil
+}
+
+// dependencyCycle returns the names of modules forming a cycle through dependsOn that the module is part of,
+// starting and ending with the module, or nil if there is no such cycle. Missing dependencies are skipped, they
+// are reported by notReadyDependencies.
+func (r *ModuleReconciler) dependencyCycle(ctx context.Context, module cyclopsv1alpha1.Module) ([]string, error) {
+	visited := make(map[string]bool)
+
+	var visit func(path []string, dependsOn []cyclopsv1alpha1.ModuleReference) ([]string, error)
+	visit = func(path []string, dependsOn []cyclopsv1alpha1.ModuleReference) ([]string, error) {
+		for _, dependency := range dependsOn {
+			dependencyPath := append(path[:len(path):len(path)], dependency.Name)
+
+			if dependency.Name == module.Name {
+				return dependencyPath, nil
+			}
+
+			if visited[dependency.Name] {
+				continue
+			}
+			visited[dependency.Name] = true
+
+			var dependencyModule cyclopsv1alpha1.Module
+			err := r.Get(ctx, types.NamespacedName{Namespace: module.Namespace, Name: dependency.Name}, &dependencyModule)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+
+				return nil, err
+			}
+
+			cycle, err := visit(dependencyPath, dependencyModule.Spec.DependsOn)
+			if err != nil || cycle != nil {
+				return cycle, err
+			}
+		}
+
+		return nil, nil
+	}
+
+	return visit([]string{module.Name}, module.Spec.DependsOn)
+}
+
+func isModuleReady(module cyclopsv1alpha1.Module) bool {
+	return module.Status.ObservedGeneration == module.Generation &&
+		meta.IsStatusConditionTrue(module.Status.Conditions, cyclopsv1alpha1.ConditionReady)
+}
+
+// dependentModules maps a module to all modules that depend on it, so they are reconciled as soon as
+// the module becomes ready
+func (r *ModuleReconciler) dependentModules(ctx context.Context, obj client.Object) []reconcile.Request {
+	var modules cyclopsv1alpha1.ModuleList
+	if err := r.List(ctx, &modules, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.logger.Error(err, "error listing modules for dependency", "module", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for _, module := range modules.Items {
+		for _, dependency := range module.Spec.DependsOn {
+			if dependency.Name == obj.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Name:      module.Name,
+						Namespace: module.Namespace,
+					},
+				})
+				break
+			}
+		}
+	}
+
+	return requests
+}
@@ -0,0 +1,190 @@
+package modulecontroller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+var _ = Describe("Module dependencies", func() {
+	module := func(name string, dependsOn ...string) *cyclopsv1alpha1.Module {
+		references := make([]cyclopsv1alpha1.ModuleReference, 0, len(dependsOn))
+		for _, dependency := range dependsOn {
+			references = append(references, cyclopsv1alpha1.ModuleReference{Name: dependency})
+		}
+
+		return &cyclopsv1alpha1.Module{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", Generation: 2},
+			Spec:       cyclopsv1alpha1.ModuleSpec{DependsOn: references},
+		}
+	}
+
+	withReady := func(m *cyclopsv1alpha1.Module, observedGeneration int64, status metav1.ConditionStatus) *cyclopsv1alpha1.Module {
+		m.Status.ObservedGeneration = observedGeneration
+		m.Status.Conditions = []metav1.Condition{{
+			Type:               cyclopsv1alpha1.ConditionReady,
+			Status:             status,
+			ObservedGeneration: observedGeneration,
+			Reason:             "Test",
+		}}
+		return m
+	}
+
+	newReconciler := func(modules ...*cyclopsv1alpha1.Module) *ModuleReconciler {
+		objects := make([]client.Object, 0, len(modules))
+		for _, m := range modules {
+			objects = append(objects, m)
+		}
+
+		return &ModuleReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build(),
+			Scheme: scheme.Scheme,
+		}
+	}
+
+	Describe("notReadyDependencies", func() {
+		type testCase struct {
+			description string
+			modules     []*cyclopsv1alpha1.Module
+			notReady    []string
+		}
+
+		testCases := []testCase{
+			{
+				description: "no dependencies",
+				modules:     []*cyclopsv1alpha1.Module{module("app")},
+				notReady:    []string{},
+			},
+			{
+				description: "ready dependencies",
+				modules: []*cyclopsv1alpha1.Module{
+					module("app", "database", "cache"),
+					withReady(module("database"), 2, metav1.ConditionTrue),
+					withReady(module("cache"), 2, metav1.ConditionTrue),
+				},
+				notReady: []string{},
+			},
+			{
+				description: "missing dependency",
+				modules: []*cyclopsv1alpha1.Module{
+					module("app", "database"),
+				},
+				notReady: []string{"database (not found)"},
+			},
+			{
+				description: "dependency with a false ready condition",
+				modules: []*cyclopsv1alpha1.Module{
+					module("app", "database"),
+					withReady(module("database"), 2, metav1.ConditionFalse),
+				},
+				notReady: []string{"database"},
+			},
+			{
+				description: "dependency ready for a previous generation",
+				modules: []*cyclopsv1alpha1.Module{
+					module("app", "database", "cache"),
+					withReady(module("database"), 1, metav1.ConditionTrue),
+					withReady(module("cache"), 2, metav1.ConditionTrue),
+				},
+				notReady: []string{"database"},
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				reconciler := newReconciler(t.modules...)
+
+				notReady, err := reconciler.notReadyDependencies(context.Background(), *t.modules[0])
+				Expect(err).To(BeNil())
+				Expect(notReady).To(Equal(t.notReady))
+			})
+		}
+	})
+
+	Describe("dependencyCycle", func() {
+		type testCase struct {
+			description string
+			modules     []*cyclopsv1alpha1.Module
+			cycle       []string
+		}
+
+		testCases := []testCase{
+			{
+				description: "no cycle",
+				modules: []*cyclopsv1alpha1.Module{
+					module("app", "database", "cache"),
+					module("database"),
+					module("cache", "database"),
+				},
+			},
+			{
+				description: "module depending on itself",
+				modules: []*cyclopsv1alpha1.Module{
+					module("app", "app"),
+				},
+				cycle: []string{"app", "app"},
+			},
+			{
+				description: "cycle through other modules",
+				modules: []*cyclopsv1alpha1.Module{
+					module("app", "cache"),
+					module("cache", "database"),
+					module("database", "app"),
+				},
+				cycle: []string{"app", "cache", "database", "app"},
+			},
+			{
+				description: "cycle the module is not part of",
+				modules: []*cyclopsv1alpha1.Module{
+					module("app", "cache"),
+					module("cache", "database"),
+					module("database", "cache"),
+				},
+			},
+			{
+				description: "missing dependency",
+				modules: []*cyclopsv1alpha1.Module{
+					module("app", "database"),
+				},
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				reconciler := newReconciler(t.modules...)
+
+				cycle, err := reconciler.dependencyCycle(context.Background(), *t.modules[0])
+				Expect(err).To(BeNil())
+				Expect(cycle).To(Equal(t.cycle))
+			})
+		}
+	})
+
+	Describe("dependentModules", func() {
+		It("returns modules depending on the module", func() {
+			reconciler := newReconciler(
+				module("database"),
+				module("app", "database"),
+				module("worker", "cache", "database"),
+				module("cache"),
+			)
+			reconciler.logger = logr.Discard()
+
+			requests := reconciler.dependentModules(context.Background(), module("database"))
+
+			names := make([]string, 0, len(requests))
+			for _, request := range requests {
+				names = append(names, request.Name)
+			}
+			Expect(names).To(ConsistOf("app", "worker"))
+		})
+	})
+})
@@ -0,0 +1,50 @@
+package modulecontroller
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/dto"
+)
+
+// Reasons of the events the module controller records on modules
+const (
+	eventReasonTemplateFetchFailed = cyclopsv1alpha1.ReasonTemplateFetchFailed
+	eventReasonRenderFailed        = cyclopsv1alpha1.ReasonRenderFailed
+	eventReasonInvalidValues       = cyclopsv1alpha1.ReasonInvalidValues
+	eventReasonApplyFailed         = cyclopsv1alpha1.ReasonApplyFailed
+	eventReasonPruned              = "Pruned"
+	eventReasonPruneFailed         = "PruneFailed"
+	eventReasonFinalized           = "Finalized"
+	eventReasonFinalizeFailed      = "FinalizeFailed"
+	eventReasonTemplateUpgraded    = "TemplateUpgraded"
+	eventReasonClusterUnavailable  = cyclopsv1alpha1.ReasonClusterUnavailable
+)
+
+// objectEvent records an event on the module about one of the resources rendered from its template
+func (r *ModuleReconciler) objectEvent(module *cyclopsv1alpha1.Module, eventType, reason string, obj *unstructured.Unstructured, message string) {
+	r.recorder.Eventf(
+		module,
+		eventType,
+		reason,
+		"%v %v/%v: %v",
+		obj.GroupVersionKind().String(),
+		obj.GetNamespace(),
+		obj.GetName(),
+		message,
+	)
+}
+
+// resourceEvent records an event on the module about one of its live resources
+func (r *ModuleReconciler) resourceEvent(module *cyclopsv1alpha1.Module, eventType, reason string, resource *dto.Resource, message string) {
+	r.recorder.Eventf(
+		module,
+		eventType,
+		reason,
+		"%v %v/%v: %v",
+		resource.GetGroupVersionKind(),
+		resource.GetNamespace(),
+		resource.GetName(),
+		message,
+	)
+}
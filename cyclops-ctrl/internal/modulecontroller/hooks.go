@@ -0,0 +1,401 @@
+package modulecontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+)
+
+const (
+	hookTimeout = 5 * time.Minute
+	// hookRequeueAfter is how often modules are reconciled while a hook is running
+	hookRequeueAfter = 5 * time.Second
+)
+
+// hook is a resource rendered from the module template with the helm.sh/hook annotation. Only install and
+// upgrade hooks are run by the module controller, hooks of other events are never applied.
+type hook struct {
+	obj            unstructured.Unstructured
+	events         []string
+	weight         int
+	deletePolicies []string
+}
+
+// splitHooks separates Helm hooks from the other resources of a rendered manifest
+func splitHooks(objects []unstructured.Unstructured) ([]unstructured.Unstructured, []hook) {
+	resources := make([]unstructured.Unstructured, 0, len(objects))
+	hooks := make([]hook, 0)
+
+	for _, obj := range objects {
+		events, ok := obj.GetAnnotations()[release.HookAnnotation]
+		if !ok {
+			resources = append(resources, obj)
+			continue
+		}
+
+		// invalid weights are ignored the same way helm ignores them
+		weight, _ := strconv.Atoi(obj.GetAnnotations()[release.HookWeightAnnotation])
+
+		hooks = append(hooks, hook{
+			obj:            obj,
+			events:         splitAnnotationList(events),
+			weight:         weight,
+			deletePolicies: splitAnnotationList(obj.GetAnnotations()[release.HookDeleteAnnotation]),
+		})
+	}
+
+	sort.SliceStable(hooks, func(i, j int) bool {
+		if hooks[i].weight != hooks[j].weight {
+			return hooks[i].weight < hooks[j].weight
+		}
+		return hooks[i].obj.GetName() < hooks[j].obj.GetName()
+	})
+
+	return resources, hooks
+}
+
+// pendingHook is a hook that was started and did not complete yet, so the resources and hooks following it were
+// not applied
+type pendingHook struct {
+	obj     unstructured.Unstructured
+	event   release.HookEvent
+	message string
+}
+
+func splitAnnotationList(value string) []string {
+	out := make([]string, 0)
+	for _, v := range strings.Split(value, ",") {
+		if v := strings.TrimSpace(v); len(v) != 0 {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
+
+func (h hook) hasEvent(event release.HookEvent) bool {
+	for _, e := range h.events {
+		if e == string(event) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h hook) hasDeletePolicy(policy release.HookDeletePolicy) bool {
+	// helm deletes hooks before creating them again if no policy is set
+	if len(h.deletePolicies) == 0 {
+		return policy == release.HookBeforeHookCreation
+	}
+
+	for _, p := range h.deletePolicies {
+		if p == string(policy) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hookEvents returns the pre and post hook events for applying the manifest identified by manifestHash. A module
+// is installed until all of its resources were applied once, hooks that already ran for the manifest keep
+// their event so a reconciliation interrupted by a sync wave does not switch from install to upgrade hooks.
+func hookEvents(module cyclopsv1alpha1.Module, manifestHash string) (release.HookEvent, release.HookEvent) {
+	for _, hookStatus := range module.Status.Hooks {
+		if hookStatus.ManifestHash != manifestHash {
+			continue
+		}
+
+		if hookStatus.Event == string(release.HookPreInstall) || hookStatus.Event == string(release.HookPostInstall) {
+			return release.HookPreInstall, release.HookPostInstall
+		}
+
+		return release.HookPreUpgrade, release.HookPostUpgrade
+	}
+
+	if len(module.Status.ManagedGVRs) == 0 {
+		return release.HookPreInstall, release.HookPostInstall
+	}
+
+	return release.HookPreUpgrade, release.HookPostUpgrade
+}
+
+// runHooks runs hooks of the event ordered by their weight and stops at the first hook that fails or is still
+// running. Hooks that already succeeded for the manifest are not run again and hooks that failed for the manifest
+// keep failing until the manifest changes. Outcomes are recorded in the module status, running hooks are checked
+// again by the next reconciliation so workers are not blocked while they run.
+func (r *ModuleReconciler) runHooks(
+	kClient k8sclient.IKubernetesClient,
+	module *cyclopsv1alpha1.Module,
+	hooks []hook,
+	event release.HookEvent,
+	manifestHash string,
+) ([]string, *pendingHook) {
+	for _, h := range hooks {
+		if !h.hasEvent(event) {
+			continue
+		}
+
+		var status cyclopsv1alpha1.HookStatus
+		recorded := recordedHook(*module, h, event, manifestHash)
+		switch {
+		case recorded != nil && recorded.Phase == cyclopsv1alpha1.HookPhaseSucceeded:
+			continue
+		case recorded != nil && recorded.Phase == cyclopsv1alpha1.HookPhaseFailed:
+			status = *recorded
+		default:
+			status = r.runHook(kClient, *module, h, event, manifestHash)
+			setHookStatus(module, status)
+		}
+
+		switch status.Phase {
+		case cyclopsv1alpha1.HookPhaseRunning:
+			return nil, &pendingHook{
+				obj:     h.obj,
+				event:   event,
+				message: status.Message,
+			}
+		case cyclopsv1alpha1.HookPhaseFailed:
+			return []string{fmt.Sprintf(
+				"%v%v/%v %v/%v %v hook failed: %v",
+				h.obj.GroupVersionKind().Group,
+				h.obj.GroupVersionKind().Version,
+				h.obj.GroupVersionKind().Kind,
+				h.obj.GetNamespace(),
+				h.obj.GetName(),
+				event,
+				status.Message,
+			)}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// runHook creates the hook if it was not created for the manifest yet and returns its current status
+func (r *ModuleReconciler) runHook(
+	kClient k8sclient.IKubernetesClient,
+	module cyclopsv1alpha1.Module,
+	h hook,
+	event release.HookEvent,
+	manifestHash string,
+) cyclopsv1alpha1.HookStatus {
+	obj := h.obj.DeepCopy()
+
+	status := cyclopsv1alpha1.HookStatus{
+		Kind:         obj.GetKind(),
+		Name:         obj.GetName(),
+		Event:        string(event),
+		Phase:        cyclopsv1alpha1.HookPhaseRunning,
+		ManifestHash: manifestHash,
+	}
+
+	resourceName, err := kClient.GVKtoAPIResourceName(obj.GroupVersionKind().GroupVersion(), obj.GroupVersionKind().Kind)
+	if err != nil {
+		return completeHook(status, cyclopsv1alpha1.HookPhaseFailed, err.Error())
+	}
+
+	gvr := cyclopsv1alpha1.GroupVersionResource{
+		Group:    obj.GroupVersionKind().Group,
+		Version:  obj.GroupVersionKind().Version,
+		Resource: resourceName,
+	}
+
+	// hooks without a start time were not created yet, they might be waiting for the previous run to be deleted
+	running := recordedHook(module, h, event, manifestHash)
+	if running != nil && running.Phase == cyclopsv1alpha1.HookPhaseRunning && running.StartedAt != nil {
+		status.StartedAt = running.StartedAt
+	} else {
+		r.logger.Info("running hook",
+			"module namespaced name",
+			module.Name,
+			"hook",
+			fmt.Sprintf("%v/%v", obj.GetKind(), obj.GetName()),
+			"event",
+			event,
+		)
+
+		if h.hasDeletePolicy(release.HookBeforeHookCreation) {
+			deleted, err := r.deleteHook(kClient, gvr, obj, module.Spec.TargetNamespace)
+			if err != nil {
+				return completeHook(status, cyclopsv1alpha1.HookPhaseFailed, err.Error())
+			}
+
+			if !deleted {
+				status.Message = "waiting for the hook of the previous run to be deleted"
+				return status
+			}
+		}
+
+		if err := kClient.CreateDynamic(gvr, obj, module.Spec.TargetNamespace, isForceConflictsEnabled(module)); err != nil {
+			return completeHook(status, cyclopsv1alpha1.HookPhaseFailed, err.Error())
+		}
+
+		now := metav1.Now()
+		status.StartedAt = &now
+	}
+
+	phase, message := hookPhase(kClient, gvr, obj, module.Spec.TargetNamespace)
+	if phase == cyclopsv1alpha1.HookPhaseRunning {
+		if time.Since(status.StartedAt.Time) > hookTimeout {
+			phase, message = cyclopsv1alpha1.HookPhaseFailed, fmt.Sprintf("hook did not complete within %v", hookTimeout)
+		} else {
+			status.Message = message
+			return status
+		}
+	}
+
+	if (phase == cyclopsv1alpha1.HookPhaseSucceeded && h.hasDeletePolicy(release.HookSucceeded)) ||
+		(phase == cyclopsv1alpha1.HookPhaseFailed && h.hasDeletePolicy(release.HookFailed)) {
+		if err := kClient.DeleteDynamic(gvr, obj, module.Spec.TargetNamespace); err != nil {
+			r.logger.Error(err, "failed to delete hook",
+				"module namespaced name",
+				module.Name,
+				"hook",
+				fmt.Sprintf("%v/%v", obj.GetKind(), obj.GetName()),
+			)
+		}
+	}
+
+	return completeHook(status, phase, message)
+}
+
+// hookPhase checks if job and pod hooks completed. Hooks of other kinds succeed as soon as they are applied.
+func hookPhase(
+	kClient k8sclient.IKubernetesClient,
+	gvr cyclopsv1alpha1.GroupVersionResource,
+	obj *unstructured.Unstructured,
+	targetNamespace string,
+) (cyclopsv1alpha1.HookPhase, string) {
+	isJob := obj.GroupVersionKind().Group == "batch" && obj.GetKind() == "Job"
+	isPod := obj.GroupVersionKind().Group == "" && obj.GetKind() == "Pod"
+	if !isJob && !isPod {
+		return cyclopsv1alpha1.HookPhaseSucceeded, ""
+	}
+
+	// errors are retried until the hook times out
+	health, healthMessage, err := kClient.GetResourceHealth(gvr, obj, targetNamespace)
+	if err != nil {
+		return cyclopsv1alpha1.HookPhaseRunning, fmt.Sprintf("checking hook status: %v", err.Error())
+	}
+
+	switch health {
+	case k8sclient.HealthHealthy:
+		return cyclopsv1alpha1.HookPhaseSucceeded, ""
+	case k8sclient.HealthUnhealthy:
+		return cyclopsv1alpha1.HookPhaseFailed, healthMessage
+	}
+
+	return cyclopsv1alpha1.HookPhaseRunning, ""
+}
+
+// deleteHook deletes a hook created by a previous run and reports whether it is gone
+func (r *ModuleReconciler) deleteHook(
+	kClient k8sclient.IKubernetesClient,
+	gvr cyclopsv1alpha1.GroupVersionResource,
+	obj *unstructured.Unstructured,
+	targetNamespace string,
+) (bool, error) {
+	if err := kClient.DeleteDynamic(gvr, obj, targetNamespace); err != nil {
+		return false, err
+	}
+
+	_, err := kClient.GetDynamic(gvr.Group, gvr.Version, obj.GetKind(), obj.GetName(), obj.GetNamespace())
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+
+	return false, err
+}
+
+func completeHook(status cyclopsv1alpha1.HookStatus, phase cyclopsv1alpha1.HookPhase, message string) cyclopsv1alpha1.HookStatus {
+	now := metav1.Now()
+
+	status.Phase = phase
+	status.Message = message
+	status.CompletedAt = &now
+
+	return status
+}
+
+// recordedHook returns the status of the last run of the hook for the manifest
+func recordedHook(module cyclopsv1alpha1.Module, h hook, event release.HookEvent, manifestHash string) *cyclopsv1alpha1.HookStatus {
+	for _, hookStatus := range module.Status.Hooks {
+		if hookStatus.Kind == h.obj.GetKind() &&
+			hookStatus.Name == h.obj.GetName() &&
+			hookStatus.Event == string(event) &&
+			hookStatus.ManifestHash == manifestHash {
+			return &hookStatus
+		}
+	}
+
+	return nil
+}
+
+// setPendingHookStatus sets the status of a module waiting for a hook to complete. The module is requeued to
+// check the hook again.
+func (r *ModuleReconciler) setPendingHookStatus(
+	ctx context.Context,
+	module cyclopsv1alpha1.Module,
+	namespacedName types.NamespacedName,
+	template *models.Template,
+	childrenResources []cyclopsv1alpha1.GroupVersionResource,
+	pending *pendingHook,
+) (ctrl.Result, error) {
+	reason := fmt.Sprintf("waiting for %v hook %v/%v to complete", pending.event, pending.obj.GetKind(), pending.obj.GetName())
+
+	var hookErrors []string
+	if len(pending.message) != 0 {
+		hookErrors = []string{pending.message}
+	}
+
+	r.logger.Info("hook running",
+		"module namespaced name",
+		module.Name,
+		"hook",
+		fmt.Sprintf("%v/%v", pending.obj.GetKind(), pending.obj.GetName()),
+		"event",
+		pending.event,
+	)
+
+	return ctrl.Result{RequeueAfter: hookRequeueAfter}, r.setStatus(
+		ctx,
+		module,
+		namespacedName,
+		cyclopsv1alpha1.Progressing,
+		template.ResolvedVersion,
+		reason,
+		hookErrors,
+		childrenResources,
+		template.IconURL,
+		nil,
+		"",
+		moduleConditions(module.Generation, cyclopsv1alpha1.ConditionApplied, cyclopsv1alpha1.ReasonProgressing, reason),
+	)
+}
+
+func setHookStatus(module *cyclopsv1alpha1.Module, status cyclopsv1alpha1.HookStatus) {
+	for i, hookStatus := range module.Status.Hooks {
+		if hookStatus.Kind == status.Kind && hookStatus.Name == status.Name && hookStatus.Event == status.Event {
+			module.Status.Hooks[i] = status
+			return
+		}
+	}
+
+	module.Status.Hooks = append(module.Status.Hooks, status)
+}
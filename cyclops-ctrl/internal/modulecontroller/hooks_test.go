@@ -0,0 +1,246 @@
+package modulecontroller
+
+import (
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"helm.sh/helm/v3/pkg/release"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+	k8smocks "github.com/andersan81/cyclops/cyclops-ctrl/pkg/mocks"
+)
+
+var _ = Describe("Module hooks", func() {
+	object := func(apiVersion, kind, name string, annotations map[string]string) unstructured.Unstructured {
+		obj := unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName(name)
+		obj.SetAnnotations(annotations)
+		return obj
+	}
+
+	hookObject := func(apiVersion, kind, name, events, weight, deletePolicy string) unstructured.Unstructured {
+		annotations := map[string]string{release.HookAnnotation: events}
+		if len(weight) != 0 {
+			annotations[release.HookWeightAnnotation] = weight
+		}
+		if len(deletePolicy) != 0 {
+			annotations[release.HookDeleteAnnotation] = deletePolicy
+		}
+
+		return object(apiVersion, kind, name, annotations)
+	}
+
+	hookNames := func(hooks []hook) []string {
+		names := make([]string, 0, len(hooks))
+		for _, h := range hooks {
+			names = append(names, h.obj.GetName())
+		}
+		return names
+	}
+
+	Describe("splitHooks", func() {
+		It("separates hooks from resources and orders them by weight and name", func() {
+			resources, hooks := splitHooks([]unstructured.Unstructured{
+				object("apps/v1", "Deployment", "api", nil),
+				hookObject("batch/v1", "Job", "migrate", "pre-install, pre-upgrade", "5", ""),
+				hookObject("v1", "ConfigMap", "b-config", "pre-install", "-1", ""),
+				hookObject("v1", "Secret", "a-secret", "pre-install", "-1", ""),
+				hookObject("batch/v1", "Job", "seed", "post-install", "invalid", "hook-succeeded,hook-failed"),
+			})
+
+			Expect(resources).To(HaveLen(1))
+			Expect(resources[0].GetName()).To(Equal("api"))
+
+			Expect(hookNames(hooks)).To(Equal([]string{"a-secret", "b-config", "seed", "migrate"}))
+			Expect(hooks[2].weight).To(Equal(0))
+			Expect(hooks[2].deletePolicies).To(Equal([]string{"hook-succeeded", "hook-failed"}))
+			Expect(hooks[3].events).To(Equal([]string{"pre-install", "pre-upgrade"}))
+			Expect(hooks[3].hasEvent(release.HookPreUpgrade)).To(BeTrue())
+			Expect(hooks[3].hasEvent(release.HookPostInstall)).To(BeFalse())
+		})
+	})
+
+	Describe("hasDeletePolicy", func() {
+		type testCase struct {
+			description  string
+			deletePolicy string
+			policy       release.HookDeletePolicy
+			out          bool
+		}
+
+		testCases := []testCase{
+			{
+				description: "deletes hooks before creating them again without a policy",
+				policy:      release.HookBeforeHookCreation,
+				out:         true,
+			},
+			{
+				description: "keeps succeeded hooks without a policy",
+				policy:      release.HookSucceeded,
+				out:         false,
+			},
+			{
+				description:  "deletes succeeded hooks with the hook-succeeded policy",
+				deletePolicy: "hook-succeeded",
+				policy:       release.HookSucceeded,
+				out:          true,
+			},
+			{
+				description:  "doesn't delete hooks before creation if another policy is set",
+				deletePolicy: "hook-succeeded, hook-failed",
+				policy:       release.HookBeforeHookCreation,
+				out:          false,
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				_, hooks := splitHooks([]unstructured.Unstructured{
+					hookObject("batch/v1", "Job", "migrate", "pre-install", "", t.deletePolicy),
+				})
+				Expect(hooks[0].hasDeletePolicy(t.policy)).To(Equal(t.out))
+			})
+		}
+	})
+
+	Describe("runHooks", func() {
+		var (
+			reconciler *ModuleReconciler
+			kClient    *k8smocks.IKubernetesClient
+		)
+
+		configMapGVR := cyclopsv1alpha1.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+		jobGVR := cyclopsv1alpha1.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+
+		BeforeEach(func() {
+			kClient = &k8smocks.IKubernetesClient{}
+			reconciler = &ModuleReconciler{logger: logr.Discard()}
+		})
+
+		module := func(hooks ...cyclopsv1alpha1.HookStatus) *cyclopsv1alpha1.Module {
+			return &cyclopsv1alpha1.Module{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "team-a"},
+				Spec:       cyclopsv1alpha1.ModuleSpec{TargetNamespace: "apps"},
+				Status:     cyclopsv1alpha1.ModuleStatus{Hooks: hooks},
+			}
+		}
+
+		It("doesn't run hooks that already succeeded for the manifest", func() {
+			_, hooks := splitHooks([]unstructured.Unstructured{
+				hookObject("batch/v1", "Job", "migrate", "pre-upgrade", "", ""),
+			})
+
+			m := module(cyclopsv1alpha1.HookStatus{
+				Kind:         "Job",
+				Name:         "migrate",
+				Event:        string(release.HookPreUpgrade),
+				Phase:        cyclopsv1alpha1.HookPhaseSucceeded,
+				ManifestHash: "current",
+			})
+
+			hookErrors, pending := reconciler.runHooks(kClient, m, hooks, release.HookPreUpgrade, "current")
+			Expect(hookErrors).To(BeEmpty())
+			Expect(pending).To(BeNil())
+			kClient.AssertNotCalled(GinkgoT(), "CreateDynamic", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("keeps reporting a hook that failed for the manifest without running it again", func() {
+			_, hooks := splitHooks([]unstructured.Unstructured{
+				hookObject("batch/v1", "Job", "migrate", "pre-upgrade", "", ""),
+				hookObject("v1", "ConfigMap", "settings", "pre-upgrade", "1", ""),
+			})
+
+			m := module(cyclopsv1alpha1.HookStatus{
+				Kind:         "Job",
+				Name:         "migrate",
+				Event:        string(release.HookPreUpgrade),
+				Phase:        cyclopsv1alpha1.HookPhaseFailed,
+				Message:      "BackoffLimitExceeded",
+				ManifestHash: "current",
+			})
+
+			hookErrors, pending := reconciler.runHooks(kClient, m, hooks, release.HookPreUpgrade, "current")
+			Expect(pending).To(BeNil())
+			Expect(hookErrors).To(HaveLen(1))
+			Expect(hookErrors[0]).To(ContainSubstring("migrate pre-upgrade hook failed: BackoffLimitExceeded"))
+			Expect(m.Status.Hooks).To(HaveLen(1))
+			kClient.AssertNotCalled(GinkgoT(), "CreateDynamic", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("runs a failed hook again when the manifest changes", func() {
+			_, hooks := splitHooks([]unstructured.Unstructured{
+				hookObject("v1", "ConfigMap", "settings", "pre-upgrade", "", ""),
+			})
+
+			m := module(cyclopsv1alpha1.HookStatus{
+				Kind:         "ConfigMap",
+				Name:         "settings",
+				Event:        string(release.HookPreUpgrade),
+				Phase:        cyclopsv1alpha1.HookPhaseFailed,
+				ManifestHash: "previous",
+			})
+
+			kClient.On("GVKtoAPIResourceName", schema.GroupVersion{Version: "v1"}, "ConfigMap").Return("configmaps", nil)
+			kClient.On("DeleteDynamic", configMapGVR, mock.Anything, "apps").Return(nil)
+			kClient.On("GetDynamic", "", "v1", "ConfigMap", "settings", "").
+				Return(nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "settings"))
+			kClient.On("CreateDynamic", configMapGVR, mock.Anything, "apps", false).Return(nil)
+
+			hookErrors, pending := reconciler.runHooks(kClient, m, hooks, release.HookPreUpgrade, "current")
+			Expect(hookErrors).To(BeEmpty())
+			Expect(pending).To(BeNil())
+
+			Expect(m.Status.Hooks).To(HaveLen(1))
+			Expect(m.Status.Hooks[0].Phase).To(Equal(cyclopsv1alpha1.HookPhaseSucceeded))
+			Expect(m.Status.Hooks[0].ManifestHash).To(Equal("current"))
+			kClient.AssertNumberOfCalls(GinkgoT(), "CreateDynamic", 1)
+		})
+
+		It("deletes hooks with the hook-succeeded policy once they succeed", func() {
+			_, hooks := splitHooks([]unstructured.Unstructured{
+				hookObject("v1", "ConfigMap", "settings", "pre-install", "", "hook-succeeded"),
+			})
+
+			kClient.On("GVKtoAPIResourceName", schema.GroupVersion{Version: "v1"}, "ConfigMap").Return("configmaps", nil)
+			kClient.On("CreateDynamic", configMapGVR, mock.Anything, "apps", false).Return(nil)
+			kClient.On("DeleteDynamic", configMapGVR, mock.Anything, "apps").Return(nil)
+
+			hookErrors, pending := reconciler.runHooks(kClient, module(), hooks, release.HookPreInstall, "current")
+			Expect(hookErrors).To(BeEmpty())
+			Expect(pending).To(BeNil())
+
+			kClient.AssertNumberOfCalls(GinkgoT(), "DeleteDynamic", 1)
+			kClient.AssertNotCalled(GinkgoT(), "GetDynamic", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("waits for a running hook before running hooks with a higher weight", func() {
+			_, hooks := splitHooks([]unstructured.Unstructured{
+				hookObject("v1", "ConfigMap", "settings", "pre-install", "10", "hook-succeeded"),
+				hookObject("batch/v1", "Job", "migrate", "pre-install", "-5", "hook-succeeded"),
+			})
+
+			kClient.On("GVKtoAPIResourceName", schema.GroupVersion{Group: "batch", Version: "v1"}, "Job").Return("jobs", nil)
+			kClient.On("CreateDynamic", jobGVR, mock.Anything, "apps", false).Return(nil)
+			kClient.On("GetResourceHealth", jobGVR, mock.Anything, "apps").Return(k8sclient.HealthProgressing, "", nil)
+
+			m := module()
+			hookErrors, pending := reconciler.runHooks(kClient, m, hooks, release.HookPreInstall, "current")
+			Expect(hookErrors).To(BeEmpty())
+			Expect(pending).ToNot(BeNil())
+			Expect(pending.obj.GetName()).To(Equal("migrate"))
+
+			Expect(m.Status.Hooks).To(HaveLen(1))
+			Expect(m.Status.Hooks[0].Phase).To(Equal(cyclopsv1alpha1.HookPhaseRunning))
+			Expect(m.Status.Hooks[0].StartedAt).ToNot(BeNil())
+			kClient.AssertNumberOfCalls(GinkgoT(), "CreateDynamic", 1)
+		})
+	})
+})
@@ -18,6 +18,8 @@ package modulecontroller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"sort"
 	"strings"
@@ -28,24 +30,40 @@ import (
 
 	"github.com/go-logr/logr"
 	"helm.sh/helm/v3/pkg/chart"
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/dto"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/prometheus"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/telemetry"
 	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
 )
 
+const (
+	defaultProgressDeadline = 10 * time.Minute
+	progressingRequeueAfter = 10 * time.Second
+	degradedRequeueAfter    = time.Minute
+)
+
 // ModuleReconciler reconciles a Module object
 type ModuleReconciler struct {
 	client.Client
@@ -54,6 +72,8 @@ type ModuleReconciler struct {
 	templatesRepo    templaterepo.ITemplateRepo
 	kubernetesClient k8sclient.IKubernetesClient
 	renderer         *render.Renderer
+	resourceWatcher  *resourceWatcher
+	recorder         record.EventRecorder
 
 	maxConcurrentReconciles int
 
@@ -71,6 +91,7 @@ func NewModuleReconciler(
 	maxConcurrentReconciles int,
 	telemetryClient telemetry.Client,
 	monitor prometheus.Monitor,
+	recorder record.EventRecorder,
 ) *ModuleReconciler {
 	return &ModuleReconciler{
 		Client:                  client,
@@ -78,9 +99,11 @@ func NewModuleReconciler(
 		templatesRepo:           templatesRepo,
 		kubernetesClient:        kubernetesClient,
 		renderer:                renderer,
+		resourceWatcher:         newResourceWatcher(kubernetesClient, ctrl.Log.WithName("resource watcher")),
 		telemetryClient:         telemetryClient,
 		maxConcurrentReconciles: maxConcurrentReconciles,
 		monitor:                 monitor,
+		recorder:                recorder,
 		logger:                  ctrl.Log.WithName("reconciler"),
 	}
 }
@@ -88,6 +111,10 @@ func NewModuleReconciler(
 //+kubebuilder:rbac:groups=cyclops-ui.com,resources=modules,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=cyclops-ui.com,resources=modules/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=cyclops-ui.com,resources=modules/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=cyclops-ui.com,resources=clusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cyclops-ui.com,resources=modulerevisions,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=secrets;configmaps,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -107,23 +134,42 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	if err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			r.logger.Error(err, "error on get module resources", "namespaced name", req.NamespacedName)
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+
+		r.resourceWatcher.forgetModule(req.NamespacedName)
+		return ctrl.Result{}, nil
 	}
 
 	if module.GetDeletionTimestamp() != nil {
-		if controllerutil.ContainsFinalizer(&module, cyclopsv1alpha1.ResourceFinalizer) {
-			if err := r.finalizeModule(module); err != nil {
-				return ctrl.Result{}, err
-			}
+		return r.deleteModule(ctx, req, module)
+	}
 
-			controllerutil.RemoveFinalizer(&module, cyclopsv1alpha1.ResourceFinalizer)
-			err := r.Update(ctx, &module)
-			if err != nil {
-				return ctrl.Result{}, err
-			}
+	kubernetesClient, err := r.kubernetesClient.ForCluster(module.Spec.Cluster)
+	if err != nil {
+		r.logger.Error(err, "error getting module cluster client", "namespaced name", req.NamespacedName, "cluster", module.Spec.Cluster)
+		r.recorder.Event(&module, apiv1.EventTypeWarning, eventReasonClusterUnavailable, err.Error())
+
+		if err := r.setStatus(
+			ctx,
+			module,
+			req.NamespacedName,
+			cyclopsv1alpha1.Failed,
+			module.Status.TemplateResolvedVersion,
+			err.Error(),
+			nil,
+			nil,
+			module.Status.IconURL,
+			nil,
+			module.Status.AppliedManifestHash,
+			moduleConditions(module.Generation, cyclopsv1alpha1.ConditionReady, cyclopsv1alpha1.ReasonClusterUnavailable, err.Error()),
+		); err != nil {
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, nil
+
+		r.monitor.OnFailedReconciliation()
+
+		return ctrl.Result{}, err
 	}
 
 	if !controllerutil.ContainsFinalizer(&module, cyclopsv1alpha1.ResourceFinalizer) {
@@ -140,6 +186,73 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 
 	r.logger.Info("upsert module", "namespaced name", req.NamespacedName)
 
+	dependencyCycle, err := r.dependencyCycle(ctx, module)
+	if err != nil {
+		r.logger.Error(err, "error checking module dependencies", "namespaced name", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	// modules in a cycle would wait for each other forever, the cycle is reported until dependsOn is changed
+	if len(dependencyCycle) != 0 {
+		reason := fmt.Sprintf("dependency cycle: %v", strings.Join(dependencyCycle, " -> "))
+
+		r.logger.Info("module dependency cycle",
+			"module namespaced name",
+			module.Name,
+			"cycle",
+			dependencyCycle,
+		)
+
+		r.monitor.OnFailedReconciliation()
+
+		return ctrl.Result{}, r.setStatus(
+			ctx,
+			module,
+			req.NamespacedName,
+			cyclopsv1alpha1.Failed,
+			module.Status.TemplateResolvedVersion,
+			reason,
+			nil,
+			nil,
+			module.Status.IconURL,
+			nil,
+			module.Status.AppliedManifestHash,
+			moduleConditions(module.Generation, cyclopsv1alpha1.ConditionDependenciesReady, cyclopsv1alpha1.ReasonDependencyCycle, reason),
+		)
+	}
+
+	notReadyDependencies, err := r.notReadyDependencies(ctx, module)
+	if err != nil {
+		r.logger.Error(err, "error checking module dependencies", "namespaced name", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	if len(notReadyDependencies) != 0 {
+		reason := fmt.Sprintf("waiting for dependencies to become ready: %v", strings.Join(notReadyDependencies, ", "))
+
+		r.logger.Info("module dependencies not ready",
+			"module namespaced name",
+			module.Name,
+			"dependencies",
+			notReadyDependencies,
+		)
+
+		return ctrl.Result{}, r.setStatus(
+			ctx,
+			module,
+			req.NamespacedName,
+			cyclopsv1alpha1.Progressing,
+			module.Status.TemplateResolvedVersion,
+			reason,
+			nil,
+			nil,
+			module.Status.IconURL,
+			nil,
+			module.Status.AppliedManifestHash,
+			moduleConditions(module.Generation, cyclopsv1alpha1.ConditionDependenciesReady, cyclopsv1alpha1.ReasonDependenciesNotReady, reason),
+		)
+	}
+
 	templateVersion := module.Status.TemplateResolvedVersion
 	if len(templateVersion) == 0 {
 		templateVersion = module.Spec.TemplateRef.Version
@@ -154,8 +267,22 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	)
 	if err != nil {
 		r.logger.Error(err, "error fetching module template", "namespaced name", req.NamespacedName)
+		r.recorder.Event(&module, apiv1.EventTypeWarning, eventReasonTemplateFetchFailed, err.Error())
 
-		if err = r.setStatus(ctx, module, req.NamespacedName, cyclopsv1alpha1.Failed, templateVersion, err.Error(), nil, nil, ""); err != nil {
+		if err = r.setStatus(
+			ctx,
+			module,
+			req.NamespacedName,
+			cyclopsv1alpha1.Failed,
+			templateVersion,
+			err.Error(),
+			nil,
+			nil,
+			"",
+			nil,
+			"",
+			moduleConditions(module.Generation, cyclopsv1alpha1.ConditionTemplateFetched, cyclopsv1alpha1.ReasonTemplateFetchFailed, err.Error()),
+		); err != nil {
 			return ctrl.Result{}, err
 		}
 
@@ -164,11 +291,35 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
-	installErrors, childrenResources, err := r.moduleToResources(template, &module)
+	manifest, err := r.renderer.HelmTemplate(module, template)
 	if err != nil {
 		r.logger.Error(err, "error on upsert module", "namespaced name", req.NamespacedName)
 
-		if err = r.setStatus(ctx, module, req.NamespacedName, cyclopsv1alpha1.Failed, template.ResolvedVersion, err.Error(), nil, nil, template.IconURL); err != nil {
+		reason, eventReason := cyclopsv1alpha1.ReasonRenderFailed, eventReasonRenderFailed
+		var installErrors []string
+		if validationErr, ok := err.(*render.ValuesValidationError); ok {
+			reason, eventReason = cyclopsv1alpha1.ReasonInvalidValues, eventReasonInvalidValues
+			for _, field := range validationErr.Fields {
+				installErrors = append(installErrors, fmt.Sprintf("%v: %v", field.Field, field.Message))
+			}
+		}
+
+		r.recorder.Event(&module, apiv1.EventTypeWarning, eventReason, err.Error())
+
+		if err = r.setStatus(
+			ctx,
+			module,
+			req.NamespacedName,
+			cyclopsv1alpha1.Failed,
+			template.ResolvedVersion,
+			err.Error(),
+			installErrors,
+			nil,
+			template.IconURL,
+			nil,
+			"",
+			moduleConditions(module.Generation, cyclopsv1alpha1.ConditionRendered, reason, err.Error()),
+		); err != nil {
 			return ctrl.Result{}, err
 		}
 
@@ -177,6 +328,50 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
+	manifestHash := hashManifest(module, manifest)
+
+	// resources are not applied again if the manifest did not change since the last successful apply, unless
+	// they drifted from it and the module is allowed to revert the drift
+	if manifestHash == module.Status.AppliedManifestHash {
+		driftedResources, err := r.detectDrift(kubernetesClient, module, manifest)
+		if err != nil {
+			r.logger.Error(err, "error detecting drift of module resources", "namespaced name", req.NamespacedName)
+		} else if len(driftedResources) == 0 {
+			return r.setHealthStatus(ctx, kubernetesClient, module, req.NamespacedName, template, manifest, manifestHash, nil)
+		} else if !isSelfHealEnabled(module) || !hasDriftedFields(driftedResources) {
+			r.logger.Info("module resources drifted",
+				"module namespaced name",
+				module.Name,
+				"number of drifted resources",
+				len(driftedResources),
+			)
+
+			return ctrl.Result{}, r.setStatus(
+				ctx,
+				module,
+				req.NamespacedName,
+				cyclopsv1alpha1.Drifted,
+				template.ResolvedVersion,
+				"module resources drifted from the template",
+				driftErrors(driftedResources),
+				nil,
+				template.IconURL,
+				driftedResources,
+				manifestHash,
+				moduleConditions(module.Generation, cyclopsv1alpha1.ConditionApplied, cyclopsv1alpha1.ReasonDrifted, "module resources drifted from the template"),
+			)
+		} else {
+			r.logger.Info("reverting drift of module resources",
+				"module namespaced name",
+				module.Name,
+				"drifted resources",
+				driftErrors(driftedResources),
+			)
+		}
+	}
+
+	installErrors, childrenResources, pendingWave, pendingHook := r.moduleToResources(ctx, kubernetesClient, template, &module, manifest, manifestHash)
+
 	if len(installErrors) != 0 {
 		r.monitor.OnFailedReconciliation()
 
@@ -199,20 +394,21 @@ func (r *ModuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			installErrors,
 			childrenResources,
 			template.IconURL,
+			nil,
+			"",
+			moduleConditions(module.Generation, cyclopsv1alpha1.ConditionApplied, cyclopsv1alpha1.ReasonApplyFailed, "error decoding/applying resources"),
 		)
 	}
 
-	return ctrl.Result{}, r.setStatus(
-		ctx,
-		module,
-		req.NamespacedName,
-		cyclopsv1alpha1.Succeeded,
-		template.ResolvedVersion,
-		"",
-		nil,
-		childrenResources,
-		template.IconURL,
-	)
+	if pendingHook != nil {
+		return r.setPendingHookStatus(ctx, module, req.NamespacedName, template, childrenResources, pendingHook)
+	}
+
+	if pendingWave != nil {
+		return r.setSyncWaveStatus(ctx, module, req.NamespacedName, template, childrenResources, manifestHash, pendingWave)
+	}
+
+	return r.setHealthStatus(ctx, kubernetesClient, module, req.NamespacedName, template, manifest, manifestHash, childrenResources)
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -221,8 +417,36 @@ func (r *ModuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		workqueue.NewItemExponentialFailureRateLimiter(1*time.Second, 64*time.Second),
 	)
 
+	if err := mgr.Add(r.resourceWatcher); err != nil {
+		return err
+	}
+
+	if err := mgr.Add(newTemplateUpdateChecker(
+		r.Client,
+		r.templatesRepo,
+		r.recorder,
+		ctrl.Log.WithName("template update checker"),
+	)); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&cyclopsv1alpha1.Module{}).
+		Watches(&cyclopsv1alpha1.Module{}, handler.EnqueueRequestsFromMapFunc(r.dependentModules)).
+		Watches(
+			&apiv1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.modulesForValuesReference(cyclopsv1alpha1.ValuesReferenceKindConfigMap)),
+			builder.OnlyMetadata,
+		).
+		Watches(
+			&apiv1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.modulesForValuesReference(cyclopsv1alpha1.ValuesReferenceKindSecret)),
+			builder.OnlyMetadata,
+		).
+		WatchesRawSource(source.Channel(
+			r.resourceWatcher.events,
+			handler.EnqueueRequestsFromMapFunc(r.moduleForResource),
+		)).
 		WithOptions(controller.Options{
 			RateLimiter:             rateLimiter,
 			MaxConcurrentReconciles: r.maxConcurrentReconciles,
@@ -230,31 +454,115 @@ func (r *ModuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-func (r *ModuleReconciler) moduleToResources(template *models.Template, module *cyclopsv1alpha1.Module) ([]string, []cyclopsv1alpha1.GroupVersionResource, error) {
-	crdInstallErrors := r.applyCRDs(template)
+// moduleForResource maps a resource created from a module template to the module it belongs to
+func (r *ModuleReconciler) moduleForResource(ctx context.Context, obj client.Object) []reconcile.Request {
+	moduleName := obj.GetLabels()["cyclops.module"]
+	if len(moduleName) == 0 {
+		return nil
+	}
 
-	installErrors, childrenGVRs, err := r.generateResources(r.kubernetesClient, *module, template)
-	if err != nil {
-		return nil, nil, err
+	var modules cyclopsv1alpha1.ModuleList
+	if err := r.List(ctx, &modules); err != nil {
+		r.logger.Error(err, "error listing modules for resource", "resource name", obj.GetName())
+		return nil
 	}
 
-	return append(crdInstallErrors, installErrors...), childrenGVRs, nil
+	// resources labeled before modules could live in multiple namespaces have no module namespace label
+	moduleNamespace, hasNamespace := obj.GetLabels()[k8sclient.ModuleNamespaceLabel]
+
+	requests := make([]reconcile.Request, 0)
+	for _, module := range modules.Items {
+		if module.Name == moduleName && (!hasNamespace || module.Namespace == moduleNamespace) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      module.Name,
+					Namespace: module.Namespace,
+				},
+			})
+		}
+	}
+
+	return requests
 }
 
-func (r *ModuleReconciler) generateResources(
+func (r *ModuleReconciler) moduleToResources(
+	ctx context.Context,
 	kClient k8sclient.IKubernetesClient,
-	module cyclopsv1alpha1.Module,
-	moduleTemplate *models.Template,
-) ([]string, []cyclopsv1alpha1.GroupVersionResource, error) {
-	out, err := r.renderer.HelmTemplate(module, moduleTemplate)
-	if err != nil {
-		return nil, nil, err
+	template *models.Template,
+	module *cyclopsv1alpha1.Module,
+	manifest string,
+	manifestHash string,
+) ([]string, []cyclopsv1alpha1.GroupVersionResource, *pendingSyncWave, *pendingHook) {
+	crdInstallErrors := r.applyCRDs(kClient, template)
+
+	objects, installErrors := r.decodeResources(*module, manifest)
+	installErrors = append(crdInstallErrors, installErrors...)
+
+	objects, hooks := splitHooks(objects)
+
+	// hooks run only when the manifest changed, reconciliations of an applied manifest only revert drift
+	runHooks := manifestHash != module.Status.AppliedManifestHash
+	preHook, postHook := hookEvents(*module, manifestHash)
+
+	if runHooks && len(installErrors) == 0 {
+		hookErrors, pending := r.runHooks(kClient, module, hooks, preHook, manifestHash)
+		if len(hookErrors) != 0 || pending != nil {
+			return append(installErrors, hookErrors...), nil, nil, pending
+		}
 	}
 
-	installErrors := make([]string, 0)
+	waves, waveErrors := syncWaves(objects)
+	installErrors = append(installErrors, waveErrors...)
+
 	childrenGVRs := make([]cyclopsv1alpha1.GroupVersionResource, 0)
+	for i, wave := range waves {
+		applyErrors, waveGVRs := r.generateResources(kClient, *module, wave.objects)
+		installErrors = append(installErrors, applyErrors...)
+		childrenGVRs = append(childrenGVRs, waveGVRs...)
+
+		// resources of the next wave are applied only once all resources of this wave were applied and are healthy
+		if len(applyErrors) != 0 {
+			return installErrors, childrenGVRs, nil, nil
+		}
+
+		if i == len(waves)-1 {
+			break
+		}
+
+		health, healthErrors := r.objectsHealth(kClient, wave.objects, module.Spec.TargetNamespace)
+		if health != k8sclient.HealthHealthy {
+			return installErrors, childrenGVRs, &pendingSyncWave{
+				wave:         wave.wave,
+				health:       health,
+				healthErrors: healthErrors,
+			}, nil
+		}
+	}
+
+	if runHooks && len(installErrors) == 0 {
+		hookErrors, pending := r.runHooks(kClient, module, hooks, postHook, manifestHash)
+		if pending != nil {
+			return installErrors, childrenGVRs, nil, pending
+		}
+
+		installErrors = append(installErrors, hookErrors...)
+	}
+
+	// pruning is skipped if anything failed to apply so a resource that failed to decode
+	// or apply is never mistaken for one that was removed from the template
+	if len(installErrors) == 0 && isPruneEnabled(*module) {
+		installErrors = append(installErrors, r.pruneResources(kClient, *module, manifest)...)
+	}
+
+	return installErrors, childrenGVRs, nil, nil
+}
 
-	for _, s := range strings.Split(out, "\n---\n") {
+// decodeResources decodes all objects of a rendered manifest and labels them as part of the module
+func (r *ModuleReconciler) decodeResources(module cyclopsv1alpha1.Module, manifest string) ([]unstructured.Unstructured, []string) {
+	installErrors := make([]string, 0)
+	objects := make([]unstructured.Unstructured, 0)
+
+	for _, s := range strings.Split(manifest, "\n---\n") {
 		s := strings.TrimSpace(s)
 		if len(s) == 0 {
 			continue
@@ -289,17 +597,28 @@ func (r *ModuleReconciler) generateResources(
 			continue
 		}
 
-		labels := obj.GetLabels()
-		if labels == nil {
-			labels = make(map[string]string)
-		}
+		k8sclient.SetModuleLabels(&obj, module.Namespace, module.Name)
+		objects = append(objects, obj)
+	}
 
-		labels["app.kubernetes.io/managed-by"] = "cyclops"
-		labels["cyclops.module"] = module.Name
-		obj.SetLabels(labels)
+	return objects, installErrors
+}
+
+func (r *ModuleReconciler) generateResources(
+	kClient k8sclient.IKubernetesClient,
+	module cyclopsv1alpha1.Module,
+	objects []unstructured.Unstructured,
+) ([]string, []cyclopsv1alpha1.GroupVersionResource) {
+	installErrors := make([]string, 0)
+	childrenGVRs := make([]cyclopsv1alpha1.GroupVersionResource, 0)
+
+	for i := range objects {
+		obj := &objects[i]
 
 		resourceName, err := kClient.GVKtoAPIResourceName(obj.GroupVersionKind().GroupVersion(), obj.GroupVersionKind().Kind)
 		if err != nil {
+			r.objectEvent(&module, apiv1.EventTypeWarning, eventReasonApplyFailed, obj, err.Error())
+
 			installErrors = append(installErrors, fmt.Sprintf(
 				"%v%v/%v %v/%v failed to apply: %v",
 				obj.GroupVersionKind().Group,
@@ -320,7 +639,32 @@ func (r *ModuleReconciler) generateResources(
 		}
 		childrenGVRs = append(childrenGVRs, gvr)
 
-		if err := kClient.CreateDynamic(gvr, &obj, module.Spec.TargetNamespace); err != nil {
+		if err := kClient.CreateDynamic(gvr, obj, module.Spec.TargetNamespace, isForceConflictsEnabled(module)); err != nil {
+			if conflicts := k8sclient.FieldManagerConflicts(err); len(conflicts) != 0 {
+				for _, conflict := range conflicts {
+					r.objectEvent(&module, apiv1.EventTypeWarning, eventReasonApplyFailed, obj, fmt.Sprintf(
+						"apply conflict on field %v: %v",
+						conflict.Field,
+						conflict.Message,
+					))
+
+					installErrors = append(installErrors, fmt.Sprintf(
+						"%v%v/%v %v/%v apply conflict on field %v: %v",
+						obj.GroupVersionKind().Group,
+						obj.GroupVersionKind().Version,
+						obj.GroupVersionKind().Kind,
+						obj.GetNamespace(),
+						obj.GetName(),
+						conflict.Field,
+						conflict.Message,
+					))
+				}
+
+				continue
+			}
+
+			r.objectEvent(&module, apiv1.EventTypeWarning, eventReasonApplyFailed, obj, err.Error())
+
 			installErrors = append(installErrors, fmt.Sprintf(
 				"%v%v/%v %v/%v failed to apply: %v",
 				obj.GroupVersionKind().Group,
@@ -335,24 +679,446 @@ func (r *ModuleReconciler) generateResources(
 		}
 	}
 
-	return installErrors, childrenGVRs, nil
+	return installErrors, childrenGVRs
+}
+
+// detectDrift dry-run applies all resources rendered from the module template and returns the ones
+// whose live state would be changed by the apply
+func (r *ModuleReconciler) detectDrift(kClient k8sclient.IKubernetesClient, module cyclopsv1alpha1.Module, manifest string) ([]cyclopsv1alpha1.DriftedResource, error) {
+	objects, err := manifestObjects(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	driftedResources := make([]cyclopsv1alpha1.DriftedResource, 0)
+	for _, obj := range objects {
+		k8sclient.SetModuleLabels(&obj, module.Namespace, module.Name)
+
+		resourceName, err := kClient.GVKtoAPIResourceName(obj.GroupVersionKind().GroupVersion(), obj.GroupVersionKind().Kind)
+		if err != nil {
+			return nil, err
+		}
+
+		live, applied, err := kClient.DryRunDynamic(
+			cyclopsv1alpha1.GroupVersionResource{
+				Group:    obj.GroupVersionKind().Group,
+				Version:  obj.GroupVersionKind().Version,
+				Resource: resourceName,
+			},
+			&obj,
+			module.Spec.TargetNamespace,
+			isForceConflictsEnabled(module),
+		)
+
+		driftedResource := cyclopsv1alpha1.DriftedResource{
+			Group:     obj.GroupVersionKind().Group,
+			Version:   obj.GroupVersionKind().Version,
+			Kind:      obj.GroupVersionKind().Kind,
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+		}
+
+		if conflicts := k8sclient.FieldManagerConflicts(err); len(conflicts) != 0 {
+			for _, conflict := range conflicts {
+				driftedResource.Conflicts = append(driftedResource.Conflicts, fmt.Sprintf("%v: %v", conflict.Field, conflict.Message))
+			}
+
+			driftedResources = append(driftedResources, driftedResource)
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if live == nil {
+			driftedResource.Missing = true
+			driftedResources = append(driftedResources, driftedResource)
+			continue
+		}
+
+		if fields := k8sclient.DriftedFields(live, applied); len(fields) != 0 {
+			driftedResource.Fields = fields
+			driftedResources = append(driftedResources, driftedResource)
+		}
+	}
+
+	return driftedResources, nil
+}
+
+// moduleHealth assesses the health of all resources rendered from the module template. It returns the
+// worst health of all resources and messages describing the resources that are not healthy.
+func (r *ModuleReconciler) moduleHealth(kClient k8sclient.IKubernetesClient, module cyclopsv1alpha1.Module, manifest string) (string, []string) {
+	objects, err := manifestObjects(manifest)
+	if err != nil {
+		return k8sclient.HealthProgressing, []string{err.Error()}
+	}
+
+	return r.objectsHealth(kClient, objects, module.Spec.TargetNamespace)
+}
+
+func (r *ModuleReconciler) objectsHealth(kClient k8sclient.IKubernetesClient, objects []unstructured.Unstructured, targetNamespace string) (string, []string) {
+	health := k8sclient.HealthHealthy
+	healthErrors := make([]string, 0)
+	for _, obj := range objects {
+		resourceHealth, message, err := r.resourceHealth(kClient, &obj, targetNamespace)
+		if err != nil {
+			resourceHealth, message = k8sclient.HealthProgressing, err.Error()
+		}
+
+		if resourceHealth == k8sclient.HealthHealthy {
+			continue
+		}
+
+		if health != k8sclient.HealthUnhealthy {
+			health = resourceHealth
+		}
+
+		healthErrors = append(healthErrors, fmt.Sprintf(
+			"%v%v/%v %v/%v is %v: %v",
+			obj.GroupVersionKind().Group,
+			obj.GroupVersionKind().Version,
+			obj.GroupVersionKind().Kind,
+			obj.GetNamespace(),
+			obj.GetName(),
+			resourceHealth,
+			message,
+		))
+	}
+
+	return health, healthErrors
+}
+
+func (r *ModuleReconciler) resourceHealth(kClient k8sclient.IKubernetesClient, obj *unstructured.Unstructured, targetNamespace string) (string, string, error) {
+	resourceName, err := kClient.GVKtoAPIResourceName(obj.GroupVersionKind().GroupVersion(), obj.GroupVersionKind().Kind)
+	if err != nil {
+		return "", "", err
+	}
+
+	return kClient.GetResourceHealth(
+		cyclopsv1alpha1.GroupVersionResource{
+			Group:    obj.GroupVersionKind().Group,
+			Version:  obj.GroupVersionKind().Version,
+			Resource: resourceName,
+		},
+		obj,
+		targetNamespace,
+	)
+}
+
+// setHealthStatus sets the status of a module whose resources were applied successfully based on the health of
+// the applied resources. The module is requeued until all of them are healthy, since changes of resource status
+// do not trigger a reconciliation.
+func (r *ModuleReconciler) setHealthStatus(
+	ctx context.Context,
+	kClient k8sclient.IKubernetesClient,
+	module cyclopsv1alpha1.Module,
+	namespacedName types.NamespacedName,
+	template *models.Template,
+	manifest string,
+	manifestHash string,
+	childrenResources []cyclopsv1alpha1.GroupVersionResource,
+) (ctrl.Result, error) {
+	if manifestHash != module.Status.AppliedManifestHash {
+		if err := r.recordRevision(ctx, module, template.ResolvedVersion, manifestHash); err != nil {
+			r.logger.Error(err, "error recording module revision", "namespaced name", namespacedName)
+		}
+	}
+
+	health, healthErrors := r.moduleHealth(kClient, module, manifest)
+
+	if health == k8sclient.HealthHealthy {
+		return ctrl.Result{}, r.setStatus(
+			ctx,
+			module,
+			namespacedName,
+			cyclopsv1alpha1.Succeeded,
+			template.ResolvedVersion,
+			"",
+			nil,
+			childrenResources,
+			template.IconURL,
+			nil,
+			manifestHash,
+			moduleConditions(module.Generation, "", "", ""),
+		)
+	}
+
+	status := cyclopsv1alpha1.Progressing
+	reason := "waiting for module resources to become healthy"
+	conditionReason := cyclopsv1alpha1.ReasonProgressing
+	requeueAfter := progressingRequeueAfter
+
+	if health == k8sclient.HealthUnhealthy || progressDeadlineExceeded(module, manifestHash) {
+		r.monitor.OnFailedReconciliation()
+
+		status = cyclopsv1alpha1.Degraded
+		reason = "module resources did not become healthy"
+		conditionReason = cyclopsv1alpha1.ReasonDegraded
+		requeueAfter = degradedRequeueAfter
+	}
+
+	r.logger.Info("module resources not healthy",
+		"module namespaced name",
+		module.Name,
+		"status",
+		status,
+		"health errors",
+		healthErrors,
+	)
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, r.setStatus(
+		ctx,
+		module,
+		namespacedName,
+		status,
+		template.ResolvedVersion,
+		reason,
+		healthErrors,
+		childrenResources,
+		template.IconURL,
+		nil,
+		manifestHash,
+		moduleConditions(module.Generation, cyclopsv1alpha1.ConditionReady, conditionReason, reason),
+	)
+}
+
+// progressDeadlineExceeded checks if the resources of the manifest identified by manifestHash had more than
+// the progress deadline of the module to become healthy
+func progressDeadlineExceeded(module cyclopsv1alpha1.Module, manifestHash string) bool {
+	if module.Status.AppliedManifestHash != manifestHash || module.Status.LastAppliedTime == nil {
+		return false
+	}
+
+	return time.Since(module.Status.LastAppliedTime.Time) > progressDeadline(module)
+}
+
+func progressDeadline(module cyclopsv1alpha1.Module) time.Duration {
+	if module.Spec.SyncPolicy != nil && module.Spec.SyncPolicy.ProgressDeadlineSeconds != nil {
+		return time.Duration(*module.Spec.SyncPolicy.ProgressDeadlineSeconds) * time.Second
+	}
+
+	return defaultProgressDeadline
+}
+
+// manifestObjects decodes all objects of a rendered manifest except Helm hooks
+func manifestObjects(manifest string) ([]unstructured.Unstructured, error) {
+	objects := make([]unstructured.Unstructured, 0)
+
+	for _, s := range strings.Split(manifest, "\n---\n") {
+		s := strings.TrimSpace(s)
+		if len(s) == 0 {
+			continue
+		}
+
+		var obj unstructured.Unstructured
+		decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(s), len(s))
+		if err := decoder.Decode(&obj); err != nil {
+			return nil, err
+		}
+
+		if len(obj.UnstructuredContent()) == 0 {
+			continue
+		}
+
+		objects = append(objects, obj)
+	}
+
+	objects, _ = splitHooks(objects)
+
+	return objects, nil
+}
+
+// hasDriftedFields returns true if any resource is missing or drifted on fields Cyclops owns. Resources with only
+// conflicts are not reverted by self-heal, since applying them would fail on the same conflicts.
+func hasDriftedFields(driftedResources []cyclopsv1alpha1.DriftedResource) bool {
+	for _, resource := range driftedResources {
+		if resource.Missing || len(resource.Fields) != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func driftErrors(driftedResources []cyclopsv1alpha1.DriftedResource) []string {
+	errors := make([]string, 0, len(driftedResources))
+	for _, resource := range driftedResources {
+		for _, conflict := range resource.Conflicts {
+			errors = append(errors, fmt.Sprintf(
+				"%v%v/%v %v/%v apply conflict on field %v",
+				resource.Group,
+				resource.Version,
+				resource.Kind,
+				resource.Namespace,
+				resource.Name,
+				conflict,
+			))
+		}
+
+		if len(resource.Conflicts) != 0 {
+			continue
+		}
+
+		if resource.Missing {
+			errors = append(errors, fmt.Sprintf(
+				"%v%v/%v %v/%v is missing",
+				resource.Group,
+				resource.Version,
+				resource.Kind,
+				resource.Namespace,
+				resource.Name,
+			))
+			continue
+		}
+
+		errors = append(errors, fmt.Sprintf(
+			"%v%v/%v %v/%v drifted on fields: %v",
+			resource.Group,
+			resource.Version,
+			resource.Kind,
+			resource.Namespace,
+			resource.Name,
+			strings.Join(resource.Fields, ", "),
+		))
+	}
+
+	return errors
+}
+
+// hashManifest identifies a rendered manifest applied for a specific module generation. Requesting a reconcile
+// through the reconciled-at annotation changes the hash as well, so all resources get applied again.
+func hashManifest(module cyclopsv1alpha1.Module, manifest string) string {
+	h := sha256.New()
+	h.Write([]byte(fmt.Sprintf("%v\n%v\n", module.Generation, module.GetAnnotations()[cyclopsv1alpha1.ReconciledAtAnnotation])))
+	h.Write([]byte(manifest))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (r *ModuleReconciler) pruneResources(kClient k8sclient.IKubernetesClient, module cyclopsv1alpha1.Module, manifest string) []string {
+	pruneErrors := make([]string, 0)
+
+	resources, err := kClient.GetResourcesForModule(module.Namespace, module.Name)
+	if err != nil {
+		return append(pruneErrors, fmt.Sprintf("failed to list module resources for pruning: %v", err.Error()))
+	}
+
+	resources, err = kClient.GetDeletedResources(resources, manifest, module.Spec.TargetNamespace)
+	if err != nil {
+		return append(pruneErrors, fmt.Sprintf("failed to compute resources for pruning: %v", err.Error()))
+	}
+
+	for _, resource := range resources {
+		if !resource.GetDeleted() {
+			continue
+		}
+
+		obj, err := kClient.GetDynamic(
+			resource.GetGroup(),
+			resource.GetVersion(),
+			resource.GetKind(),
+			resource.GetName(),
+			resource.GetNamespace(),
+		)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+
+			r.resourceEvent(&module, apiv1.EventTypeWarning, eventReasonPruneFailed, resource, err.Error())
+
+			pruneErrors = append(pruneErrors, fmt.Sprintf(
+				"%v/%v %v %v/%v failed to prune: %v",
+				resource.GetGroup(),
+				resource.GetVersion(),
+				resource.GetKind(),
+				resource.GetNamespace(),
+				resource.GetName(),
+				err.Error(),
+			))
+			continue
+		}
+
+		if !isPrunable(obj) {
+			continue
+		}
+
+		if err := kClient.Delete(resource); err != nil && !apierrors.IsNotFound(err) {
+			r.logger.Error(err, "failed to prune resource",
+				"module namespaced name",
+				module.Name,
+				"gvk",
+				resource.GetGroupVersionKind(),
+				"resource namespaced name",
+				fmt.Sprintf("%s/%s", resource.GetNamespace(), resource.GetName()),
+			)
+			r.resourceEvent(&module, apiv1.EventTypeWarning, eventReasonPruneFailed, resource, err.Error())
+
+			pruneErrors = append(pruneErrors, fmt.Sprintf(
+				"%v/%v %v %v/%v failed to prune: %v",
+				resource.GetGroup(),
+				resource.GetVersion(),
+				resource.GetKind(),
+				resource.GetNamespace(),
+				resource.GetName(),
+				err.Error(),
+			))
+			continue
+		}
+
+		r.logger.Info("pruned resource",
+			"module namespaced name",
+			module.Name,
+			"gvk",
+			resource.GetGroupVersionKind(),
+			"resource namespaced name",
+			fmt.Sprintf("%s/%s", resource.GetNamespace(), resource.GetName()),
+		)
+		r.resourceEvent(&module, apiv1.EventTypeNormal, eventReasonPruned, resource, "removed from the module template")
+	}
+
+	return pruneErrors
+}
+
+func isPruneEnabled(module cyclopsv1alpha1.Module) bool {
+	return module.Spec.SyncPolicy != nil && module.Spec.SyncPolicy.Prune
 }
 
-func (r *ModuleReconciler) applyCRDs(template *models.Template) []string {
+func isForceConflictsEnabled(module cyclopsv1alpha1.Module) bool {
+	return module.Spec.SyncPolicy != nil && module.Spec.SyncPolicy.ForceConflicts
+}
+
+func isSelfHealEnabled(module cyclopsv1alpha1.Module) bool {
+	return module.Spec.SyncPolicy != nil && module.Spec.SyncPolicy.SelfHeal
+}
+
+// isPrunable checks if a live resource can be deleted when it is no longer part of the module.
+// Resources owned by another object (like pods of a deployment) are left to their owner's garbage collection.
+func isPrunable(obj *unstructured.Unstructured) bool {
+	if len(obj.GetOwnerReferences()) != 0 {
+		return false
+	}
+
+	return obj.GetAnnotations()[cyclopsv1alpha1.PruneAnnotation] != "false" &&
+		obj.GetAnnotations()[cyclopsv1alpha1.KeepAnnotation] != "true"
+}
+
+func (r *ModuleReconciler) applyCRDs(kClient k8sclient.IKubernetesClient, template *models.Template) []string {
 	installErrors := make([]string, 0)
 
 	for _, d := range template.Dependencies {
-		installErrors = append(installErrors, r.applyCRDs(d)...)
+		installErrors = append(installErrors, r.applyCRDs(kClient, d)...)
 	}
 
 	for _, crdFile := range template.CRDs {
-		installErrors = append(installErrors, r.applyCRDFile(crdFile)...)
+		installErrors = append(installErrors, r.applyCRDFile(kClient, crdFile)...)
 	}
 
 	return installErrors
 }
 
-func (r *ModuleReconciler) applyCRDFile(file *chart.File) []string {
+func (r *ModuleReconciler) applyCRDFile(kClient k8sclient.IKubernetesClient, file *chart.File) []string {
 	installErrors := make([]string, 0)
 
 	for _, s := range strings.Split(string(file.Data), "\n---\n") {
@@ -383,7 +1149,7 @@ func (r *ModuleReconciler) applyCRDFile(file *chart.File) []string {
 			continue
 		}
 
-		if err := r.kubernetesClient.ApplyCRD(crd); err != nil {
+		if err := kClient.ApplyCRD(crd); err != nil {
 			r.logger.Error(err, "failed to apply crd",
 				"crd",
 				crd.GetName(),
@@ -432,14 +1198,98 @@ func (r *ModuleReconciler) mergeChildrenGVRs(existing, current []cyclopsv1alpha1
 	return merged
 }
 
-func (r *ModuleReconciler) finalizeModule(module cyclopsv1alpha1.Module) error {
-	resources, err := r.kubernetesClient.GetResourcesForModule(module.Name)
+// deleteModule deletes resources of a module marked for deletion and removes the finalizer once they are gone.
+// If the module cluster can not be reached, its resources are orphaned so the module is not stuck in deletion.
+func (r *ModuleReconciler) deleteModule(ctx context.Context, req ctrl.Request, module cyclopsv1alpha1.Module) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(&module, cyclopsv1alpha1.ResourceFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	kubernetesClient, err := r.kubernetesClient.ForCluster(module.Spec.Cluster)
 	if err != nil {
-		return err
+		// resources are orphaned only if the cluster was removed or the module orphans them anyway,
+		// any other error is retried with backoff so resources are not leaked on transient failures
+		if !apierrors.IsNotFound(err) && deletionPolicy(module) != cyclopsv1alpha1.DeletionPolicyOrphan {
+			r.recorder.Event(&module, apiv1.EventTypeWarning, eventReasonClusterUnavailable, fmt.Sprintf("failed to get module cluster client: %v", err))
+			return ctrl.Result{}, err
+		}
+
+		r.logger.Error(err, "error getting module cluster client, orphaning module resources", "namespaced name", req.NamespacedName, "cluster", module.Spec.Cluster)
+		r.recorder.Event(&module, apiv1.EventTypeWarning, eventReasonClusterUnavailable, fmt.Sprintf("orphaning module resources: %v", err))
+	} else {
+		blockingResources, err := r.finalizeModule(kubernetesClient, module)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// the finalizer is kept until all resources are deleted, returning an error retries the deletion with backoff
+		if len(blockingResources) != 0 {
+			reason := fmt.Sprintf("deletion blocked by %v module resources", len(blockingResources))
+
+			if err := r.setStatus(
+				ctx,
+				module,
+				req.NamespacedName,
+				cyclopsv1alpha1.Failed,
+				module.Status.TemplateResolvedVersion,
+				reason,
+				blockingResources,
+				nil,
+				module.Status.IconURL,
+				nil,
+				module.Status.AppliedManifestHash,
+				moduleConditions(module.Generation, cyclopsv1alpha1.ConditionReady, cyclopsv1alpha1.ReasonDeletionBlocked, reason),
+			); err != nil {
+				return ctrl.Result{}, err
+			}
+
+			return ctrl.Result{}, fmt.Errorf("%v: %v", reason, strings.Join(blockingResources, "; "))
+		}
+	}
+
+	controllerutil.RemoveFinalizer(&module, cyclopsv1alpha1.ResourceFinalizer)
+	return ctrl.Result{}, r.Update(ctx, &module)
+}
+
+// finalizeModule deletes module resources according to the module deletion policy. It returns errors of
+// resources that failed to delete, the module must not be removed while there are any.
+func (r *ModuleReconciler) finalizeModule(kClient k8sclient.IKubernetesClient, module cyclopsv1alpha1.Module) ([]string, error) {
+	if deletionPolicy(module) == cyclopsv1alpha1.DeletionPolicyOrphan {
+		r.recorder.Event(&module, apiv1.EventTypeNormal, eventReasonFinalized, "orphaned module resources")
+		return nil, nil
+	}
+
+	resources, err := kClient.GetResourcesForModule(module.Namespace, module.Name)
+	if err != nil {
+		r.recorder.Eventf(&module, apiv1.EventTypeWarning, eventReasonFinalizeFailed, "failed to list module resources: %v", err.Error())
+		return nil, err
 	}
 
+	deleteErrors := make([]string, 0)
+	deleted, kept := 0, 0
 	for _, resource := range resources {
-		if err := r.kubernetesClient.Delete(resource); err != nil {
+		if isRetainedKind(module, resource) {
+			kept++
+			continue
+		}
+
+		obj, err := kClient.GetDynamic(
+			resource.GetGroup(),
+			resource.GetVersion(),
+			resource.GetKind(),
+			resource.GetName(),
+			resource.GetNamespace(),
+		)
+		if err == nil && obj.GetAnnotations()[cyclopsv1alpha1.KeepAnnotation] == "true" {
+			kept++
+			continue
+		}
+
+		if err == nil {
+			err = kClient.Delete(resource)
+		}
+
+		if err != nil && !apierrors.IsNotFound(err) {
 			r.logger.Error(
 				err,
 				"error finalizing module: failed to delete module",
@@ -448,10 +1298,104 @@ func (r *ModuleReconciler) finalizeModule(module cyclopsv1alpha1.Module) error {
 				"resource namespaced name",
 				fmt.Sprintf("%s/%s", resource.GetNamespace(), resource.GetName()),
 			)
+			r.resourceEvent(&module, apiv1.EventTypeWarning, eventReasonFinalizeFailed, resource, err.Error())
+
+			deleteErrors = append(deleteErrors, fmt.Sprintf(
+				"%v/%v %v %v/%v failed to delete: %v",
+				resource.GetGroup(),
+				resource.GetVersion(),
+				resource.GetKind(),
+				resource.GetNamespace(),
+				resource.GetName(),
+				err.Error(),
+			))
+			continue
 		}
+
+		deleted++
 	}
 
-	return nil
+	if len(deleteErrors) == 0 {
+		r.recorder.Eventf(&module, apiv1.EventTypeNormal, eventReasonFinalized, "deleted %v and kept %v module resources", deleted, kept)
+	}
+
+	return deleteErrors, nil
+}
+
+func deletionPolicy(module cyclopsv1alpha1.Module) cyclopsv1alpha1.DeletionPolicyType {
+	if module.Spec.DeletionPolicy == nil || len(module.Spec.DeletionPolicy.Policy) == 0 {
+		return cyclopsv1alpha1.DeletionPolicyDelete
+	}
+
+	return module.Spec.DeletionPolicy.Policy
+}
+
+// isRetainedKind checks if the resource kind is listed in the module retained kinds, either by itself or
+// qualified with its group
+func isRetainedKind(module cyclopsv1alpha1.Module, resource *dto.Resource) bool {
+	if module.Spec.DeletionPolicy == nil {
+		return false
+	}
+
+	for _, kind := range module.Spec.DeletionPolicy.RetainKinds {
+		if kind == resource.GetKind() || kind == fmt.Sprintf("%v/%v", resource.GetGroup(), resource.GetKind()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// moduleConditions returns conditions of a reconciliation that stopped at the stage of failedCondition.
+// Conditions of stages before it are true and the ones after it are unknown. All conditions are true if
+// failedCondition is empty, and only Ready is false if the failed condition is Ready.
+func moduleConditions(generation int64, failedCondition, reason, message string) []metav1.Condition {
+	stages := []string{
+		cyclopsv1alpha1.ConditionDependenciesReady,
+		cyclopsv1alpha1.ConditionTemplateFetched,
+		cyclopsv1alpha1.ConditionRendered,
+		cyclopsv1alpha1.ConditionApplied,
+	}
+
+	if len(failedCondition) == 0 {
+		reason = cyclopsv1alpha1.ReasonSucceeded
+	}
+
+	conditions := make([]metav1.Condition, 0, len(stages)+1)
+	status := metav1.ConditionTrue
+	for _, stage := range stages {
+		condition := metav1.Condition{
+			Type:               stage,
+			Status:             status,
+			ObservedGeneration: generation,
+			Reason:             cyclopsv1alpha1.ReasonSucceeded,
+		}
+
+		if stage == failedCondition {
+			condition.Status = metav1.ConditionFalse
+			status = metav1.ConditionUnknown
+		}
+
+		if condition.Status != metav1.ConditionTrue {
+			condition.Reason = reason
+			condition.Message = message
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	ready := metav1.Condition{
+		Type:               cyclopsv1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	}
+	if len(failedCondition) != 0 {
+		ready.Status = metav1.ConditionFalse
+	}
+
+	return append(conditions, ready)
 }
 
 func (r *ModuleReconciler) setStatus(
@@ -464,12 +1408,36 @@ func (r *ModuleReconciler) setStatus(
 	installErrors []string,
 	childrenResources []cyclopsv1alpha1.GroupVersionResource,
 	iconURL string,
+	driftedResources []cyclopsv1alpha1.DriftedResource,
+	appliedManifestHash string,
+	conditions []metav1.Condition,
 ) error {
 	trv := module.Status.TemplateResolvedVersion
 	if len(trv) == 0 {
 		trv = templateResolvedVersion
 	}
 
+	lastAppliedTime := module.Status.LastAppliedTime
+	if len(appliedManifestHash) == 0 {
+		lastAppliedTime = nil
+	} else if appliedManifestHash != module.Status.AppliedManifestHash || lastAppliedTime == nil {
+		now := metav1.Now()
+		lastAppliedTime = &now
+	}
+
+	// the pending sync wave is kept until the manifest is applied, so resources of the wave keep their deadline
+	// while the module is requeued
+	pendingSyncWave := module.Status.PendingSyncWave
+	if len(appliedManifestHash) != 0 {
+		pendingSyncWave = nil
+	}
+
+	// conditions are merged into the existing ones so their transition times only change with their status
+	moduleConditions := module.Status.Conditions
+	for _, condition := range conditions {
+		meta.SetStatusCondition(&moduleConditions, condition)
+	}
+
 	module.Status = cyclopsv1alpha1.ModuleStatus{
 		ReconciliationStatus: &cyclopsv1alpha1.ReconciliationStatus{
 			Status: status,
@@ -479,8 +1447,18 @@ func (r *ModuleReconciler) setStatus(
 		ManagedGVRs:             r.mergeChildrenGVRs(module.Status.ManagedGVRs, childrenResources),
 		TemplateResolvedVersion: templateResolvedVersion,
 		IconURL:                 iconURL,
+		DriftedResources:        driftedResources,
+		AppliedManifestHash:     appliedManifestHash,
+		LastAppliedTime:         lastAppliedTime,
+		PendingSyncWave:         pendingSyncWave,
+		ObservedGeneration:      module.Generation,
+		Conditions:              moduleConditions,
+		Hooks:                   module.Status.Hooks,
+		TemplateUpdate:          module.Status.TemplateUpdate,
 	}
 
+	r.resourceWatcher.ensureWatches(namespacedName, module.Spec.Cluster, module.Status.ManagedGVRs)
+
 	if err := r.Status().Update(ctx, &module); err != nil {
 		r.logger.Error(err, "error updating module status", "namespaced name", namespacedName)
 		return err
@@ -0,0 +1,295 @@
+package modulecontroller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/dto"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+	k8smocks "github.com/andersan81/cyclops/cyclops-ctrl/pkg/mocks"
+)
+
+var _ = Describe("Module controller", func() {
+	var (
+		reconciler *ModuleReconciler
+		kClient    *k8smocks.IKubernetesClient
+	)
+
+	module := func(policy *cyclopsv1alpha1.DeletionPolicy) *cyclopsv1alpha1.Module {
+		return &cyclopsv1alpha1.Module{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "demo",
+				Namespace:  "team-a",
+				Finalizers: []string{cyclopsv1alpha1.ResourceFinalizer},
+			},
+			Spec: cyclopsv1alpha1.ModuleSpec{
+				Cluster:        "staging",
+				DeletionPolicy: policy,
+			},
+		}
+	}
+
+	newReconciler := func(objects ...client.Object) {
+		kClient = &k8smocks.IKubernetesClient{}
+		reconciler = &ModuleReconciler{
+			Client:           fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build(),
+			Scheme:           scheme.Scheme,
+			kubernetesClient: kClient,
+			recorder:         record.NewFakeRecorder(10),
+			logger:           logr.Discard(),
+		}
+	}
+
+	forClusterError := func(err error) func(string) (k8sclient.IKubernetesClient, error) {
+		return func(string) (k8sclient.IKubernetesClient, error) {
+			return nil, err
+		}
+	}
+
+	liveObject := func(name string, annotations map[string]string, owned bool) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetName(name)
+		obj.SetNamespace("apps")
+		obj.SetAnnotations(annotations)
+		if owned {
+			obj.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "api", UID: "api-uid"}})
+		}
+		return obj
+	}
+
+	resource := func(name string, deleted bool) *dto.Resource {
+		return &dto.Resource{Version: "v1", Kind: "ConfigMap", Name: name, Namespace: "apps", Deleted: deleted}
+	}
+
+	finalizers := func() []string {
+		var m cyclopsv1alpha1.Module
+		Expect(reconciler.Get(context.Background(), client.ObjectKey{Namespace: "team-a", Name: "demo"}, &m)).To(Succeed())
+		return m.Finalizers
+	}
+
+	Describe("deleteModule", func() {
+		req := ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "team-a", Name: "demo"}}
+
+		type testCase struct {
+			description   string
+			policy        *cyclopsv1alpha1.DeletionPolicy
+			clusterErr    error
+			returnsError  bool
+			keepFinalizer bool
+		}
+
+		testCases := []testCase{
+			{
+				description: "orphans module resources if the cluster was removed",
+				clusterErr:  apierrors.NewNotFound(schema.GroupResource{Group: "cyclops-ui.com", Resource: "clusters"}, "staging"),
+			},
+			{
+				description: "orphans module resources if the cluster kubeconfig was removed",
+				clusterErr:  apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "staging-kubeconfig"),
+			},
+			{
+				description: "orphans module resources on cluster errors with the orphan policy",
+				policy:      &cyclopsv1alpha1.DeletionPolicy{Policy: cyclopsv1alpha1.DeletionPolicyOrphan},
+				clusterErr:  errors.New("connection refused"),
+			},
+			{
+				description:   "retries the deletion on transient cluster errors",
+				clusterErr:    errors.New("connection refused"),
+				returnsError:  true,
+				keepFinalizer: true,
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				m := module(t.policy)
+				newReconciler(m)
+				kClient.On("ForCluster", "staging").Return(forClusterError(t.clusterErr), nil)
+
+				_, err := reconciler.deleteModule(context.Background(), req, *m)
+				Expect(err != nil).To(Equal(t.returnsError))
+
+				if t.keepFinalizer {
+					Expect(finalizers()).To(ContainElement(cyclopsv1alpha1.ResourceFinalizer))
+				} else {
+					Expect(finalizers()).ToNot(ContainElement(cyclopsv1alpha1.ResourceFinalizer))
+				}
+			})
+		}
+	})
+
+	Describe("pruneResources", func() {
+		const manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: current\n"
+
+		named := func(name string) interface{} {
+			return mock.MatchedBy(func(r *dto.Resource) bool {
+				return r.GetName() == name
+			})
+		}
+
+		It("deletes prunable resources removed from the manifest", func() {
+			m := module(nil)
+			m.Spec.TargetNamespace = "apps"
+			newReconciler(m)
+
+			resources := []*dto.Resource{
+				resource("current", false),
+				resource("removed", true),
+				resource("owned", true),
+				resource("prune-disabled", true),
+				resource("kept", true),
+				resource("already-deleted", true),
+				resource("failing", true),
+			}
+
+			kClient.On("GetResourcesForModule", "team-a", "demo").Return(resources, nil)
+			kClient.On("GetDeletedResources", resources, manifest, "apps").Return(resources, nil)
+
+			kClient.On("GetDynamic", "", "v1", "ConfigMap", "removed", "apps").Return(liveObject("removed", nil, false), nil)
+			kClient.On("GetDynamic", "", "v1", "ConfigMap", "owned", "apps").Return(liveObject("owned", nil, true), nil)
+			kClient.On("GetDynamic", "", "v1", "ConfigMap", "prune-disabled", "apps").
+				Return(liveObject("prune-disabled", map[string]string{cyclopsv1alpha1.PruneAnnotation: "false"}, false), nil)
+			kClient.On("GetDynamic", "", "v1", "ConfigMap", "kept", "apps").
+				Return(liveObject("kept", map[string]string{cyclopsv1alpha1.KeepAnnotation: "true"}, false), nil)
+			kClient.On("GetDynamic", "", "v1", "ConfigMap", "already-deleted", "apps").
+				Return(nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "already-deleted"))
+			kClient.On("GetDynamic", "", "v1", "ConfigMap", "failing", "apps").Return(liveObject("failing", nil, false), nil)
+
+			kClient.On("Delete", named("removed")).Return(nil)
+			kClient.On("Delete", named("failing")).Return(errors.New("forbidden"))
+
+			pruneErrors := reconciler.pruneResources(kClient, *m, manifest)
+
+			Expect(pruneErrors).To(HaveLen(1))
+			Expect(pruneErrors[0]).To(ContainSubstring("apps/failing failed to prune: forbidden"))
+			kClient.AssertNumberOfCalls(GinkgoT(), "Delete", 2)
+			kClient.AssertCalled(GinkgoT(), "Delete", named("removed"))
+			kClient.AssertNotCalled(GinkgoT(), "GetDynamic", "", "v1", "ConfigMap", "current", "apps")
+		})
+
+		It("reports resources that can't be listed", func() {
+			m := module(nil)
+			newReconciler(m)
+
+			kClient.On("GetResourcesForModule", "team-a", "demo").Return(nil, errors.New("connection refused"))
+
+			pruneErrors := reconciler.pruneResources(kClient, *m, manifest)
+
+			Expect(pruneErrors).To(Equal([]string{"failed to list module resources for pruning: connection refused"}))
+			kClient.AssertNotCalled(GinkgoT(), "Delete", mock.Anything)
+		})
+	})
+
+	Describe("finalizeModule", func() {
+		resources := func() []*dto.Resource {
+			return []*dto.Resource{
+				resource("config", false),
+				{Version: "v1", Kind: "PersistentVolumeClaim", Name: "data", Namespace: "apps"},
+				{Group: "apps", Version: "v1", Kind: "Deployment", Name: "api", Namespace: "apps"},
+				resource("credentials", false),
+			}
+		}
+
+		type testCase struct {
+			description string
+			policy      *cyclopsv1alpha1.DeletionPolicy
+			deleted     []string
+			event       string
+		}
+
+		testCases := []testCase{
+			{
+				description: "orphans all module resources",
+				policy:      &cyclopsv1alpha1.DeletionPolicy{Policy: cyclopsv1alpha1.DeletionPolicyOrphan},
+				deleted:     []string{},
+				event:       "orphaned module resources",
+			},
+			{
+				description: "deletes module resources without the keep annotation",
+				deleted:     []string{"config", "data", "api"},
+				event:       "deleted 3 and kept 1 module resources",
+			},
+			{
+				description: "keeps retained kinds with and without their group",
+				policy: &cyclopsv1alpha1.DeletionPolicy{
+					Policy:      cyclopsv1alpha1.DeletionPolicyDelete,
+					RetainKinds: []string{"PersistentVolumeClaim", "apps/Deployment"},
+				},
+				deleted: []string{"config"},
+				event:   "deleted 1 and kept 3 module resources",
+			},
+			{
+				description: "doesn't keep kinds retained in another group",
+				policy: &cyclopsv1alpha1.DeletionPolicy{
+					RetainKinds: []string{"batch/Deployment"},
+				},
+				deleted: []string{"config", "data", "api"},
+				event:   "deleted 3 and kept 1 module resources",
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				m := module(t.policy)
+				newReconciler(m)
+
+				kClient.On("GetResourcesForModule", "team-a", "demo").Return(resources(), nil)
+				kClient.On("GetDynamic", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+					func(_, _, _, name, _ string) (*unstructured.Unstructured, error) {
+						if name == "credentials" {
+							return liveObject(name, map[string]string{cyclopsv1alpha1.KeepAnnotation: "true"}, false), nil
+						}
+						return liveObject(name, nil, false), nil
+					}, nil)
+				kClient.On("Delete", mock.Anything).Return(nil)
+
+				deleteErrors, err := reconciler.finalizeModule(kClient, *m)
+				Expect(err).To(BeNil())
+				Expect(deleteErrors).To(BeEmpty())
+
+				deleted := make([]string, 0)
+				for _, call := range kClient.Calls {
+					if call.Method == "Delete" {
+						deleted = append(deleted, call.Arguments.Get(0).(*dto.Resource).GetName())
+					}
+				}
+				Expect(deleted).To(Equal(t.deleted))
+
+				Expect(reconciler.recorder.(*record.FakeRecorder).Events).To(Receive(ContainSubstring(t.event)))
+			})
+		}
+
+		It("returns resources that failed to delete", func() {
+			m := module(nil)
+			newReconciler(m)
+
+			kClient.On("GetResourcesForModule", "team-a", "demo").Return([]*dto.Resource{resource("config", false), resource("gone", false)}, nil)
+			kClient.On("GetDynamic", "", "v1", "ConfigMap", "config", "apps").Return(liveObject("config", nil, false), nil)
+			kClient.On("GetDynamic", "", "v1", "ConfigMap", "gone", "apps").
+				Return(nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "gone"))
+			kClient.On("Delete", mock.Anything).Return(errors.New("forbidden"))
+
+			deleteErrors, err := reconciler.finalizeModule(kClient, *m)
+			Expect(err).To(BeNil())
+			Expect(deleteErrors).To(HaveLen(1))
+			Expect(deleteErrors[0]).To(ContainSubstring("apps/config failed to delete: forbidden"))
+		})
+	})
+})
@@ -0,0 +1,144 @@
+package modulecontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+const (
+	defaultRevisionHistoryLimit = 10
+
+	// revisionHashLength is the length of the manifest hash prefix in revision names
+	revisionHashLength = 10
+)
+
+// recordRevision creates the revision of the module manifest that was applied, and deletes revisions outside of the
+// module revision history policy. Revisions are named by the module generation and the manifest hash, so a
+// generation whose template version resolved to a new commit, or was reconciled again, gets a new revision
+// instead of overwriting the revision that was applied before.
+func (r *ModuleReconciler) recordRevision(
+	ctx context.Context,
+	module cyclopsv1alpha1.Module,
+	resolvedVersion string,
+	manifestHash string,
+) error {
+	revision := &cyclopsv1alpha1.ModuleRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      revisionName(module, manifestHash),
+			Namespace: module.Namespace,
+			Labels: map[string]string{
+				cyclopsv1alpha1.ModuleRevisionModuleLabel: module.Name,
+			},
+		},
+		Spec: cyclopsv1alpha1.ModuleRevisionSpec{
+			Module:          module.Name,
+			Generation:      module.Generation,
+			TargetNamespace: module.Spec.TargetNamespace,
+			TemplateRef: cyclopsv1alpha1.HistoryTemplateRef{
+				URL:        module.Spec.TemplateRef.URL,
+				Path:       module.Spec.TemplateRef.Path,
+				Version:    resolvedVersion,
+				SourceType: module.Spec.TemplateRef.SourceType,
+			},
+			Values:       module.Spec.Values,
+			ManifestHash: manifestHash,
+			Author:       module.GetAnnotations()[cyclopsv1alpha1.AuthorAnnotation],
+		},
+	}
+
+	// revisions are owned by their module to be deleted together with it
+	if err := controllerutil.SetOwnerReference(&module, revision, r.Scheme); err != nil {
+		return err
+	}
+
+	// revisions are snapshots of applied manifests and are never updated; an existing revision with the same name
+	// was recorded for the same manifest
+	if err := r.Create(ctx, revision); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	return r.pruneRevisions(ctx, module, manifestHash)
+}
+
+func revisionName(module cyclopsv1alpha1.Module, manifestHash string) string {
+	if len(manifestHash) > revisionHashLength {
+		manifestHash = manifestHash[:revisionHashLength]
+	}
+
+	return fmt.Sprintf("%v-%v-%v", module.Name, module.Generation, manifestHash)
+}
+
+// pruneRevisions deletes revisions over the limit or older than the max age of the module revision history
+// policy. The revision of the currently applied manifest is always kept.
+func (r *ModuleReconciler) pruneRevisions(ctx context.Context, module cyclopsv1alpha1.Module, manifestHash string) error {
+	var revisions cyclopsv1alpha1.ModuleRevisionList
+	if err := r.List(
+		ctx,
+		&revisions,
+		client.InNamespace(module.Namespace),
+		client.MatchingLabels{cyclopsv1alpha1.ModuleRevisionModuleLabel: module.Name},
+	); err != nil {
+		return err
+	}
+
+	sortRevisions(revisions.Items)
+
+	limit, maxAge := revisionHistoryLimits(module)
+
+	kept := 0
+	for _, revision := range revisions.Items {
+		if revision.Spec.Generation == module.Generation && revision.Spec.ManifestHash == manifestHash {
+			continue
+		}
+
+		if kept < limit && (maxAge == 0 || time.Since(revision.CreationTimestamp.Time) < maxAge) {
+			kept++
+			continue
+		}
+
+		if err := r.Delete(ctx, &revision); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortRevisions sorts revisions newest first; by generation, and by creation time within a generation
+func sortRevisions(revisions []cyclopsv1alpha1.ModuleRevision) {
+	sort.SliceStable(revisions, func(i, j int) bool {
+		if revisions[i].Spec.Generation != revisions[j].Spec.Generation {
+			return revisions[i].Spec.Generation > revisions[j].Spec.Generation
+		}
+
+		return revisions[j].CreationTimestamp.Before(&revisions[i].CreationTimestamp)
+	})
+}
+
+func revisionHistoryLimits(module cyclopsv1alpha1.Module) (int, time.Duration) {
+	limit, maxAge := defaultRevisionHistoryLimit, time.Duration(0)
+
+	policy := module.Spec.RevisionHistory
+	if policy == nil {
+		return limit, maxAge
+	}
+
+	if policy.Limit != nil {
+		limit = int(*policy.Limit)
+	}
+
+	if policy.MaxAge != nil {
+		maxAge = policy.MaxAge.Duration
+	}
+
+	return limit, maxAge
+}
@@ -0,0 +1,203 @@
+package modulecontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+var _ = Describe("Module revisions", func() {
+	var reconciler *ModuleReconciler
+
+	module := func(generation int64, policy *cyclopsv1alpha1.RevisionHistoryPolicy) cyclopsv1alpha1.Module {
+		return cyclopsv1alpha1.Module{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "team-a", Generation: generation, UID: "demo-uid"},
+			Spec: cyclopsv1alpha1.ModuleSpec{
+				TemplateRef: cyclopsv1alpha1.TemplateRef{
+					URL:        "https://github.com/cyclops-ui/templates",
+					Path:       "demo",
+					Version:    "main",
+					SourceType: cyclopsv1alpha1.TemplateSourceTypeGit,
+				},
+				RevisionHistory: policy,
+			},
+		}
+	}
+
+	revision := func(generation int64, manifestHash string, age time.Duration) *cyclopsv1alpha1.ModuleRevision {
+		return &cyclopsv1alpha1.ModuleRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              fmt.Sprintf("demo-%v-%v", generation, manifestHash),
+				Namespace:         "team-a",
+				Labels:            map[string]string{cyclopsv1alpha1.ModuleRevisionModuleLabel: "demo"},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+			Spec: cyclopsv1alpha1.ModuleRevisionSpec{
+				Module:       "demo",
+				Generation:   generation,
+				ManifestHash: manifestHash,
+			},
+		}
+	}
+
+	revisionNames := func() []string {
+		var revisions cyclopsv1alpha1.ModuleRevisionList
+		Expect(reconciler.List(context.Background(), &revisions, client.InNamespace("team-a"))).To(Succeed())
+
+		names := make([]string, 0, len(revisions.Items))
+		for _, r := range revisions.Items {
+			names = append(names, r.Name)
+		}
+
+		return names
+	}
+
+	newReconciler := func(objects ...client.Object) {
+		reconciler = &ModuleReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build(),
+			Scheme: scheme.Scheme,
+		}
+	}
+
+	Describe("recordRevision", func() {
+		It("records a new revision when the template of a generation resolves to a new commit", func() {
+			newReconciler(revision(3, "aaaaaaaaaa", time.Hour))
+
+			Expect(reconciler.recordRevision(context.Background(), module(3, nil), "5d2a1f4", "bbbbbbbbbbbbbbbb")).To(Succeed())
+
+			Expect(revisionNames()).To(ConsistOf("demo-3-aaaaaaaaaa", "demo-3-bbbbbbbbbb"))
+
+			var recorded cyclopsv1alpha1.ModuleRevision
+			Expect(reconciler.Get(context.Background(), client.ObjectKey{Namespace: "team-a", Name: "demo-3-bbbbbbbbbb"}, &recorded)).To(Succeed())
+			Expect(recorded.Spec.TemplateRef.Version).To(Equal("5d2a1f4"))
+			Expect(recorded.Spec.ManifestHash).To(Equal("bbbbbbbbbbbbbbbb"))
+			Expect(recorded.OwnerReferences).To(HaveLen(1))
+			Expect(recorded.OwnerReferences[0].Name).To(Equal("demo"))
+		})
+
+		It("doesn't overwrite revisions of the same manifest", func() {
+			existing := revision(3, "aaaaaaaaaa", time.Hour)
+			existing.Spec.TemplateRef.Version = "5d2a1f4"
+			newReconciler(existing)
+
+			Expect(reconciler.recordRevision(context.Background(), module(3, nil), "8c0e3b7", "aaaaaaaaaaaaaaaa")).To(Succeed())
+
+			var recorded cyclopsv1alpha1.ModuleRevision
+			Expect(reconciler.Get(context.Background(), client.ObjectKey{Namespace: "team-a", Name: "demo-3-aaaaaaaaaa"}, &recorded)).To(Succeed())
+			Expect(recorded.Spec.TemplateRef.Version).To(Equal("5d2a1f4"))
+		})
+	})
+
+	Describe("pruneRevisions", func() {
+		limit := int32(2)
+
+		type testCase struct {
+			description string
+			policy      *cyclopsv1alpha1.RevisionHistoryPolicy
+			generation  int64
+			revisions   []*cyclopsv1alpha1.ModuleRevision
+			kept        []string
+		}
+
+		testCases := []testCase{
+			{
+				description: "keeps the current revision and the default number of previous revisions",
+				generation:  13,
+				revisions: func() []*cyclopsv1alpha1.ModuleRevision {
+					revisions := make([]*cyclopsv1alpha1.ModuleRevision, 0)
+					for generation := int64(1); generation <= 13; generation++ {
+						revisions = append(revisions, revision(generation, "current", time.Duration(13-generation)*time.Hour))
+					}
+					return revisions
+				}(),
+				kept: []string{
+					"demo-13-current", "demo-12-current", "demo-11-current", "demo-10-current", "demo-9-current",
+					"demo-8-current", "demo-7-current", "demo-6-current", "demo-5-current", "demo-4-current",
+					"demo-3-current",
+				},
+			},
+			{
+				description: "keeps the limit of previous revisions, including previous revisions of the current generation",
+				policy:      &cyclopsv1alpha1.RevisionHistoryPolicy{Limit: &limit},
+				generation:  4,
+				revisions: []*cyclopsv1alpha1.ModuleRevision{
+					revision(4, "current", 0),
+					revision(4, "previous", time.Hour),
+					revision(3, "current", 2*time.Hour),
+					revision(2, "current", 3*time.Hour),
+				},
+				kept: []string{"demo-4-current", "demo-4-previous", "demo-3-current"},
+			},
+			{
+				description: "deletes revisions older than the max age",
+				policy:      &cyclopsv1alpha1.RevisionHistoryPolicy{MaxAge: &metav1.Duration{Duration: 24 * time.Hour}},
+				generation:  4,
+				revisions: []*cyclopsv1alpha1.ModuleRevision{
+					revision(4, "current", 48*time.Hour),
+					revision(3, "current", 12*time.Hour),
+					revision(2, "current", 36*time.Hour),
+				},
+				kept: []string{"demo-4-current", "demo-3-current"},
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				objects := make([]client.Object, 0, len(t.revisions))
+				for _, r := range t.revisions {
+					objects = append(objects, r)
+				}
+				newReconciler(objects...)
+
+				Expect(reconciler.pruneRevisions(context.Background(), module(t.generation, t.policy), "current")).To(Succeed())
+				Expect(revisionNames()).To(ConsistOf(t.kept))
+			})
+		}
+	})
+
+	Describe("revisionHistoryLimits", func() {
+		limit := int32(3)
+
+		type testCase struct {
+			description string
+			policy      *cyclopsv1alpha1.RevisionHistoryPolicy
+			limit       int
+			maxAge      time.Duration
+		}
+
+		testCases := []testCase{
+			{
+				description: "defaults without a policy",
+				limit:       defaultRevisionHistoryLimit,
+			},
+			{
+				description: "uses the limit of the policy",
+				policy:      &cyclopsv1alpha1.RevisionHistoryPolicy{Limit: &limit},
+				limit:       3,
+			},
+			{
+				description: "uses the max age of the policy with the default limit",
+				policy:      &cyclopsv1alpha1.RevisionHistoryPolicy{MaxAge: &metav1.Duration{Duration: time.Hour}},
+				limit:       defaultRevisionHistoryLimit,
+				maxAge:      time.Hour,
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				limit, maxAge := revisionHistoryLimits(module(1, t.policy))
+				Expect(limit).To(Equal(t.limit))
+				Expect(maxAge).To(Equal(t.maxAge))
+			})
+		}
+	})
+})
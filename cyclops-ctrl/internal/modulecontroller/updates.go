@@ -0,0 +1,183 @@
+package modulecontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	templaterepo "github.com/andersan81/cyclops/cyclops-ctrl/pkg/template"
+)
+
+const templateUpdateCheckInterval = 5 * time.Minute
+
+// templateUpdateChecker periodically resolves template versions of all modules to find new commits and chart
+// releases. Available updates are reported in the module status, and modules with an upgrade policy are
+// upgraded to them by bumping their resolved template version.
+type templateUpdateChecker struct {
+	client        client.Client
+	templatesRepo templaterepo.ITemplateRepo
+	recorder      record.EventRecorder
+
+	logger logr.Logger
+}
+
+func newTemplateUpdateChecker(
+	client client.Client,
+	templatesRepo templaterepo.ITemplateRepo,
+	recorder record.EventRecorder,
+	logger logr.Logger,
+) *templateUpdateChecker {
+	return &templateUpdateChecker{
+		client:        client,
+		templatesRepo: templatesRepo,
+		recorder:      recorder,
+		logger:        logger,
+	}
+}
+
+// Start implements manager.Runnable. It checks modules for template updates until ctx is done.
+func (c *templateUpdateChecker) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, c.checkModules, templateUpdateCheckInterval)
+	return nil
+}
+
+func (c *templateUpdateChecker) checkModules(ctx context.Context) {
+	var modules cyclopsv1alpha1.ModuleList
+	if err := c.client.List(ctx, &modules); err != nil {
+		c.logger.Error(err, "error listing modules for template updates")
+		return
+	}
+
+	for _, module := range modules.Items {
+		if err := c.checkModule(ctx, module); err != nil {
+			c.logger.Error(err, "error checking module template updates", "module namespaced name", module.Name)
+		}
+	}
+}
+
+func (c *templateUpdateChecker) checkModule(ctx context.Context, module cyclopsv1alpha1.Module) error {
+	// modules are checked only once the reconciler resolved their template version
+	currentVersion := module.Status.TemplateResolvedVersion
+	if module.GetDeletionTimestamp() != nil || len(currentVersion) == 0 {
+		return nil
+	}
+
+	update := cyclopsv1alpha1.TemplateUpdateStatus{
+		LastCheckTime: metav1.Now(),
+	}
+
+	availableVersion, err := c.templatesRepo.ResolveVersion(
+		module.Spec.TemplateRef.URL,
+		module.Spec.TemplateRef.Path,
+		module.Spec.TemplateRef.Version,
+		module.Spec.TemplateRef.SourceType,
+	)
+	if err != nil {
+		update.Message = fmt.Sprintf("failed to resolve template version: %v", err.Error())
+	} else if templaterepo.IsUpgrade(currentVersion, availableVersion) {
+		update.AvailableVersion = availableVersion
+	}
+
+	upgrade := len(update.AvailableVersion) != 0 && isUpgradeDue(module, currentVersion, update.AvailableVersion, time.Now())
+	if upgrade {
+		update.AvailableVersion = ""
+		update.Message = fmt.Sprintf("upgraded template from %v to %v", currentVersion, availableVersion)
+	}
+
+	if !upgrade && sameTemplateUpdate(module.Status.TemplateUpdate, update) {
+		return nil
+	}
+
+	patch := client.MergeFrom(module.DeepCopy())
+	module.Status.TemplateUpdate = &update
+	if upgrade {
+		// the status change triggers a reconciliation that renders the module from the new template version
+		module.Status.TemplateResolvedVersion = availableVersion
+	}
+
+	if err := c.client.Status().Patch(ctx, &module, patch); err != nil {
+		return err
+	}
+
+	if upgrade {
+		c.logger.Info("upgraded module template",
+			"module namespaced name",
+			module.Name,
+			"from",
+			currentVersion,
+			"to",
+			availableVersion,
+		)
+		c.recorder.Eventf(&module, apiv1.EventTypeNormal, eventReasonTemplateUpgraded, "upgraded template from %v to %v", currentVersion, availableVersion)
+	}
+
+	return nil
+}
+
+func sameTemplateUpdate(existing *cyclopsv1alpha1.TemplateUpdateStatus, update cyclopsv1alpha1.TemplateUpdateStatus) bool {
+	return existing != nil && existing.AvailableVersion == update.AvailableVersion && existing.Message == update.Message
+}
+
+// isUpgradeDue checks if the module upgrade policy allows upgrading from current to available at the given time
+func isUpgradeDue(module cyclopsv1alpha1.Module, current, available string, now time.Time) bool {
+	if module.Spec.UpgradePolicy == nil {
+		return false
+	}
+
+	if !templaterepo.IsUpgradeAllowed(current, available, module.Spec.UpgradePolicy.Mode) {
+		return false
+	}
+
+	return inMaintenanceWindow(module.Spec.UpgradePolicy.MaintenanceWindow, now)
+}
+
+// inMaintenanceWindow checks if the window is open at the given time. Windows longer than a day can still be
+// open from one of the previous days.
+func inMaintenanceWindow(window *cyclopsv1alpha1.MaintenanceWindow, now time.Time) bool {
+	if window == nil {
+		return true
+	}
+
+	start, err := time.Parse("15:04", window.Start)
+	if err != nil {
+		return false
+	}
+
+	now = now.UTC()
+	for daysBack := 0; daysBack <= int(window.Duration.Hours()/24)+1; daysBack++ {
+		day := now.AddDate(0, 0, -daysBack)
+		opensAt := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, time.UTC)
+
+		if !windowOpensOn(window.Days, opensAt.Weekday()) {
+			continue
+		}
+
+		if !now.Before(opensAt) && now.Before(opensAt.Add(window.Duration.Duration)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func windowOpensOn(days []string, weekday time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+
+	for _, day := range days {
+		if day == weekday.String()[:3] {
+			return true
+		}
+	}
+
+	return false
+}
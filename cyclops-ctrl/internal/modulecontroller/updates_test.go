@@ -0,0 +1,199 @@
+package modulecontroller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+var _ = Describe("Template updates", func() {
+	// 2024-06-07 is a Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.June, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	window := func(start string, duration time.Duration, days ...string) *cyclopsv1alpha1.MaintenanceWindow {
+		return &cyclopsv1alpha1.MaintenanceWindow{
+			Days:     days,
+			Start:    start,
+			Duration: metav1.Duration{Duration: duration},
+		}
+	}
+
+	Describe("inMaintenanceWindow", func() {
+		type testCase struct {
+			description string
+			window      *cyclopsv1alpha1.MaintenanceWindow
+			now         time.Time
+			out         bool
+		}
+
+		testCases := []testCase{
+			{
+				description: "always open without a window",
+				now:         at(7, 12, 0),
+				out:         true,
+			},
+			{
+				description: "open within a daily window",
+				window:      window("02:00", 2*time.Hour),
+				now:         at(7, 3, 0),
+				out:         true,
+			},
+			{
+				description: "open at the start of the window",
+				window:      window("02:00", 2*time.Hour),
+				now:         at(7, 2, 0),
+				out:         true,
+			},
+			{
+				description: "closed at the end of the window",
+				window:      window("02:00", 2*time.Hour),
+				now:         at(7, 4, 0),
+				out:         false,
+			},
+			{
+				description: "closed before the window",
+				window:      window("02:00", 2*time.Hour),
+				now:         at(7, 1, 59),
+				out:         false,
+			},
+			{
+				description: "open before midnight in a window across midnight",
+				window:      window("23:00", 3*time.Hour),
+				now:         at(7, 23, 30),
+				out:         true,
+			},
+			{
+				description: "open after midnight in a window across midnight",
+				window:      window("23:00", 3*time.Hour),
+				now:         at(8, 1, 30),
+				out:         true,
+			},
+			{
+				description: "closed after a window across midnight",
+				window:      window("23:00", 3*time.Hour),
+				now:         at(8, 2, 0),
+				out:         false,
+			},
+			{
+				description: "open on the next day in a window across midnight that opens on one day",
+				window:      window("23:00", 3*time.Hour, "Fri"),
+				now:         at(8, 1, 0),
+				out:         true,
+			},
+			{
+				description: "closed on the morning of the day a window across midnight opens",
+				window:      window("23:00", 3*time.Hour, "Fri"),
+				now:         at(7, 1, 0),
+				out:         false,
+			},
+			{
+				description: "open at midnight on the day of the window",
+				window:      window("00:00", time.Hour, "Mon"),
+				now:         at(10, 0, 0),
+				out:         true,
+			},
+			{
+				description: "closed right before midnight on the day before the window",
+				window:      window("00:00", time.Hour, "Mon"),
+				now:         at(9, 23, 59),
+				out:         false,
+			},
+			{
+				description: "open on the last day of a window longer than a day",
+				window:      window("00:00", 48*time.Hour, "Sat"),
+				now:         at(9, 23, 59),
+				out:         true,
+			},
+			{
+				description: "closed after a window longer than a day",
+				window:      window("00:00", 48*time.Hour, "Sat"),
+				now:         at(10, 0, 0),
+				out:         false,
+			},
+			{
+				description: "checks windows in UTC",
+				window:      window("22:00", 2*time.Hour, "Fri"),
+				now:         time.Date(2024, time.June, 8, 1, 0, 0, 0, time.FixedZone("CEST", 2*60*60)),
+				out:         true,
+			},
+			{
+				description: "closed with an invalid start",
+				window:      window("2am", 2*time.Hour),
+				now:         at(7, 2, 30),
+				out:         false,
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				Expect(inMaintenanceWindow(t.window, t.now)).To(Equal(t.out))
+			})
+		}
+	})
+
+	Describe("isUpgradeDue", func() {
+		type testCase struct {
+			description string
+			policy      *cyclopsv1alpha1.UpgradePolicy
+			available   string
+			now         time.Time
+			out         bool
+		}
+
+		testCases := []testCase{
+			{
+				description: "no upgrades without a policy",
+				available:   "1.2.4",
+				now:         at(7, 12, 0),
+				out:         false,
+			},
+			{
+				description: "upgrade allowed by the policy without a window",
+				policy:      &cyclopsv1alpha1.UpgradePolicy{Mode: cyclopsv1alpha1.UpgradeModePatch},
+				available:   "1.2.4",
+				now:         at(7, 12, 0),
+				out:         true,
+			},
+			{
+				description: "upgrade not allowed by the policy",
+				policy:      &cyclopsv1alpha1.UpgradePolicy{Mode: cyclopsv1alpha1.UpgradeModePatch},
+				available:   "1.3.0",
+				now:         at(7, 12, 0),
+				out:         false,
+			},
+			{
+				description: "upgrade allowed by the policy within the window",
+				policy: &cyclopsv1alpha1.UpgradePolicy{
+					Mode:              cyclopsv1alpha1.UpgradeModeMinor,
+					MaintenanceWindow: window("23:00", 3*time.Hour, "Fri"),
+				},
+				available: "1.3.0",
+				now:       at(8, 0, 30),
+				out:       true,
+			},
+			{
+				description: "upgrade allowed by the policy outside of the window",
+				policy: &cyclopsv1alpha1.UpgradePolicy{
+					Mode:              cyclopsv1alpha1.UpgradeModeMinor,
+					MaintenanceWindow: window("23:00", 3*time.Hour, "Fri"),
+				},
+				available: "1.3.0",
+				now:       at(8, 23, 30),
+				out:       false,
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				module := cyclopsv1alpha1.Module{Spec: cyclopsv1alpha1.ModuleSpec{UpgradePolicy: t.policy}}
+				Expect(isUpgradeDue(module, "1.2.3", t.available, t.now)).To(Equal(t.out))
+			})
+		}
+	})
+})
@@ -0,0 +1,40 @@
+package modulecontroller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// modulesForValuesReference maps a ConfigMap or Secret of the given kind to modules sourcing their values from it
+func (r *ModuleReconciler) modulesForValuesReference(kind cyclopsv1alpha1.ValuesReferenceKind) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var modules cyclopsv1alpha1.ModuleList
+		if err := r.List(ctx, &modules, client.InNamespace(obj.GetNamespace())); err != nil {
+			r.logger.Error(err, "error listing modules for values reference", "kind", kind, "name", obj.GetName())
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0)
+		for _, module := range modules.Items {
+			for _, reference := range module.Spec.ValuesFrom {
+				if reference.Kind == kind && reference.Name == obj.GetName() {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Name:      module.Name,
+							Namespace: module.Namespace,
+						},
+					})
+					break
+				}
+			}
+		}
+
+		return requests
+	}
+}
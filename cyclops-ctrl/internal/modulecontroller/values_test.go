@@ -0,0 +1,95 @@
+package modulecontroller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+var _ = Describe("Module values references", func() {
+	module := func(namespace, name string, valuesFrom ...cyclopsv1alpha1.ValuesReference) *cyclopsv1alpha1.Module {
+		return &cyclopsv1alpha1.Module{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       cyclopsv1alpha1.ModuleSpec{ValuesFrom: valuesFrom},
+		}
+	}
+
+	configMapRef := func(name string) cyclopsv1alpha1.ValuesReference {
+		return cyclopsv1alpha1.ValuesReference{Kind: cyclopsv1alpha1.ValuesReferenceKindConfigMap, Name: name}
+	}
+
+	secretRef := func(name string) cyclopsv1alpha1.ValuesReference {
+		return cyclopsv1alpha1.ValuesReference{Kind: cyclopsv1alpha1.ValuesReferenceKindSecret, Name: name}
+	}
+
+	var reconciler *ModuleReconciler
+
+	BeforeEach(func() {
+		reconciler = &ModuleReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				module("team-a", "api", configMapRef("shared"), secretRef("api-credentials")),
+				module("team-a", "worker", secretRef("shared"), configMapRef("shared")),
+				module("team-a", "cron", secretRef("shared")),
+				module("team-a", "web"),
+				module("team-b", "api", configMapRef("shared")),
+			).Build(),
+			Scheme: scheme.Scheme,
+			logger: logr.Discard(),
+		}
+	})
+
+	type testCase struct {
+		description string
+		kind        cyclopsv1alpha1.ValuesReferenceKind
+		obj         client.Object
+		modules     []string
+	}
+
+	testCases := []testCase{
+		{
+			description: "maps a ConfigMap to modules in its namespace referencing it",
+			kind:        cyclopsv1alpha1.ValuesReferenceKindConfigMap,
+			obj:         &apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "team-a"}},
+			modules:     []string{"team-a/api", "team-a/worker"},
+		},
+		{
+			description: "maps a Secret to modules referencing a Secret with its name",
+			kind:        cyclopsv1alpha1.ValuesReferenceKindSecret,
+			obj:         &apiv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "team-a"}},
+			modules:     []string{"team-a/worker", "team-a/cron"},
+		},
+		{
+			description: "maps a ConfigMap in another namespace to modules of that namespace",
+			kind:        cyclopsv1alpha1.ValuesReferenceKindConfigMap,
+			obj:         &apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "team-b"}},
+			modules:     []string{"team-b/api"},
+		},
+		{
+			description: "maps an unreferenced ConfigMap to no modules",
+			kind:        cyclopsv1alpha1.ValuesReferenceKindConfigMap,
+			obj:         &apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "api-credentials", Namespace: "team-a"}},
+			modules:     []string{},
+		},
+	}
+
+	for _, t := range testCases {
+		It(t.description, func() {
+			requests := reconciler.modulesForValuesReference(t.kind)(context.Background(), t.obj)
+
+			modules := make([]string, 0, len(requests))
+			for _, request := range requests {
+				modules = append(modules, request.String())
+			}
+			Expect(modules).To(ConsistOf(t.modules))
+		})
+	}
+})
@@ -0,0 +1,222 @@
+package modulecontroller
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+)
+
+// resourceWatcher watches resources of all GVRs managed by modules and passes changed resources on to the
+// module controller, so changes made outside of Cyclops are noticed without waiting for the module to change.
+// GVRs are watched separately in every cluster modules are deployed to.
+type resourceWatcher struct {
+	kubernetesClient k8sclient.IKubernetesClient
+
+	// queue holds modules of changed resources. Informers only add to it, so they are never blocked by the module
+	// controller, and multiple changes of a module's resources are passed on once.
+	queue  workqueue.Interface
+	events chan event.GenericEvent
+
+	mu sync.Mutex
+	// modules holds the cluster and GVRs of every module, so watches no module references anymore are stopped
+	modules map[types.NamespacedName]watchedModule
+	// watched holds the function stopping the watch of each GVR, which is nil until the watcher is started
+	watched map[watchedGVR]context.CancelFunc
+	ctx     context.Context
+
+	logger logr.Logger
+}
+
+// moduleRef references the module a resource belongs to by its module labels
+type moduleRef struct {
+	name         string
+	namespace    string
+	hasNamespace bool
+}
+
+type watchedGVR struct {
+	cluster string
+	gvr     cyclopsv1alpha1.GroupVersionResource
+}
+
+type watchedModule struct {
+	cluster string
+	gvrs    []cyclopsv1alpha1.GroupVersionResource
+}
+
+func newResourceWatcher(kubernetesClient k8sclient.IKubernetesClient, logger logr.Logger) *resourceWatcher {
+	return &resourceWatcher{
+		kubernetesClient: kubernetesClient,
+		queue:            workqueue.New(),
+		events:           make(chan event.GenericEvent),
+		modules:          make(map[types.NamespacedName]watchedModule),
+		watched:          make(map[watchedGVR]context.CancelFunc),
+		logger:           logger,
+	}
+}
+
+// Start implements manager.Runnable. It starts watches for GVRs registered before the manager started and
+// forwards resource changes until ctx is done.
+func (w *resourceWatcher) Start(ctx context.Context) error {
+	w.mu.Lock()
+	w.ctx = ctx
+	for watched := range w.watched {
+		w.startWatch(watched)
+	}
+	w.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		w.queue.ShutDown()
+	}()
+
+	for {
+		item, shutdown := w.queue.Get()
+		if shutdown {
+			return nil
+		}
+
+		ref := item.(moduleRef)
+
+		resource := &unstructured.Unstructured{}
+		resource.SetLabels(ref.labels())
+
+		select {
+		case w.events <- event.GenericEvent{Object: resource}:
+			w.queue.Done(item)
+		case <-ctx.Done():
+			w.queue.Done(item)
+			return nil
+		}
+	}
+}
+
+// enqueue adds the module of the changed resource to the queue
+func (w *resourceWatcher) enqueue(resource *unstructured.Unstructured) {
+	moduleName := resource.GetLabels()["cyclops.module"]
+	if len(moduleName) == 0 {
+		return
+	}
+
+	moduleNamespace, hasNamespace := resource.GetLabels()[k8sclient.ModuleNamespaceLabel]
+
+	w.queue.Add(moduleRef{
+		name:         moduleName,
+		namespace:    moduleNamespace,
+		hasNamespace: hasNamespace,
+	})
+}
+
+func (r moduleRef) labels() map[string]string {
+	labels := map[string]string{
+		"cyclops.module": r.name,
+	}
+
+	if r.hasNamespace {
+		labels[k8sclient.ModuleNamespaceLabel] = r.namespace
+	}
+
+	return labels
+}
+
+// ensureWatches starts watching all the given GVRs of the module in the cluster that are not watched already, and
+// stops watches no module references anymore
+func (w *resourceWatcher) ensureWatches(module types.NamespacedName, cluster string, gvrs []cyclopsv1alpha1.GroupVersionResource) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.modules[module] = watchedModule{cluster: cluster, gvrs: gvrs}
+
+	for _, gvr := range gvrs {
+		watched := watchedGVR{cluster: cluster, gvr: gvr}
+		if _, ok := w.watched[watched]; ok {
+			continue
+		}
+
+		w.watched[watched] = nil
+
+		if w.ctx != nil {
+			w.startWatch(watched)
+		}
+	}
+
+	w.stopUnreferencedWatches()
+}
+
+// forgetModule stops watches only the deleted module referenced
+func (w *resourceWatcher) forgetModule(module types.NamespacedName) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.modules[module]; !ok {
+		return
+	}
+
+	delete(w.modules, module)
+	w.stopUnreferencedWatches()
+}
+
+// stopUnreferencedWatches stops watches of GVRs no module in the cluster manages anymore. Cached clients of
+// clusters no module is deployed to anymore are dropped, so clients of removed clusters are not kept around.
+func (w *resourceWatcher) stopUnreferencedWatches() {
+	referenced := make(map[watchedGVR]struct{})
+	clusters := make(map[string]struct{})
+	for _, module := range w.modules {
+		clusters[module.cluster] = struct{}{}
+		for _, gvr := range module.gvrs {
+			referenced[watchedGVR{cluster: module.cluster, gvr: gvr}] = struct{}{}
+		}
+	}
+
+	for watched, stop := range w.watched {
+		if _, ok := referenced[watched]; ok {
+			continue
+		}
+
+		w.logger.Info("stopped watching module resources", "cluster", watched.cluster, "gvr", watched.gvr)
+
+		if stop != nil {
+			stop()
+		}
+		delete(w.watched, watched)
+
+		if _, ok := clusters[watched.cluster]; !ok && len(watched.cluster) != 0 {
+			w.kubernetesClient.ForgetCluster(watched.cluster)
+			clusters[watched.cluster] = struct{}{}
+		}
+	}
+}
+
+func (w *resourceWatcher) startWatch(watched watchedGVR) {
+	w.logger.Info("watching module resources", "cluster", watched.cluster, "gvr", watched.gvr)
+
+	kubernetesClient, err := w.kubernetesClient.ForCluster(watched.cluster)
+	if err != nil {
+		// the watch is started again by the next reconciliation of a module deployed to the cluster
+		w.logger.Error(err, "error getting cluster client for watch", "cluster", watched.cluster, "gvr", watched.gvr)
+		delete(w.watched, watched)
+		return
+	}
+
+	ctx, stop := context.WithCancel(w.ctx)
+	w.watched[watched] = stop
+
+	kubernetesClient.WatchModuleResources(
+		schema.GroupVersionResource{
+			Group:    watched.gvr.Group,
+			Version:  watched.gvr.Version,
+			Resource: watched.gvr.Resource,
+		},
+		w.enqueue,
+		ctx.Done(),
+	)
+}
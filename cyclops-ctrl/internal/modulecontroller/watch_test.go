@@ -0,0 +1,111 @@
+package modulecontroller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	k8smocks "github.com/andersan81/cyclops/cyclops-ctrl/pkg/mocks"
+)
+
+var _ = Describe("Resource watcher", func() {
+	deployments := cyclopsv1alpha1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	services := cyclopsv1alpha1.GroupVersionResource{Version: "v1", Resource: "services"}
+
+	api := types.NamespacedName{Namespace: "team-a", Name: "api"}
+	worker := types.NamespacedName{Namespace: "team-a", Name: "worker"}
+
+	var (
+		k8sClient, stagingClient *k8smocks.IKubernetesClient
+		watcher                  *resourceWatcher
+		stopChannels             map[string]<-chan struct{}
+	)
+
+	stopped := func(gvr cyclopsv1alpha1.GroupVersionResource) bool {
+		stopCh, ok := stopChannels[gvr.Resource]
+		Expect(ok).To(BeTrue())
+
+		select {
+		case <-stopCh:
+			return true
+		default:
+			return false
+		}
+	}
+
+	BeforeEach(func() {
+		k8sClient = &k8smocks.IKubernetesClient{}
+		stagingClient = &k8smocks.IKubernetesClient{}
+		stopChannels = make(map[string]<-chan struct{})
+
+		k8sClient.On("ForCluster", "").Return(k8sClient, nil)
+		k8sClient.On("ForCluster", "staging").Return(stagingClient, nil)
+		k8sClient.On("ForgetCluster", mock.Anything).Return()
+		for _, client := range []*k8smocks.IKubernetesClient{k8sClient, stagingClient} {
+			client.On("WatchModuleResources", mock.Anything, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					stopChannels[args.Get(0).(schema.GroupVersionResource).Resource] = args.Get(2).(<-chan struct{})
+				}).
+				Return()
+		}
+
+		watcher = newResourceWatcher(k8sClient, logr.Discard())
+		watcher.ctx = context.Background()
+	})
+
+	It("watches a GVR once for all modules managing it", func() {
+		watcher.ensureWatches(api, "staging", []cyclopsv1alpha1.GroupVersionResource{deployments})
+		watcher.ensureWatches(worker, "staging", []cyclopsv1alpha1.GroupVersionResource{deployments, services})
+
+		stagingClient.AssertNumberOfCalls(GinkgoT(), "WatchModuleResources", 2)
+		Expect(stopped(deployments)).To(BeFalse())
+		Expect(stopped(services)).To(BeFalse())
+	})
+
+	It("stops watches no module references anymore", func() {
+		watcher.ensureWatches(api, "staging", []cyclopsv1alpha1.GroupVersionResource{deployments})
+		watcher.ensureWatches(worker, "staging", []cyclopsv1alpha1.GroupVersionResource{deployments, services})
+
+		watcher.ensureWatches(worker, "staging", []cyclopsv1alpha1.GroupVersionResource{services})
+		Expect(stopped(deployments)).To(BeFalse())
+
+		watcher.forgetModule(api)
+		Expect(stopped(deployments)).To(BeTrue())
+		Expect(stopped(services)).To(BeFalse())
+		k8sClient.AssertNotCalled(GinkgoT(), "ForgetCluster", mock.Anything)
+
+		watcher.ensureWatches(api, "staging", []cyclopsv1alpha1.GroupVersionResource{deployments})
+		Expect(stopped(deployments)).To(BeFalse())
+		stagingClient.AssertNumberOfCalls(GinkgoT(), "WatchModuleResources", 3)
+	})
+
+	It("drops the client of a cluster no module is deployed to anymore", func() {
+		watcher.ensureWatches(api, "staging", []cyclopsv1alpha1.GroupVersionResource{deployments})
+		watcher.ensureWatches(worker, "staging", []cyclopsv1alpha1.GroupVersionResource{services})
+
+		watcher.ensureWatches(api, "", []cyclopsv1alpha1.GroupVersionResource{deployments})
+		Expect(stopped(deployments)).To(BeFalse())
+		k8sClient.AssertNotCalled(GinkgoT(), "ForgetCluster", mock.Anything)
+
+		watcher.forgetModule(worker)
+		Expect(stopped(services)).To(BeTrue())
+		k8sClient.AssertCalled(GinkgoT(), "ForgetCluster", "staging")
+		k8sClient.AssertNumberOfCalls(GinkgoT(), "ForgetCluster", 1)
+	})
+
+	It("doesn't start watches before the watcher is started", func() {
+		watcher.ctx = nil
+
+		watcher.ensureWatches(api, "staging", []cyclopsv1alpha1.GroupVersionResource{deployments})
+		watcher.forgetModule(api)
+
+		k8sClient.AssertNotCalled(GinkgoT(), "ForCluster", mock.Anything)
+		Expect(watcher.watched).To(BeEmpty())
+	})
+})
@@ -0,0 +1,141 @@
+package modulecontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+)
+
+type syncWave struct {
+	wave    int
+	objects []unstructured.Unstructured
+}
+
+// pendingSyncWave is an applied sync wave whose resources are not healthy yet, so the following waves
+// were not applied
+type pendingSyncWave struct {
+	wave         int
+	health       string
+	healthErrors []string
+}
+
+// syncWaves groups objects by the sync wave annotation, in the order the waves are applied. Objects keep
+// their manifest order within a wave.
+func syncWaves(objects []unstructured.Unstructured) ([]syncWave, []string) {
+	waveErrors := make([]string, 0)
+	byWave := make(map[int][]unstructured.Unstructured)
+
+	for _, obj := range objects {
+		wave := 0
+
+		if value, ok := obj.GetAnnotations()[cyclopsv1alpha1.SyncWaveAnnotation]; ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				waveErrors = append(waveErrors, fmt.Sprintf(
+					"%v%v/%v %v/%v invalid sync wave %v: %v",
+					obj.GroupVersionKind().Group,
+					obj.GroupVersionKind().Version,
+					obj.GroupVersionKind().Kind,
+					obj.GetNamespace(),
+					obj.GetName(),
+					value,
+					err.Error(),
+				))
+				continue
+			}
+
+			wave = parsed
+		}
+
+		byWave[wave] = append(byWave[wave], obj)
+	}
+
+	waves := make([]syncWave, 0, len(byWave))
+	for wave, waveObjects := range byWave {
+		waves = append(waves, syncWave{
+			wave:    wave,
+			objects: waveObjects,
+		})
+	}
+
+	sort.Slice(waves, func(i, j int) bool {
+		return waves[i].wave < waves[j].wave
+	})
+
+	return waves, waveErrors
+}
+
+// setSyncWaveStatus sets the status of a module whose resources were applied up to a sync wave that is not
+// healthy yet. The module is requeued to apply the following waves once it becomes healthy. The time the wave
+// was first applied is kept in the status, so the wave is degraded once it exceeds the progress deadline.
+func (r *ModuleReconciler) setSyncWaveStatus(
+	ctx context.Context,
+	module cyclopsv1alpha1.Module,
+	namespacedName types.NamespacedName,
+	template *models.Template,
+	childrenResources []cyclopsv1alpha1.GroupVersionResource,
+	manifestHash string,
+	pendingWave *pendingSyncWave,
+) (ctrl.Result, error) {
+	status := cyclopsv1alpha1.Progressing
+	conditionReason := cyclopsv1alpha1.ReasonProgressing
+	requeueAfter := progressingRequeueAfter
+
+	startedAt := metav1.Now()
+	if current := module.Status.PendingSyncWave; current != nil &&
+		current.Wave == pendingWave.wave &&
+		current.ManifestHash == manifestHash {
+		startedAt = current.StartedAt
+	}
+
+	module.Status.PendingSyncWave = &cyclopsv1alpha1.SyncWaveStatus{
+		Wave:         pendingWave.wave,
+		ManifestHash: manifestHash,
+		StartedAt:    startedAt,
+	}
+
+	if pendingWave.health == k8sclient.HealthUnhealthy || time.Since(startedAt.Time) > progressDeadline(module) {
+		r.monitor.OnFailedReconciliation()
+
+		status = cyclopsv1alpha1.Degraded
+		conditionReason = cyclopsv1alpha1.ReasonDegraded
+		requeueAfter = degradedRequeueAfter
+	}
+
+	reason := fmt.Sprintf("waiting for resources of sync wave %v to become healthy", pendingWave.wave)
+
+	r.logger.Info("sync wave not healthy",
+		"module namespaced name",
+		module.Name,
+		"sync wave",
+		pendingWave.wave,
+		"health errors",
+		pendingWave.healthErrors,
+	)
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, r.setStatus(
+		ctx,
+		module,
+		namespacedName,
+		status,
+		template.ResolvedVersion,
+		reason,
+		pendingWave.healthErrors,
+		childrenResources,
+		template.IconURL,
+		nil,
+		"",
+		moduleConditions(module.Generation, cyclopsv1alpha1.ConditionApplied, conditionReason, reason),
+	)
+}
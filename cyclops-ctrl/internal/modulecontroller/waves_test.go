@@ -0,0 +1,266 @@
+package modulecontroller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	promclient "github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/prometheus"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+)
+
+var _ = Describe("Sync waves", func() {
+	object := func(name, wave string) unstructured.Unstructured {
+		obj := unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetName(name)
+		if len(wave) != 0 {
+			obj.SetAnnotations(map[string]string{cyclopsv1alpha1.SyncWaveAnnotation: wave})
+		}
+		return obj
+	}
+
+	Describe("syncWaves", func() {
+		type testCase struct {
+			description string
+			objects     []unstructured.Unstructured
+			waves       map[int][]string
+			order       []int
+			errors      int
+		}
+
+		testCases := []testCase{
+			{
+				description: "puts objects without a sync wave into wave 0",
+				objects:     []unstructured.Unstructured{object("a", ""), object("b", "0")},
+				waves:       map[int][]string{0: {"a", "b"}},
+				order:       []int{0},
+			},
+			{
+				description: "orders waves ascending and keeps the manifest order within a wave",
+				objects: []unstructured.Unstructured{
+					object("app", "5"),
+					object("config", ""),
+					object("crd", "-2"),
+					object("worker", "5"),
+					object("migration", "1"),
+				},
+				waves: map[int][]string{
+					-2: {"crd"},
+					0:  {"config"},
+					1:  {"migration"},
+					5:  {"app", "worker"},
+				},
+				order: []int{-2, 0, 1, 5},
+			},
+			{
+				description: "reports objects with invalid sync waves and leaves them out",
+				objects:     []unstructured.Unstructured{object("app", "first"), object("config", "1")},
+				waves:       map[int][]string{1: {"config"}},
+				order:       []int{1},
+				errors:      1,
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				waves, waveErrors := syncWaves(t.objects)
+				Expect(waveErrors).To(HaveLen(t.errors))
+
+				order := make([]int, 0, len(waves))
+				for _, w := range waves {
+					order = append(order, w.wave)
+
+					names := make([]string, 0, len(w.objects))
+					for _, obj := range w.objects {
+						names = append(names, obj.GetName())
+					}
+					Expect(names).To(Equal(t.waves[w.wave]))
+				}
+				Expect(order).To(Equal(t.order))
+			})
+		}
+	})
+
+	Describe("progressDeadlineExceeded", func() {
+		deadlineSeconds := int64(60)
+
+		type testCase struct {
+			description     string
+			syncPolicy      *cyclopsv1alpha1.SyncPolicy
+			appliedHash     string
+			lastAppliedTime *metav1.Time
+			out             bool
+		}
+
+		appliedAgo := func(d time.Duration) *metav1.Time {
+			t := metav1.NewTime(time.Now().Add(-d))
+			return &t
+		}
+
+		testCases := []testCase{
+			{
+				description:     "not exceeded within the default deadline",
+				appliedHash:     "current",
+				lastAppliedTime: appliedAgo(5 * time.Minute),
+				out:             false,
+			},
+			{
+				description:     "exceeded after the default deadline",
+				appliedHash:     "current",
+				lastAppliedTime: appliedAgo(defaultProgressDeadline + time.Minute),
+				out:             true,
+			},
+			{
+				description:     "exceeded after the deadline of the sync policy",
+				syncPolicy:      &cyclopsv1alpha1.SyncPolicy{ProgressDeadlineSeconds: &deadlineSeconds},
+				appliedHash:     "current",
+				lastAppliedTime: appliedAgo(2 * time.Minute),
+				out:             true,
+			},
+			{
+				description:     "not exceeded for a manifest that was not applied yet",
+				appliedHash:     "previous",
+				lastAppliedTime: appliedAgo(defaultProgressDeadline + time.Minute),
+				out:             false,
+			},
+			{
+				description: "not exceeded without an apply time",
+				appliedHash: "current",
+				out:         false,
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				module := cyclopsv1alpha1.Module{
+					Spec: cyclopsv1alpha1.ModuleSpec{SyncPolicy: t.syncPolicy},
+					Status: cyclopsv1alpha1.ModuleStatus{
+						AppliedManifestHash: t.appliedHash,
+						LastAppliedTime:     t.lastAppliedTime,
+					},
+				}
+
+				Expect(progressDeadlineExceeded(module, "current")).To(Equal(t.out))
+			})
+		}
+	})
+
+	Describe("setSyncWaveStatus", func() {
+		namespacedName := types.NamespacedName{Namespace: "team-a", Name: "demo"}
+
+		type testCase struct {
+			description  string
+			pending      *cyclopsv1alpha1.SyncWaveStatus
+			health       string
+			status       cyclopsv1alpha1.ReconciliationStatusState
+			requeueAfter time.Duration
+			keepsStart   bool
+		}
+
+		startedAgo := func(wave int, manifestHash string, d time.Duration) *cyclopsv1alpha1.SyncWaveStatus {
+			return &cyclopsv1alpha1.SyncWaveStatus{
+				Wave:         wave,
+				ManifestHash: manifestHash,
+				StartedAt:    metav1.NewTime(time.Now().Add(-d).Truncate(time.Second)),
+			}
+		}
+
+		testCases := []testCase{
+			{
+				description:  "starts the deadline of a wave applied for the first time",
+				health:       k8sclient.HealthProgressing,
+				status:       cyclopsv1alpha1.Progressing,
+				requeueAfter: progressingRequeueAfter,
+			},
+			{
+				description:  "keeps the start of a wave that is still progressing",
+				pending:      startedAgo(1, "current", time.Minute),
+				health:       k8sclient.HealthProgressing,
+				status:       cyclopsv1alpha1.Progressing,
+				requeueAfter: progressingRequeueAfter,
+				keepsStart:   true,
+			},
+			{
+				description:  "degrades a wave that exceeds the progress deadline",
+				pending:      startedAgo(1, "current", defaultProgressDeadline+time.Minute),
+				health:       k8sclient.HealthProgressing,
+				status:       cyclopsv1alpha1.Degraded,
+				requeueAfter: degradedRequeueAfter,
+				keepsStart:   true,
+			},
+			{
+				description:  "starts the deadline again for a new manifest",
+				pending:      startedAgo(1, "previous", defaultProgressDeadline+time.Minute),
+				health:       k8sclient.HealthProgressing,
+				status:       cyclopsv1alpha1.Progressing,
+				requeueAfter: progressingRequeueAfter,
+			},
+			{
+				description:  "degrades an unhealthy wave right away",
+				health:       k8sclient.HealthUnhealthy,
+				status:       cyclopsv1alpha1.Degraded,
+				requeueAfter: degradedRequeueAfter,
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				module := &cyclopsv1alpha1.Module{
+					ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "team-a"},
+					Status:     cyclopsv1alpha1.ModuleStatus{PendingSyncWave: t.pending},
+				}
+
+				reconciler := &ModuleReconciler{
+					Client: fake.NewClientBuilder().
+						WithScheme(scheme.Scheme).
+						WithObjects(module).
+						WithStatusSubresource(module).
+						Build(),
+					Scheme:          scheme.Scheme,
+					resourceWatcher: newResourceWatcher(nil, logr.Discard()),
+					monitor: prometheus.Monitor{
+						FailedReconciliationCounter: promclient.NewCounter(promclient.CounterOpts{Name: "failed_reconciliations"}),
+					},
+					logger: logr.Discard(),
+				}
+
+				result, err := reconciler.setSyncWaveStatus(
+					context.Background(),
+					*module,
+					namespacedName,
+					&models.Template{},
+					nil,
+					"current",
+					&pendingSyncWave{wave: 1, health: t.health},
+				)
+				Expect(err).To(BeNil())
+				Expect(result.RequeueAfter).To(Equal(t.requeueAfter))
+
+				var updated cyclopsv1alpha1.Module
+				Expect(reconciler.Get(context.Background(), namespacedName, &updated)).To(Succeed())
+				Expect(updated.Status.ReconciliationStatus.Status).To(Equal(t.status))
+				Expect(updated.Status.PendingSyncWave.Wave).To(Equal(1))
+				Expect(updated.Status.PendingSyncWave.ManifestHash).To(Equal("current"))
+
+				if t.keepsStart {
+					Expect(updated.Status.PendingSyncWave.StartedAt.Time).To(BeTemporally("==", t.pending.StartedAt.Time))
+				} else {
+					Expect(updated.Status.PendingSyncWave.StartedAt.Time).To(BeTemporally("~", time.Now(), 5*time.Second))
+				}
+			})
+		}
+	})
+})
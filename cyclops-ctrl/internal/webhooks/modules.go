@@ -0,0 +1,242 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	templaterepo "github.com/andersan81/cyclops/cyclops-ctrl/pkg/template"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/template/render"
+)
+
+// templateValidationTimeout bounds fetching and rendering module templates so admission requests are answered
+// before the API server webhook timeout of 10 seconds
+const templateValidationTimeout = 8 * time.Second
+
+//+kubebuilder:webhook:path=/mutate-cyclops-ui-com-v1alpha1-module,mutating=true,failurePolicy=fail,sideEffects=None,groups=cyclops-ui.com,resources=modules,verbs=create;update,versions=v1alpha1,name=mmodule.cyclops-ui.com,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-cyclops-ui-com-v1alpha1-module,mutating=false,failurePolicy=fail,sideEffects=None,groups=cyclops-ui.com,resources=modules,verbs=create;update,versions=v1alpha1,name=vmodule.cyclops-ui.com,admissionReviewVersions=v1
+
+// ModuleDefaulter fills the template source type and the target namespace of modules, so modules applied to the
+// cluster directly end up with the same spec as modules created from the UI
+type ModuleDefaulter struct {
+	moduleTargetNamespace string
+	assumeSourceType      func(repo string) (cyclopsv1alpha1.TemplateSourceType, error)
+}
+
+func NewModuleDefaulter(templatesRepo templaterepo.ITemplateRepo, moduleTargetNamespace string) *ModuleDefaulter {
+	return &ModuleDefaulter{
+		moduleTargetNamespace: moduleTargetNamespace,
+		assumeSourceType:      templatesRepo.AssumeTemplateSourceType,
+	}
+}
+
+func (d *ModuleDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	module, ok := obj.(*cyclopsv1alpha1.Module)
+	if !ok {
+		return fmt.Errorf("expected a Module but got %T", obj)
+	}
+
+	defaultSourceType(&module.Spec.TemplateRef, d.assumeSourceType)
+
+	if len(module.Spec.TargetNamespace) == 0 {
+		module.Spec.TargetNamespace = defaultTargetNamespace(d.moduleTargetNamespace)
+	}
+
+	return nil
+}
+
+func defaultTargetNamespace(moduleTargetNamespace string) string {
+	if len(moduleTargetNamespace) == 0 {
+		return apiv1.NamespaceDefault
+	}
+
+	return moduleTargetNamespace
+}
+
+// ModuleValidator rejects modules with templates that can't be fetched, values that don't match the template
+// values schema, and updates of fields that can't change after the module is created
+type ModuleValidator struct {
+	templatesRepo         templaterepo.ITemplateRepo
+	renderer              *render.Renderer
+	moduleTargetNamespace string
+	templateTimeout       time.Duration
+}
+
+func NewModuleValidator(templatesRepo templaterepo.ITemplateRepo, renderer *render.Renderer, moduleTargetNamespace string) *ModuleValidator {
+	return &ModuleValidator{
+		templatesRepo:         templatesRepo,
+		renderer:              renderer,
+		moduleTargetNamespace: moduleTargetNamespace,
+		templateTimeout:       templateValidationTimeout,
+	}
+}
+
+func (v *ModuleValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	module, ok := obj.(*cyclopsv1alpha1.Module)
+	if !ok {
+		return nil, fmt.Errorf("expected a Module but got %T", obj)
+	}
+
+	warnings, errs := v.validateTemplate(ctx, module, "")
+
+	return warnings, invalid("Module", module.Name, errs)
+}
+
+func (v *ModuleValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldModule, ok := oldObj.(*cyclopsv1alpha1.Module)
+	if !ok {
+		return nil, fmt.Errorf("expected a Module but got %T", oldObj)
+	}
+
+	module, ok := newObj.(*cyclopsv1alpha1.Module)
+	if !ok {
+		return nil, fmt.Errorf("expected a Module but got %T", newObj)
+	}
+
+	// the controller updates finalizers and annotations of modules being deleted or with templates that became
+	// unavailable, which must not be blocked
+	if module.DeletionTimestamp != nil || reflect.DeepEqual(oldModule.Spec, module.Spec) {
+		return nil, nil
+	}
+
+	errs := validateImmutableFields(oldModule, module, v.moduleTargetNamespace)
+	if len(errs) != 0 {
+		return nil, invalid("Module", module.Name, errs)
+	}
+
+	// the resolved version in the status belongs to the old template reference, a changed reference is resolved
+	// again the same way the controller resolves it
+	resolvedVersion := ""
+	if reflect.DeepEqual(oldModule.Spec.TemplateRef, module.Spec.TemplateRef) {
+		resolvedVersion = module.Status.TemplateResolvedVersion
+	}
+
+	warnings, errs := v.validateTemplate(ctx, module, resolvedVersion)
+
+	return warnings, invalid("Module", module.Name, errs)
+}
+
+func (v *ModuleValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+type templateValidation struct {
+	warnings admission.Warnings
+	errs     field.ErrorList
+}
+
+// validateTemplate validates module values against its template within the template timeout. Slow template
+// repositories must not fail admission, so modules are only warned about if their template is not validated in time.
+func (v *ModuleValidator) validateTemplate(ctx context.Context, module *cyclopsv1alpha1.Module, resolvedVersion string) (admission.Warnings, field.ErrorList) {
+	ctx, cancel := context.WithTimeout(ctx, v.templateTimeout)
+	defer cancel()
+
+	// the template fetch can't be canceled, it completes in the background and fills the templates cache
+	validation := make(chan templateValidation, 1)
+	go func(module *cyclopsv1alpha1.Module) {
+		warnings, errs := v.fetchAndValidateTemplate(module, resolvedVersion)
+		validation <- templateValidation{warnings: warnings, errs: errs}
+	}(module.DeepCopy())
+
+	select {
+	case result := <-validation:
+		return result.warnings, result.errs
+	case <-ctx.Done():
+		return admission.Warnings{fmt.Sprintf("module values not validated: template not fetched within %v", v.templateTimeout)}, nil
+	}
+}
+
+// fetchAndValidateTemplate fetches the module template at resolvedVersion, or at the version from the template
+// reference if it is empty, and validates module values against it. Values that can't be validated because
+// referenced ConfigMaps or Secrets are missing are only warned about, since they can be created after the module.
+func (v *ModuleValidator) fetchAndValidateTemplate(module *cyclopsv1alpha1.Module, resolvedVersion string) (admission.Warnings, field.ErrorList) {
+	templatePath := field.NewPath("spec", "template")
+
+	templateVersion := resolvedVersion
+	if len(templateVersion) == 0 {
+		templateVersion = module.Spec.TemplateRef.Version
+	}
+
+	moduleTemplate, err := v.templatesRepo.GetTemplate(
+		module.Spec.TemplateRef.URL,
+		module.Spec.TemplateRef.Path,
+		templateVersion,
+		resolvedVersion,
+		module.Spec.TemplateRef.SourceType,
+	)
+	if err != nil {
+		return nil, field.ErrorList{
+			field.Invalid(templatePath, module.Spec.TemplateRef.URL, fmt.Sprintf("failed to fetch template: %v", err)),
+		}
+	}
+
+	err = v.renderer.ValidateValues(*module, moduleTemplate)
+	if validationErr, ok := err.(*render.ValuesValidationError); ok {
+		errs := make(field.ErrorList, 0, len(validationErr.Fields))
+		for _, fieldErr := range validationErr.Fields {
+			errs = append(errs, &field.Error{
+				Type:   field.ErrorTypeInvalid,
+				Field:  field.NewPath("spec", "values").Child(fieldErr.Field).String(),
+				Detail: fieldErr.Message,
+			})
+		}
+
+		return nil, errs
+	}
+	if err != nil {
+		return admission.Warnings{fmt.Sprintf("module values not validated: %v", err)}, nil
+	}
+
+	return nil, nil
+}
+
+// validateImmutableFields rejects changes of the target namespace and cluster, which would leave resources of
+// the module behind, and of the template source type, which is set once by the defaulting webhook
+func validateImmutableFields(oldModule, module *cyclopsv1alpha1.Module, moduleTargetNamespace string) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if targetNamespaceChanged(oldModule, module, moduleTargetNamespace) {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "targetNamespace"), module.Spec.TargetNamespace, "field is immutable"))
+	}
+
+	if oldModule.Spec.Cluster != module.Spec.Cluster {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "cluster"), module.Spec.Cluster, "field is immutable"))
+	}
+
+	if len(oldModule.Spec.TemplateRef.SourceType) != 0 && oldModule.Spec.TemplateRef.SourceType != module.Spec.TemplateRef.SourceType {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "template", "sourceType"), module.Spec.TemplateRef.SourceType, "field is immutable"))
+	}
+
+	return errs
+}
+
+// targetNamespaceChanged checks if the target namespace of the module changed. Modules created before the
+// defaulting webhook have an empty target namespace, which is filled by the defaulting webhook on their next update.
+func targetNamespaceChanged(oldModule, module *cyclopsv1alpha1.Module, moduleTargetNamespace string) bool {
+	if oldModule.Spec.TargetNamespace == module.Spec.TargetNamespace {
+		return false
+	}
+
+	if len(oldModule.Spec.TargetNamespace) != 0 {
+		return true
+	}
+
+	return module.Spec.TargetNamespace != apiv1.NamespaceDefault &&
+		module.Spec.TargetNamespace != defaultTargetNamespace(moduleTargetNamespace)
+}
+
+func invalid(kind, name string, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(cyclopsv1alpha1.GroupVersion.WithKind(kind).GroupKind(), name, errs)
+}
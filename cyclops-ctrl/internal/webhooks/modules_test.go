@@ -0,0 +1,206 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	apiv1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/helm"
+	"github.com/andersan81/cyclops/cyclops-ctrl/mocks"
+	k8smocks "github.com/andersan81/cyclops/cyclops-ctrl/pkg/mocks"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/template/render"
+)
+
+func TestWebhooks(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test admission webhooks")
+}
+
+var _ = Describe("Module webhooks", func() {
+	var templatesRepo *mocks.ITemplateRepo
+	var validator *ModuleValidator
+
+	module := func(targetNamespace, values string) *v1alpha1.Module {
+		return &v1alpha1.Module{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "cyclops"},
+			Spec: v1alpha1.ModuleSpec{
+				TargetNamespace: targetNamespace,
+				TemplateRef: v1alpha1.TemplateRef{
+					URL:        "https://github.com/cyclops-ui/templates",
+					Path:       "demo",
+					Version:    "main",
+					SourceType: v1alpha1.TemplateSourceTypeGit,
+				},
+				Values: apiextensionsv1.JSON{Raw: []byte(values)},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		templatesRepo = &mocks.ITemplateRepo{}
+		validator = NewModuleValidator(templatesRepo, render.NewRenderer(&k8smocks.IKubernetesClient{}, nil), "apps")
+
+		templatesRepo.On("GetTemplate", "https://github.com/cyclops-ui/templates", "demo", "main", "", v1alpha1.TemplateSourceTypeGit).Return(&models.Template{
+			HelmChartMetadata: &helm.Metadata{Name: "demo"},
+			RawSchema:         []byte(`{"type": "object", "properties": {"replicas": {"type": "integer"}}}`),
+		}, nil)
+	})
+
+	It("defaults the template source type and the target namespace", func() {
+		templatesRepo.On("AssumeTemplateSourceType", "https://github.com/cyclops-ui/templates").Return(v1alpha1.TemplateSourceTypeHelm, nil)
+		defaulter := NewModuleDefaulter(templatesRepo, "apps")
+
+		m := module("", `{}`)
+		m.Spec.TemplateRef.SourceType = ""
+
+		Expect(defaulter.Default(context.Background(), m)).To(BeNil())
+		Expect(m.Spec.TemplateRef.SourceType).To(BeEquivalentTo(v1alpha1.TemplateSourceTypeHelm))
+		Expect(m.Spec.TargetNamespace).To(BeEquivalentTo("apps"))
+	})
+
+	It("rejects values that don't match the template values schema", func() {
+		_, err := validator.ValidateCreate(context.Background(), module("apps", `{"replicas": "three"}`))
+
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.values.replicas"))
+	})
+
+	It("rejects changing the target namespace", func() {
+		_, err := validator.ValidateUpdate(context.Background(), module("apps", `{}`), module("other", `{}`))
+
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.targetNamespace"))
+		templatesRepo.AssertNotCalled(GinkgoT(), "GetTemplate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	It("allows the defaulted target namespace of modules without one", func() {
+		_, err := validator.ValidateUpdate(context.Background(), module("", `{}`), module("apps", `{"replicas": 3}`))
+
+		Expect(err).To(BeNil())
+	})
+
+	It("rejects setting another target namespace on modules without one", func() {
+		_, err := validator.ValidateUpdate(context.Background(), module("", `{}`), module("other", `{}`))
+
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.targetNamespace"))
+	})
+
+	It("warns instead of failing if the template is not fetched in time", func() {
+		validator.templateTimeout = 10 * time.Millisecond
+
+		templatesRepo.On("GetTemplate", "https://github.com/cyclops-ui/templates", "demo", "slow", "", v1alpha1.TemplateSourceTypeGit).
+			After(time.Second).
+			Return(&models.Template{HelmChartMetadata: &helm.Metadata{Name: "demo"}}, nil)
+
+		m := module("apps", `{"replicas": "three"}`)
+		m.Spec.TemplateRef.Version = "slow"
+
+		warnings, err := validator.ValidateCreate(context.Background(), m)
+
+		Expect(err).To(BeNil())
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0]).To(ContainSubstring("template not fetched within 10ms"))
+	})
+
+	It("allows updates of valid values", func() {
+		_, err := validator.ValidateUpdate(context.Background(), module("apps", `{}`), module("apps", `{"replicas": 3}`))
+
+		Expect(err).To(BeNil())
+	})
+
+	It("validates values against the new template version on a version bump", func() {
+		templatesRepo.On("GetTemplate", "https://github.com/cyclops-ui/templates", "demo", "v2", "", v1alpha1.TemplateSourceTypeGit).Return(&models.Template{
+			HelmChartMetadata: &helm.Metadata{Name: "demo"},
+			RawSchema:         []byte(`{"type": "object", "properties": {"replicas": {"type": "string"}}}`),
+		}, nil)
+
+		oldModule := module("apps", `{"replicas": 3}`)
+		oldModule.Status.TemplateResolvedVersion = "5d2a1f4"
+
+		newModule := oldModule.DeepCopy()
+		newModule.Spec.TemplateRef.Version = "v2"
+
+		_, err := validator.ValidateUpdate(context.Background(), oldModule, newModule)
+
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.values.replicas"))
+		templatesRepo.AssertNotCalled(GinkgoT(), "GetTemplate", mock.Anything, mock.Anything, "5d2a1f4", mock.Anything, mock.Anything)
+	})
+
+	It("validates values against the resolved template version if the template did not change", func() {
+		templatesRepo.On("GetTemplate", "https://github.com/cyclops-ui/templates", "demo", "5d2a1f4", "5d2a1f4", v1alpha1.TemplateSourceTypeGit).Return(&models.Template{
+			HelmChartMetadata: &helm.Metadata{Name: "demo"},
+			RawSchema:         []byte(`{"type": "object", "properties": {"replicas": {"type": "integer"}}}`),
+		}, nil)
+
+		oldModule := module("apps", `{"replicas": 3}`)
+		oldModule.Status.TemplateResolvedVersion = "5d2a1f4"
+
+		newModule := oldModule.DeepCopy()
+		newModule.Spec.Values = apiextensionsv1.JSON{Raw: []byte(`{"replicas": 4}`)}
+
+		_, err := validator.ValidateUpdate(context.Background(), oldModule, newModule)
+
+		Expect(err).To(BeNil())
+		templatesRepo.AssertCalled(GinkgoT(), "GetTemplate", "https://github.com/cyclops-ui/templates", "demo", "5d2a1f4", "5d2a1f4", v1alpha1.TemplateSourceTypeGit)
+	})
+})
+
+var _ = Describe("TemplateAuthRule webhooks", func() {
+	It("rejects repo patterns that don't compile", func() {
+		_, err := NewTemplateAuthRuleValidator().ValidateCreate(context.Background(), &v1alpha1.TemplateAuthRule{
+			ObjectMeta: metav1.ObjectMeta{Name: "private"},
+			Spec:       v1alpha1.TemplateAuthRuleSpec{Repo: "https://github.com/(cyclops"},
+		})
+
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.repo"))
+	})
+
+	It("rejects rules without username and password or an SSH private key", func() {
+		_, err := NewTemplateAuthRuleValidator().ValidateCreate(context.Background(), &v1alpha1.TemplateAuthRule{
+			ObjectMeta: metav1.ObjectMeta{Name: "private"},
+			Spec: v1alpha1.TemplateAuthRuleSpec{
+				Repo:     "https://github.com/cyclops-ui/.*",
+				Password: apiv1.SecretKeySelector{LocalObjectReference: apiv1.LocalObjectReference{Name: "github"}, Key: "token"},
+			},
+		})
+
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.username"))
+	})
+
+	It("accepts rules with username and password or an SSH private key", func() {
+		validator := NewTemplateAuthRuleValidator()
+
+		_, err := validator.ValidateCreate(context.Background(), &v1alpha1.TemplateAuthRule{
+			ObjectMeta: metav1.ObjectMeta{Name: "private"},
+			Spec: v1alpha1.TemplateAuthRuleSpec{
+				Repo:     "https://github.com/cyclops-ui/.*",
+				Username: apiv1.SecretKeySelector{LocalObjectReference: apiv1.LocalObjectReference{Name: "github"}, Key: "username"},
+				Password: apiv1.SecretKeySelector{LocalObjectReference: apiv1.LocalObjectReference{Name: "github"}, Key: "token"},
+			},
+		})
+		Expect(err).To(BeNil())
+
+		_, err = validator.ValidateCreate(context.Background(), &v1alpha1.TemplateAuthRule{
+			ObjectMeta: metav1.ObjectMeta{Name: "private"},
+			Spec: v1alpha1.TemplateAuthRuleSpec{
+				Repo:          "git@github.com:cyclops-ui/.*",
+				SSHPrivateKey: &apiv1.SecretKeySelector{LocalObjectReference: apiv1.LocalObjectReference{Name: "deploy-key"}, Key: "ssh-privatekey"},
+			},
+		})
+		Expect(err).To(BeNil())
+	})
+})
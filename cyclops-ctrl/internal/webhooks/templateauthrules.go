@@ -0,0 +1,69 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+//+kubebuilder:webhook:path=/validate-cyclops-ui-com-v1alpha1-templateauthrule,mutating=false,failurePolicy=fail,sideEffects=None,groups=cyclops-ui.com,resources=templateauthrules,verbs=create;update,versions=v1alpha1,name=vtemplateauthrule.cyclops-ui.com,admissionReviewVersions=v1
+
+// TemplateAuthRuleValidator rejects template auth rules with repo patterns that don't compile, which would
+// otherwise never match any repo, and rules without username and password or an SSH private key
+type TemplateAuthRuleValidator struct{}
+
+func NewTemplateAuthRuleValidator() *TemplateAuthRuleValidator {
+	return &TemplateAuthRuleValidator{}
+}
+
+func (v *TemplateAuthRuleValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validateTemplateAuthRule(obj)
+}
+
+func (v *TemplateAuthRuleValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, validateTemplateAuthRule(newObj)
+}
+
+func (v *TemplateAuthRuleValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateTemplateAuthRule(obj runtime.Object) error {
+	rule, ok := obj.(*cyclopsv1alpha1.TemplateAuthRule)
+	if !ok {
+		return fmt.Errorf("expected a TemplateAuthRule but got %T", obj)
+	}
+
+	var errs field.ErrorList
+
+	if _, err := regexp.Compile(rule.Spec.Repo); err != nil {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "repo"), rule.Spec.Repo, err.Error()))
+	}
+
+	specPath := field.NewPath("spec")
+	switch {
+	case rule.Spec.SSHPrivateKey != nil:
+		if len(rule.Spec.SSHPrivateKey.Name) == 0 {
+			errs = append(errs, field.Required(specPath.Child("sshPrivateKey", "name"), "secret name is required"))
+		}
+	case len(rule.Spec.Username.Name) == 0 || len(rule.Spec.Password.Name) == 0:
+		if len(rule.Spec.Username.Name) == 0 {
+			errs = append(errs, field.Required(specPath.Child("username"), "username and password or an SSH private key are required"))
+		}
+		if len(rule.Spec.Password.Name) == 0 {
+			errs = append(errs, field.Required(specPath.Child("password"), "username and password or an SSH private key are required"))
+		}
+	}
+
+	if len(errs) != 0 {
+		return invalid("TemplateAuthRule", rule.Name, errs)
+	}
+
+	return nil
+}
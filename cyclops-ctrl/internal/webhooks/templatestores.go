@@ -0,0 +1,92 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	templaterepo "github.com/andersan81/cyclops/cyclops-ctrl/pkg/template"
+)
+
+//+kubebuilder:webhook:path=/mutate-cyclops-ui-com-v1alpha1-templatestore,mutating=true,failurePolicy=fail,sideEffects=None,groups=cyclops-ui.com,resources=templatestores,verbs=create;update,versions=v1alpha1,name=mtemplatestore.cyclops-ui.com,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-cyclops-ui-com-v1alpha1-templatestore,mutating=false,failurePolicy=fail,sideEffects=None,groups=cyclops-ui.com,resources=templatestores,verbs=create;update,versions=v1alpha1,name=vtemplatestore.cyclops-ui.com,admissionReviewVersions=v1
+
+// TemplateStoreDefaulter fills the source type of template store references
+type TemplateStoreDefaulter struct {
+	assumeSourceType func(repo string) (cyclopsv1alpha1.TemplateSourceType, error)
+}
+
+func NewTemplateStoreDefaulter(templatesRepo templaterepo.ITemplateRepo) *TemplateStoreDefaulter {
+	return &TemplateStoreDefaulter{
+		assumeSourceType: templatesRepo.AssumeTemplateSourceType,
+	}
+}
+
+func (d *TemplateStoreDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	templateStore, ok := obj.(*cyclopsv1alpha1.TemplateStore)
+	if !ok {
+		return fmt.Errorf("expected a TemplateStore but got %T", obj)
+	}
+
+	defaultSourceType(&templateStore.Spec, d.assumeSourceType)
+
+	return nil
+}
+
+// TemplateStoreValidator rejects template stores referencing templates that can't be fetched
+type TemplateStoreValidator struct {
+	templatesRepo templaterepo.ITemplateRepo
+}
+
+func NewTemplateStoreValidator(templatesRepo templaterepo.ITemplateRepo) *TemplateStoreValidator {
+	return &TemplateStoreValidator{
+		templatesRepo: templatesRepo,
+	}
+}
+
+func (v *TemplateStoreValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	templateStore, ok := obj.(*cyclopsv1alpha1.TemplateStore)
+	if !ok {
+		return nil, fmt.Errorf("expected a TemplateStore but got %T", obj)
+	}
+
+	return nil, v.validate(templateStore)
+}
+
+func (v *TemplateStoreValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	templateStore, ok := newObj.(*cyclopsv1alpha1.TemplateStore)
+	if !ok {
+		return nil, fmt.Errorf("expected a TemplateStore but got %T", newObj)
+	}
+
+	if templateStore.DeletionTimestamp != nil {
+		return nil, nil
+	}
+
+	return nil, v.validate(templateStore)
+}
+
+func (v *TemplateStoreValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *TemplateStoreValidator) validate(templateStore *cyclopsv1alpha1.TemplateStore) error {
+	_, err := v.templatesRepo.GetTemplate(
+		templateStore.Spec.URL,
+		templateStore.Spec.Path,
+		templateStore.Spec.Version,
+		"",
+		templateStore.Spec.SourceType,
+	)
+	if err != nil {
+		return invalid("TemplateStore", templateStore.Name, field.ErrorList{
+			field.Invalid(field.NewPath("spec"), templateStore.Spec.URL, fmt.Sprintf("failed to fetch template: %v", err)),
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package webhooks
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	templaterepo "github.com/andersan81/cyclops/cyclops-ctrl/pkg/template"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/template/render"
+)
+
+// SetupWithManager registers validating and defaulting webhooks of Cyclops custom resources with the webhook
+// server of the manager
+func SetupWithManager(
+	mgr ctrl.Manager,
+	templatesRepo templaterepo.ITemplateRepo,
+	renderer *render.Renderer,
+	moduleTargetNamespace string,
+) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&cyclopsv1alpha1.Module{}).
+		WithDefaulter(NewModuleDefaulter(templatesRepo, moduleTargetNamespace)).
+		WithValidator(NewModuleValidator(templatesRepo, renderer, moduleTargetNamespace)).
+		Complete(); err != nil {
+		return err
+	}
+
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&cyclopsv1alpha1.TemplateStore{}).
+		WithDefaulter(NewTemplateStoreDefaulter(templatesRepo)).
+		WithValidator(NewTemplateStoreValidator(templatesRepo)).
+		Complete(); err != nil {
+		return err
+	}
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&cyclopsv1alpha1.TemplateAuthRule{}).
+		WithValidator(NewTemplateAuthRuleValidator()).
+		Complete()
+}
+
+// defaultSourceType sets the source type of template references without one. The source type is left empty if
+// it can't be determined, and is assumed again when the template is fetched.
+func defaultSourceType(
+	templateRef *cyclopsv1alpha1.TemplateRef,
+	assumeSourceType func(repo string) (cyclopsv1alpha1.TemplateSourceType, error),
+) {
+	if len(templateRef.SourceType) != 0 {
+		return
+	}
+
+	sourceType, err := assumeSourceType(templateRef.URL)
+	if err != nil {
+		return
+	}
+
+	templateRef.SourceType = sourceType
+}
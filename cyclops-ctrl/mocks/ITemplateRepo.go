@@ -23,6 +23,62 @@ func (_m *ITemplateRepo) EXPECT() *ITemplateRepo_Expecter {
 	return &ITemplateRepo_Expecter{mock: &_m.Mock}
 }
 
+// AssumeTemplateSourceType provides a mock function with given fields: repo
+func (_m *ITemplateRepo) AssumeTemplateSourceType(repo string) (v1alpha1.TemplateSourceType, error) {
+	ret := _m.Called(repo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AssumeTemplateSourceType")
+	}
+
+	var r0 v1alpha1.TemplateSourceType
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (v1alpha1.TemplateSourceType, error)); ok {
+		return rf(repo)
+	}
+	if rf, ok := ret.Get(0).(func(string) v1alpha1.TemplateSourceType); ok {
+		r0 = rf(repo)
+	} else {
+		r0 = ret.Get(0).(v1alpha1.TemplateSourceType)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ITemplateRepo_AssumeTemplateSourceType_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AssumeTemplateSourceType'
+type ITemplateRepo_AssumeTemplateSourceType_Call struct {
+	*mock.Call
+}
+
+// AssumeTemplateSourceType is a helper method to define mock.On call
+//   - repo string
+func (_e *ITemplateRepo_Expecter) AssumeTemplateSourceType(repo interface{}) *ITemplateRepo_AssumeTemplateSourceType_Call {
+	return &ITemplateRepo_AssumeTemplateSourceType_Call{Call: _e.mock.On("AssumeTemplateSourceType", repo)}
+}
+
+func (_c *ITemplateRepo_AssumeTemplateSourceType_Call) Run(run func(repo string)) *ITemplateRepo_AssumeTemplateSourceType_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *ITemplateRepo_AssumeTemplateSourceType_Call) Return(_a0 v1alpha1.TemplateSourceType, _a1 error) *ITemplateRepo_AssumeTemplateSourceType_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ITemplateRepo_AssumeTemplateSourceType_Call) RunAndReturn(run func(string) (v1alpha1.TemplateSourceType, error)) *ITemplateRepo_AssumeTemplateSourceType_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetTemplate provides a mock function with given fields: repo, path, version, resolvedVersion, source
 func (_m *ITemplateRepo) GetTemplate(repo string, path string, version string, resolvedVersion string, source v1alpha1.TemplateSourceType) (*models.Template, error) {
 	ret := _m.Called(repo, path, version, resolvedVersion, source)
@@ -146,29 +202,29 @@ func (_c *ITemplateRepo_GetTemplateInitialValues_Call) RunAndReturn(run func(str
 	return _c
 }
 
-// GetTemplateRevisions provides a mock function with given fields: repo, path
-func (_m *ITemplateRepo) GetTemplateRevisions(repo string, path string) ([]string, error) {
-	ret := _m.Called(repo, path)
+// GetTemplateRevisions provides a mock function with given fields: repo, path, source, includePrerelease
+func (_m *ITemplateRepo) GetTemplateRevisions(repo string, path string, source v1alpha1.TemplateSourceType, includePrerelease bool) ([]models.TemplateRevision, error) {
+	ret := _m.Called(repo, path, source, includePrerelease)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetTemplateRevisions")
 	}
 
-	var r0 []string
+	var r0 []models.TemplateRevision
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) ([]string, error)); ok {
-		return rf(repo, path)
+	if rf, ok := ret.Get(0).(func(string, string, v1alpha1.TemplateSourceType, bool) ([]models.TemplateRevision, error)); ok {
+		return rf(repo, path, source, includePrerelease)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) []string); ok {
-		r0 = rf(repo, path)
+	if rf, ok := ret.Get(0).(func(string, string, v1alpha1.TemplateSourceType, bool) []models.TemplateRevision); ok {
+		r0 = rf(repo, path, source, includePrerelease)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]string)
+			r0 = ret.Get(0).([]models.TemplateRevision)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(repo, path)
+	if rf, ok := ret.Get(1).(func(string, string, v1alpha1.TemplateSourceType, bool) error); ok {
+		r1 = rf(repo, path, source, includePrerelease)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -184,23 +240,84 @@ type ITemplateRepo_GetTemplateRevisions_Call struct {
 // GetTemplateRevisions is a helper method to define mock.On call
 //   - repo string
 //   - path string
-func (_e *ITemplateRepo_Expecter) GetTemplateRevisions(repo interface{}, path interface{}) *ITemplateRepo_GetTemplateRevisions_Call {
-	return &ITemplateRepo_GetTemplateRevisions_Call{Call: _e.mock.On("GetTemplateRevisions", repo, path)}
+//   - source v1alpha1.TemplateSourceType
+//   - includePrerelease bool
+func (_e *ITemplateRepo_Expecter) GetTemplateRevisions(repo interface{}, path interface{}, source interface{}, includePrerelease interface{}) *ITemplateRepo_GetTemplateRevisions_Call {
+	return &ITemplateRepo_GetTemplateRevisions_Call{Call: _e.mock.On("GetTemplateRevisions", repo, path, source, includePrerelease)}
+}
+
+func (_c *ITemplateRepo_GetTemplateRevisions_Call) Run(run func(repo string, path string, source v1alpha1.TemplateSourceType, includePrerelease bool)) *ITemplateRepo_GetTemplateRevisions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(v1alpha1.TemplateSourceType), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *ITemplateRepo_GetTemplateRevisions_Call) Return(_a0 []models.TemplateRevision, _a1 error) *ITemplateRepo_GetTemplateRevisions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ITemplateRepo_GetTemplateRevisions_Call) RunAndReturn(run func(string, string, v1alpha1.TemplateSourceType, bool) ([]models.TemplateRevision, error)) *ITemplateRepo_GetTemplateRevisions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResolveVersion provides a mock function with given fields: repo, path, version, source
+func (_m *ITemplateRepo) ResolveVersion(repo string, path string, version string, source v1alpha1.TemplateSourceType) (string, error) {
+	ret := _m.Called(repo, path, version, source)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveVersion")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, v1alpha1.TemplateSourceType) (string, error)); ok {
+		return rf(repo, path, version, source)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, v1alpha1.TemplateSourceType) string); ok {
+		r0 = rf(repo, path, version, source)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, v1alpha1.TemplateSourceType) error); ok {
+		r1 = rf(repo, path, version, source)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ITemplateRepo_ResolveVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResolveVersion'
+type ITemplateRepo_ResolveVersion_Call struct {
+	*mock.Call
+}
+
+// ResolveVersion is a helper method to define mock.On call
+//   - repo string
+//   - path string
+//   - version string
+//   - source v1alpha1.TemplateSourceType
+func (_e *ITemplateRepo_Expecter) ResolveVersion(repo interface{}, path interface{}, version interface{}, source interface{}) *ITemplateRepo_ResolveVersion_Call {
+	return &ITemplateRepo_ResolveVersion_Call{Call: _e.mock.On("ResolveVersion", repo, path, version, source)}
 }
 
-func (_c *ITemplateRepo_GetTemplateRevisions_Call) Run(run func(repo string, path string)) *ITemplateRepo_GetTemplateRevisions_Call {
+func (_c *ITemplateRepo_ResolveVersion_Call) Run(run func(repo string, path string, version string, source v1alpha1.TemplateSourceType)) *ITemplateRepo_ResolveVersion_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(string), args[1].(string), args[2].(string), args[3].(v1alpha1.TemplateSourceType))
 	})
 	return _c
 }
 
-func (_c *ITemplateRepo_GetTemplateRevisions_Call) Return(_a0 []string, _a1 error) *ITemplateRepo_GetTemplateRevisions_Call {
+func (_c *ITemplateRepo_ResolveVersion_Call) Return(_a0 string, _a1 error) *ITemplateRepo_ResolveVersion_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *ITemplateRepo_GetTemplateRevisions_Call) RunAndReturn(run func(string, string) ([]string, error)) *ITemplateRepo_GetTemplateRevisions_Call {
+func (_c *ITemplateRepo_ResolveVersion_Call) RunAndReturn(run func(string, string, string, v1alpha1.TemplateSourceType) (string, error)) *ITemplateRepo_ResolveVersion_Call {
 	_c.Call.Return(run)
 	return _c
 }
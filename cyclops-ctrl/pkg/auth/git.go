@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/pkg/errors"
+)
+
+// GitAuth returns the URL and the auth method used to access the git repo with the credentials. Repos with SSH
+// URLs, like git@github.com:org/repo.git, are accessed with the SSH private key of the credentials. Repos with
+// HTTP URLs are accessed with basic auth, or over SSH if the credentials have an SSH private key but no username
+// and password.
+func GitAuth(repoURL string, creds *Credentials) (string, transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if endpoint.Protocol != "ssh" {
+		if creds == nil {
+			return repoURL, nil, nil
+		}
+
+		if !creds.SSHOnly() {
+			return repoURL, &http.BasicAuth{
+				Username: creds.Username,
+				Password: creds.Password,
+			}, nil
+		}
+
+		repoURL = sshRepoURL(endpoint)
+		endpoint, err = transport.NewEndpoint(repoURL)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	if creds == nil || len(creds.SSHPrivateKey) == 0 {
+		return "", nil, errors.New(fmt.Sprintf("SSH private key required for repo %v: check template auth rules", repoURL))
+	}
+
+	user := endpoint.User
+	if len(user) == 0 {
+		user = "git"
+	}
+
+	publicKeys, err := gitssh.NewPublicKeys(user, []byte(creds.SSHPrivateKey), "")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to parse SSH private key")
+	}
+
+	if len(creds.SSHKnownHosts) != 0 {
+		if err := setKnownHosts(publicKeys, creds.SSHKnownHosts); err != nil {
+			return "", nil, errors.Wrap(err, "failed to parse SSH known hosts")
+		}
+	}
+
+	return repoURL, publicKeys, nil
+}
+
+// sshRepoURL returns the SSH URL of the repo with the HTTP endpoint. Azure DevOps serves repos over SSH on a
+// different host and path, like git@ssh.dev.azure.com:v3/org/project/repo, while other git servers serve them
+// on the same host and path.
+func sshRepoURL(endpoint *transport.Endpoint) string {
+	parts := strings.Split(strings.Trim(endpoint.Path, "/"), "/")
+	if len(parts) > 0 && strings.EqualFold(parts[0], "DefaultCollection") {
+		parts = parts[1:]
+	}
+
+	switch {
+	case endpoint.Host == "dev.azure.com" && len(parts) == 4 && parts[2] == "_git":
+		return fmt.Sprintf("git@ssh.dev.azure.com:v3/%v/%v/%v", parts[0], parts[1], parts[3])
+	case strings.HasSuffix(endpoint.Host, ".visualstudio.com") && len(parts) == 3 && parts[1] == "_git":
+		org := strings.TrimSuffix(endpoint.Host, ".visualstudio.com")
+		return fmt.Sprintf("%v@vs-ssh.visualstudio.com:v3/%v/%v/%v", org, org, parts[0], parts[2])
+	}
+
+	sshEndpoint := *endpoint
+	sshEndpoint.Protocol = "ssh"
+	sshEndpoint.User = "git"
+	sshEndpoint.Password = ""
+	sshEndpoint.Port = 0
+	return sshEndpoint.String()
+}
+
+// SSHOnly returns true if the credentials can only be used to access repos over SSH
+func (c *Credentials) SSHOnly() bool {
+	return len(c.SSHPrivateKey) != 0 && len(c.Username) == 0 && len(c.Password) == 0
+}
+
+// setKnownHosts verifies host keys of the SSH auth method against the known_hosts entries. Entries are written to
+// a temporary file since known hosts callbacks are created from files, which are read when the callback is created.
+func setKnownHosts(publicKeys *gitssh.PublicKeys, knownHosts string) error {
+	f, err := os.CreateTemp("", "known_hosts")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(knownHosts); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	publicKeys.HostKeyCallback, err = gitssh.NewKnownHostsCallback(f.Name())
+	return err
+}
@@ -13,6 +13,11 @@ type TemplatesResolver struct {
 type Credentials struct {
 	Username string
 	Password string
+
+	// SSHPrivateKey is a PEM encoded private key used to access git repos over SSH
+	SSHPrivateKey string
+	// SSHKnownHosts are known_hosts entries of git servers accessed over SSH
+	SSHKnownHosts string
 }
 
 func NewTemplatesResolver(k8s k8sClient) TemplatesResolver {
@@ -34,26 +39,48 @@ func (t TemplatesResolver) RepoAuthCredentials(repo string) (*Credentials, error
 		}
 
 		if re.MatchString(repo) {
-			username, err := t.k8s.GetTemplateAuthRuleSecret(ta.Spec.Username.Name, ta.Spec.Username.Key)
-			if err != nil {
-				return nil, err
-			}
-
-			password, err := t.k8s.GetTemplateAuthRuleSecret(ta.Spec.Password.Name, ta.Spec.Password.Key)
-			if err != nil {
-				return nil, err
-			}
-
-			return &Credentials{
-				Username: username,
-				Password: password,
-			}, err
+			return t.ruleCredentials(ta)
 		}
 	}
 
 	return nil, nil
 }
 
+func (t TemplatesResolver) ruleCredentials(ta v1alpha1.TemplateAuthRule) (*Credentials, error) {
+	creds := &Credentials{}
+	var err error
+
+	if len(ta.Spec.Username.Name) != 0 {
+		creds.Username, err = t.k8s.GetTemplateAuthRuleSecret(ta.Spec.Username.Name, ta.Spec.Username.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(ta.Spec.Password.Name) != 0 {
+		creds.Password, err = t.k8s.GetTemplateAuthRuleSecret(ta.Spec.Password.Name, ta.Spec.Password.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ta.Spec.SSHPrivateKey != nil {
+		creds.SSHPrivateKey, err = t.k8s.GetTemplateAuthRuleSecret(ta.Spec.SSHPrivateKey.Name, ta.Spec.SSHPrivateKey.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ta.Spec.SSHKnownHosts != nil {
+		creds.SSHKnownHosts, err = t.k8s.GetTemplateAuthRuleSecret(ta.Spec.SSHKnownHosts.Name, ta.Spec.SSHKnownHosts.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return creds, nil
+}
+
 type k8sClient interface {
 	GetTemplateAuthRuleSecret(string, string) (string, error)
 	ListTemplateAuthRules() ([]v1alpha1.TemplateAuthRule, error)
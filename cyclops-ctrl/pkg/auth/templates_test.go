@@ -82,6 +82,19 @@ var _ = Describe("Templates resolver", func() {
 				},
 			},
 		},
+		{
+			Spec: v1alpha1.TemplateAuthRuleSpec{
+				Repo: "git@github.com:my-org/.*",
+				SSHPrivateKey: &apiv1.SecretKeySelector{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: "deploy-key"},
+					Key:                  "identity",
+				},
+				SSHKnownHosts: &apiv1.SecretKeySelector{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: "deploy-key"},
+					Key:                  "known_hosts",
+				},
+			},
+		},
 	}
 
 	testCases := []testCase{
@@ -171,6 +184,24 @@ var _ = Describe("Templates resolver", func() {
 				returnsError: false,
 			},
 		},
+		{
+			description: "fetches ssh private key of matching template auth rule",
+			in: caseInput{
+				repo: "git@github.com:my-org/my-templates.git",
+				mockCalls: func() {
+					k8sClient.On("ListTemplateAuthRules").Return(tars, nil)
+					k8sClient.On("GetTemplateAuthRuleSecret", "deploy-key", "identity").Return("my-private-key", nil)
+					k8sClient.On("GetTemplateAuthRuleSecret", "deploy-key", "known_hosts").Return("github.com ssh-ed25519 AAAA", nil)
+				},
+			},
+			out: caseOutput{
+				credentials: &Credentials{
+					SSHPrivateKey: "my-private-key",
+					SSHKnownHosts: "github.com ssh-ed25519 AAAA",
+				},
+				returnsError: false,
+			},
+		},
 	}
 
 	for _, t := range testCases {
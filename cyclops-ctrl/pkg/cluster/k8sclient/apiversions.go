@@ -0,0 +1,75 @@
+package k8sclient
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// apiVersionsCacheTTL is how long API versions served by the cluster are cached. New CRDs are picked up by
+// rendering after at most this long.
+const apiVersionsCacheTTL = time.Minute
+
+type apiVersionsCache struct {
+	mu        sync.Mutex
+	versions  []string
+	fetchedAt time.Time
+}
+
+// APIVersions returns group versions served by the cluster and their kinds, in the group/version and
+// group/version/Kind formats Helm charts check with .Capabilities.APIVersions.Has
+func (k *KubernetesClient) APIVersions() ([]string, error) {
+	k.apiVersions.mu.Lock()
+	defer k.apiVersions.mu.Unlock()
+
+	if k.apiVersions.versions != nil && time.Since(k.apiVersions.fetchedAt) < apiVersionsCacheTTL {
+		return k.apiVersions.versions, nil
+	}
+
+	// groups that failed discovery are left out, as by Helm
+	_, resources, err := k.discovery.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	versions := make([]string, 0)
+	for _, resourceList := range resources {
+		versions = append(versions, resourceList.GroupVersion)
+		for _, resource := range resourceList.APIResources {
+			versions = append(versions, resourceList.GroupVersion+"/"+resource.Kind)
+		}
+	}
+
+	k.apiVersions.versions = versions
+	k.apiVersions.fetchedAt = time.Now()
+
+	return versions, nil
+}
+
+// ResourceClient returns a dynamic client for resources of the given API version and kind, and whether they
+// are namespaced
+func (k *KubernetesClient) ResourceClient(apiVersion, kind string) (dynamic.NamespaceableResourceInterface, bool, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, false, err
+	}
+
+	apiResources, err := k.discovery.ServerResourcesForGroupVersion(gv.String())
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, resource := range apiResources.APIResources {
+		// subresources, like deployments/scale, share the kind of their resource
+		if resource.Kind == kind && !strings.Contains(resource.Name, "/") {
+			return k.Dynamic.Resource(gv.WithResource(resource.Name)), resource.Namespaced, nil
+		}
+	}
+
+	return nil, false, errors.Errorf("could not find api-resource for groupVersion: %v and kind: %v", gv.String(), kind)
+}
@@ -3,6 +3,7 @@ package k8sclient
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -24,6 +25,9 @@ import (
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/dto"
 )
 
+// fieldManager is the field manager used for all server-side applies done by Cyclops
+const fieldManager = "cyclops-ctrl"
+
 type KubernetesClient struct {
 	config *rest.Config
 
@@ -32,7 +36,15 @@ type KubernetesClient struct {
 	discovery *discovery.DiscoveryClient
 	moduleset *client.CyclopsV1Alpha1Client
 
+	clusters *clusterClients
+
+	apiVersions *apiVersionsCache
+
+	// upgradedObjects holds objects whose managed fields were already upgraded to server-side apply
+	upgradedObjects *sync.Map
+
 	moduleNamespace       string
+	watchNamespaces       []string
 	helmReleaseNamespace  string
 	moduleTargetNamespace string
 
@@ -46,6 +58,10 @@ type ClientConfig struct {
 	ModuleNamespace       string
 	HelmReleaseNamespace  string
 	ModuleTargetNamespace string
+
+	// WatchNamespaces are namespaces watched for modules in addition to the ModuleNamespace. Modules from all
+	// namespaces are watched if it contains metav1.NamespaceAll.
+	WatchNamespaces []string
 }
 
 func NewWithConfig(config ClientConfig, logger logr.Logger) (*KubernetesClient, error) {
@@ -79,6 +95,18 @@ func NewWithConfig(config ClientConfig, logger logr.Logger) (*KubernetesClient,
 		}
 	}
 
+	k, err := newForRestConfig(k8sConfig, config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	k.clusters = newClusterClients(k)
+	return k, nil
+}
+
+// newForRestConfig creates a client for the cluster of the given rest config. Cluster clients are set by the
+// caller.
+func newForRestConfig(k8sConfig *rest.Config, config ClientConfig, logger logr.Logger) (*KubernetesClient, error) {
 	clientset, err := kubernetes.NewForConfig(k8sConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create clientset: %w", err)
@@ -105,7 +133,10 @@ func NewWithConfig(config ClientConfig, logger logr.Logger) (*KubernetesClient,
 		discovery:             discovery,
 		clientset:             clientset,
 		moduleset:             moduleSet,
+		apiVersions:           &apiVersionsCache{},
+		upgradedObjects:       &sync.Map{},
 		moduleNamespace:       config.ModuleNamespace,
+		watchNamespaces:       config.WatchNamespaces,
 		helmReleaseNamespace:  config.HelmReleaseNamespace,
 		moduleTargetNamespace: config.ModuleTargetNamespace,
 		logger:                logger,
@@ -114,12 +145,14 @@ func NewWithConfig(config ClientConfig, logger logr.Logger) (*KubernetesClient,
 
 func New(
 	moduleNamespace string,
+	watchNamespaces []string,
 	helmReleaseNamespace string,
 	moduleTargetNamespace string,
 	logger logr.Logger,
 ) (*KubernetesClient, error) {
 	config := ClientConfig{
 		ModuleNamespace:       moduleNamespace,
+		WatchNamespaces:       watchNamespaces,
 		HelmReleaseNamespace:  helmReleaseNamespace,
 		ModuleTargetNamespace: moduleTargetNamespace,
 	}
@@ -135,23 +168,35 @@ type IKubernetesClient interface {
 	CreateModule(module cyclopsv1alpha1.Module) error
 	UpdateModule(module *cyclopsv1alpha1.Module) error
 	UpdateModuleStatus(module *cyclopsv1alpha1.Module) (*cyclopsv1alpha1.Module, error)
-	DeleteModule(name string) error
-	GetModule(name string) (*cyclopsv1alpha1.Module, error)
-	GetResourcesForModule(name string) ([]*dto.Resource, error)
+	DeleteModule(namespace, name string) error
+	GetModule(namespace, name string) (*cyclopsv1alpha1.Module, error)
+	GetModuleEvents(namespace, name string) ([]apiv1.Event, error)
+	ListModuleRevisions(namespace, name string) ([]cyclopsv1alpha1.ModuleRevision, error)
+	ModuleNamespace(namespace string) string
+	ForCluster(name string) (IKubernetesClient, error)
+	ForgetCluster(name string)
+	ListClusters() ([]cyclopsv1alpha1.Cluster, error)
+	GetResourcesForModule(namespace, name string) ([]*dto.Resource, error)
 	MapUnstructuredResource(u unstructured.Unstructured) (*dto.Resource, error)
-	GetWorkloadsForModule(name string) ([]*dto.Resource, error)
+	GetWorkloadsForModule(namespace, name string) ([]*dto.Resource, error)
 	GetDeletedResources([]*dto.Resource, string, string) ([]*dto.Resource, error)
-	GetModuleResourcesHealth(name string) (string, error)
+	GetModuleResourcesHealth(namespace, name string) (string, error)
 	GVKtoAPIResourceName(gv schema.GroupVersion, kind string) (string, error)
 	VersionInfo() (*version.Info, error)
+	APIVersions() ([]string, error)
+	ResourceClient(apiVersion, kind string) (dynamic.NamespaceableResourceInterface, bool, error)
 	RestartDeployment(name, namespace string) error
 	RestartStatefulSet(name, namespace string) error
 	RestartDaemonSet(name, namespace string) error
 	GetManifest(group, version, kind, name, namespace string, includeManagedFields bool) (string, error)
+	GetDynamic(group, version, kind, name, namespace string) (*unstructured.Unstructured, error)
 	Restart(group, version, kind, name, namespace string) error
 	GetResource(group, version, kind, name, namespace string) (any, error)
 	Delete(resource *dto.Resource) error
-	CreateDynamic(cyclopsv1alpha1.GroupVersionResource, *unstructured.Unstructured, string) error
+	CreateDynamic(cyclopsv1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) error
+	DeleteDynamic(resource cyclopsv1alpha1.GroupVersionResource, obj *unstructured.Unstructured, targetNamespace string) error
+	DryRunDynamic(resource cyclopsv1alpha1.GroupVersionResource, obj *unstructured.Unstructured, targetNamespace string, forceConflicts bool) (*unstructured.Unstructured, *unstructured.Unstructured, error)
+	GetResourceHealth(resource cyclopsv1alpha1.GroupVersionResource, obj *unstructured.Unstructured, targetNamespace string) (string, string, error)
 	ApplyCRD(obj *unstructured.Unstructured) error
 	ListNodes() ([]apiv1.Node, error)
 	GetNode(name string) (*apiv1.Node, error)
@@ -159,8 +204,10 @@ type IKubernetesClient interface {
 	ListNamespaces() ([]string, error)
 	WatchResource(group, version, resource, name, namespace string) (watch.Interface, error)
 	WatchKubernetesResources(gvrs []ResourceWatchSpec, stopCh chan struct{}) (chan *unstructured.Unstructured, error)
+	WatchModuleResources(gvr schema.GroupVersionResource, onChange func(*unstructured.Unstructured), stopCh <-chan struct{})
 	ListTemplateAuthRules() ([]cyclopsv1alpha1.TemplateAuthRule, error)
 	GetTemplateAuthRuleSecret(name, key string) (string, error)
+	GetValuesReference(namespace string, reference cyclopsv1alpha1.ValuesReference) (string, bool, error)
 	ListTemplateStore() ([]cyclopsv1alpha1.TemplateStore, error)
 	GetTemplateStore(name string) (*cyclopsv1alpha1.TemplateStore, error)
 	CreateTemplateStore(ts *cyclopsv1alpha1.TemplateStore) error
@@ -0,0 +1,110 @@
+package k8sclient
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// clusterClients caches clients of remote clusters. It is shared between the local client and all remote
+// clients created from it.
+type clusterClients struct {
+	local *KubernetesClient
+
+	mu      sync.Mutex
+	remotes map[string]remoteClient
+}
+
+type remoteClient struct {
+	// version is the resource version of the Cluster and the kubeconfig Secret the client was created from
+	version string
+	client  *KubernetesClient
+}
+
+func newClusterClients(local *KubernetesClient) *clusterClients {
+	return &clusterClients{
+		local:   local,
+		remotes: make(map[string]remoteClient),
+	}
+}
+
+func (k *KubernetesClient) ListClusters() ([]cyclopsv1alpha1.Cluster, error) {
+	return k.moduleset.Clusters(k.moduleNamespace).List(metav1.ListOptions{})
+}
+
+// ForCluster returns a client for the Cluster with the given name, or the client of the cluster Cyclops runs in
+// if the name is empty. Clients of remote clusters read and write modules and other Cyclops custom resources in
+// the cluster Cyclops runs in, and all other resources in the remote cluster.
+func (k *KubernetesClient) ForCluster(name string) (IKubernetesClient, error) {
+	local := k.clusters.local
+	if len(name) == 0 {
+		return local, nil
+	}
+
+	cluster, err := local.moduleset.Clusters(local.moduleNamespace).Get(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get cluster %v", name)
+	}
+
+	secret, err := local.clientset.CoreV1().Secrets(local.moduleNamespace).Get(
+		context.Background(),
+		cluster.Spec.Kubeconfig.Name,
+		metav1.GetOptions{},
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get kubeconfig of cluster %v", name)
+	}
+
+	kubeconfig, ok := secret.Data[cluster.Spec.Kubeconfig.Key]
+	if !ok {
+		return nil, errors.Errorf("key %v not found in kubeconfig secret %v of cluster %v", cluster.Spec.Kubeconfig.Key, secret.Name, name)
+	}
+
+	version := cluster.ResourceVersion + "/" + secret.ResourceVersion
+
+	k.clusters.mu.Lock()
+	defer k.clusters.mu.Unlock()
+
+	if remote, ok := k.clusters.remotes[name]; ok && remote.version == version {
+		return remote.client, nil
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load kubeconfig of cluster %v", name)
+	}
+
+	remote, err := newForRestConfig(restConfig, ClientConfig{
+		ModuleNamespace:       local.moduleNamespace,
+		WatchNamespaces:       local.watchNamespaces,
+		HelmReleaseNamespace:  local.helmReleaseNamespace,
+		ModuleTargetNamespace: local.moduleTargetNamespace,
+	}, local.logger.WithValues("cluster", name))
+	if err != nil {
+		return nil, err
+	}
+
+	remote.moduleset = local.moduleset
+	remote.clusters = k.clusters
+
+	k.clusters.remotes[name] = remoteClient{
+		version: version,
+		client:  remote,
+	}
+
+	return remote, nil
+}
+
+// ForgetCluster drops the cached client of the Cluster with the given name, so the next ForCluster call creates a
+// new one
+func (k *KubernetesClient) ForgetCluster(name string) {
+	k.clusters.mu.Lock()
+	defer k.clusters.mu.Unlock()
+
+	delete(k.clusters.remotes, name)
+}
@@ -0,0 +1,106 @@
+package k8sclient
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ignoredDiffFields are set by the API server or other controllers and never come from a module template
+var ignoredDiffFields = [][]string{
+	{"status"},
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "uid"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration"},
+}
+
+// DiffObjects returns sorted paths of all fields that differ between two versions of the same object,
+// like .spec.replicas or .spec.template.spec.containers[0].image. Status and metadata maintained by the
+// API server are ignored.
+func DiffObjects(current, desired *unstructured.Unstructured) []string {
+	currentContent := stripIgnoredFields(current)
+	desiredContent := stripIgnoredFields(desired)
+
+	paths := diffValues("", currentContent, desiredContent)
+	sort.Strings(paths)
+
+	return paths
+}
+
+func stripIgnoredFields(obj *unstructured.Unstructured) map[string]interface{} {
+	if obj == nil {
+		return map[string]interface{}{}
+	}
+
+	content := obj.DeepCopy().UnstructuredContent()
+	for _, field := range ignoredDiffFields {
+		unstructured.RemoveNestedField(content, field...)
+	}
+
+	if annotations, found, _ := unstructured.NestedMap(content, "metadata", "annotations"); found && len(annotations) == 0 {
+		unstructured.RemoveNestedField(content, "metadata", "annotations")
+	}
+
+	return content
+}
+
+func diffValues(path string, current, desired interface{}) []string {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		currentValue, ok := current.(map[string]interface{})
+		if !ok {
+			return []string{pathOrRoot(path)}
+		}
+
+		paths := make([]string, 0)
+		for key, value := range desiredValue {
+			paths = append(paths, diffValues(path+"."+key, currentValue[key], value)...)
+		}
+
+		for key, value := range currentValue {
+			if _, exists := desiredValue[key]; !exists {
+				paths = append(paths, diffValues(path+"."+key, value, nil)...)
+			}
+		}
+
+		return paths
+	case []interface{}:
+		currentValue, ok := current.([]interface{})
+		if !ok {
+			return []string{pathOrRoot(path)}
+		}
+
+		paths := make([]string, 0)
+		for i := 0; i < len(desiredValue) || i < len(currentValue); i++ {
+			elementPath := fmt.Sprintf("%v[%v]", path, i)
+
+			if i >= len(currentValue) || i >= len(desiredValue) {
+				paths = append(paths, elementPath)
+				continue
+			}
+
+			paths = append(paths, diffValues(elementPath, currentValue[i], desiredValue[i])...)
+		}
+
+		return paths
+	default:
+		if reflect.DeepEqual(current, desired) {
+			return nil
+		}
+
+		return []string{pathOrRoot(path)}
+	}
+}
+
+func pathOrRoot(path string) string {
+	if len(path) == 0 {
+		return "."
+	}
+
+	return path
+}
@@ -0,0 +1,158 @@
+package k8sclient
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestK8sClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test k8s client")
+}
+
+var _ = Describe("DiffObjects", func() {
+	deployment := func(replicas int64, image string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "my-app",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  "app",
+								"image": image,
+							},
+						},
+					},
+				},
+			},
+		}}
+	}
+
+	It("returns no paths for equal objects", func() {
+		Expect(DiffObjects(deployment(1, "nginx:1.25"), deployment(1, "nginx:1.25"))).To(BeEmpty())
+	})
+
+	It("returns paths of changed fields", func() {
+		Expect(DiffObjects(deployment(3, "nginx:1.25"), deployment(1, "nginx:1.26"))).To(Equal([]string{
+			".spec.replicas",
+			".spec.template.spec.containers[0].image",
+		}))
+	})
+
+	It("returns paths of added and removed fields", func() {
+		current := deployment(1, "nginx:1.25")
+		Expect(unstructured.SetNestedField(current.Object, "Recreate", "spec", "strategy", "type")).To(Succeed())
+
+		desired := deployment(1, "nginx:1.25")
+		Expect(unstructured.SetNestedSlice(desired.Object, []interface{}{
+			map[string]interface{}{"name": "app", "image": "nginx:1.25"},
+			map[string]interface{}{"name": "sidecar", "image": "envoy"},
+		}, "spec", "template", "spec", "containers")).To(Succeed())
+
+		Expect(DiffObjects(current, desired)).To(Equal([]string{
+			".spec.strategy",
+			".spec.template.spec.containers[1]",
+		}))
+	})
+
+	It("ignores status and server maintained metadata", func() {
+		current := deployment(1, "nginx:1.25")
+		current.SetResourceVersion("1234")
+		current.SetGeneration(3)
+		current.SetAnnotations(map[string]string{
+			"kubectl.kubernetes.io/last-applied-configuration": "{}",
+		})
+		Expect(unstructured.SetNestedField(current.Object, int64(1), "status", "readyReplicas")).To(Succeed())
+
+		Expect(DiffObjects(current, deployment(1, "nginx:1.25"))).To(BeEmpty())
+	})
+})
+
+var _ = Describe("DriftedFields", func() {
+	deployment := func(replicas int64, image string, labels map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "my-app",
+				"namespace": "default",
+				"labels":    labels,
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  "app",
+								"image": image,
+							},
+						},
+					},
+				},
+			},
+		}}
+	}
+
+	// live object with replicas owned by an HPA and everything else owned by Cyclops
+	live := func(replicas int64, image string, labels map[string]interface{}) *unstructured.Unstructured {
+		obj := deployment(replicas, image, labels)
+		obj.SetManagedFields([]metav1.ManagedFieldsEntry{
+			{
+				Manager:    fieldManager,
+				Operation:  metav1.ManagedFieldsOperationApply,
+				APIVersion: "apps/v1",
+				FieldsType: "FieldsV1",
+				FieldsV1: &metav1.FieldsV1{Raw: []byte(`{
+					"f:metadata":{"f:labels":{"f:app":{}}},
+					"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{".":{},"f:image":{},"f:name":{}}}}}}
+				}`)},
+			},
+			{
+				Manager:     "kube-controller-manager",
+				Operation:   metav1.ManagedFieldsOperationUpdate,
+				APIVersion:  "apps/v1",
+				FieldsType:  "FieldsV1",
+				Subresource: "scale",
+				FieldsV1:    &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+			},
+		})
+		return obj
+	}
+
+	It("ignores fields owned by other field managers", func() {
+		Expect(DriftedFields(
+			live(5, "nginx:1.25", map[string]interface{}{"app": "my-app"}),
+			deployment(3, "nginx:1.25", map[string]interface{}{"app": "my-app"}),
+		)).To(BeEmpty())
+	})
+
+	It("returns fields owned by Cyclops", func() {
+		Expect(DriftedFields(
+			live(5, "nginx:1.24", map[string]interface{}{"app": "my-app"}),
+			deployment(3, "nginx:1.25", map[string]interface{}{"app": "my-app"}),
+		)).To(Equal([]string{
+			".spec.template.spec.containers[0].image",
+		}))
+	})
+
+	It("returns fields missing from the live object", func() {
+		Expect(DriftedFields(
+			live(5, "nginx:1.25", map[string]interface{}{}),
+			deployment(3, "nginx:1.25", map[string]interface{}{"app": "my-app"}),
+		)).To(Equal([]string{
+			".metadata.labels.app",
+		}))
+	})
+})
@@ -0,0 +1,150 @@
+package k8sclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DriftedFields returns sorted paths of fields that differ between the live object and the object the API server
+// would persist if the rendered resource was applied. Only fields Cyclops owns on the live object, and fields
+// missing from it, are drift. Fields taken over by other field managers, like replicas scaled by an HPA, are not.
+func DriftedFields(live, applied *unstructured.Unstructured) []string {
+	paths := diffOwned("", stripIgnoredFields(live), stripIgnoredFields(applied), ownedFields(live))
+	sort.Strings(paths)
+
+	return paths
+}
+
+// ownedFields returns the fields set of the Cyclops apply, in the FieldsV1 format, e.g. {"f:spec":{"f:replicas":{}}}
+func ownedFields(obj *unstructured.Unstructured) map[string]interface{} {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager != fieldManager || entry.Operation != metav1.ManagedFieldsOperationApply || entry.FieldsV1 == nil {
+			continue
+		}
+
+		var owned map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &owned); err != nil {
+			continue
+		}
+
+		return owned
+	}
+
+	return map[string]interface{}{}
+}
+
+func diffOwned(path string, current, desired interface{}, owned map[string]interface{}) []string {
+	switch currentValue := current.(type) {
+	case map[string]interface{}:
+		desiredValue, _ := desired.(map[string]interface{})
+
+		paths := make([]string, 0)
+		for key, value := range desiredValue {
+			if _, exists := currentValue[key]; !exists {
+				paths = append(paths, diffValues(path+"."+key, nil, value)...)
+			}
+		}
+
+		for key, value := range currentValue {
+			childOwned, ok := owned["f:"+key].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			paths = append(paths, diffOwnedChild(path+"."+key, value, desiredValue[key], childOwned)...)
+		}
+
+		return paths
+	case []interface{}:
+		desiredValue, _ := desired.([]interface{})
+
+		paths := make([]string, 0)
+		for i := 0; i < len(desiredValue) || i < len(currentValue); i++ {
+			elementPath := fmt.Sprintf("%v[%v]", path, i)
+
+			if i >= len(currentValue) {
+				paths = append(paths, elementPath)
+				continue
+			}
+
+			childOwned := listItemOwned(owned, i, currentValue[i])
+			if childOwned == nil {
+				continue
+			}
+
+			var desiredItem interface{}
+			if i < len(desiredValue) {
+				desiredItem = desiredValue[i]
+			}
+
+			paths = append(paths, diffOwnedChild(elementPath, currentValue[i], desiredItem, childOwned)...)
+		}
+
+		return paths
+	default:
+		return diffValues(path, current, desired)
+	}
+}
+
+// diffOwnedChild diffs the whole field if it is owned without listing its children, like atomic structs and lists
+func diffOwnedChild(path string, current, desired interface{}, owned map[string]interface{}) []string {
+	for key := range owned {
+		if key != "." {
+			return diffOwned(path, current, desired, owned)
+		}
+	}
+
+	return diffValues(path, current, desired)
+}
+
+// listItemOwned returns the owned fields of the list item, which is keyed by its index (i:0), its value for sets
+// (v:"value") or its key fields for associative lists (k:{"name":"app"})
+func listItemOwned(owned map[string]interface{}, index int, item interface{}) map[string]interface{} {
+	for key, value := range owned {
+		childOwned, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		switch {
+		case key == fmt.Sprintf("i:%v", index):
+			return childOwned
+		case strings.HasPrefix(key, "v:"):
+			itemValue, err := json.Marshal(item)
+			if err == nil && string(itemValue) == strings.TrimPrefix(key, "v:") {
+				return childOwned
+			}
+		case strings.HasPrefix(key, "k:"):
+			if matchesListKey(strings.TrimPrefix(key, "k:"), item) {
+				return childOwned
+			}
+		}
+	}
+
+	return nil
+}
+
+func matchesListKey(key string, item interface{}) bool {
+	itemMap, ok := item.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	var keyFields map[string]interface{}
+	if err := json.Unmarshal([]byte(key), &keyFields); err != nil {
+		return false
+	}
+
+	for name, value := range keyFields {
+		if fmt.Sprint(itemMap[name]) != fmt.Sprint(value) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,223 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// Rollout health of a module resource returned by GetResourceHealth. Resources are unhealthy only if their
+// rollout failed for good, a resource that is not ready yet is progressing.
+const (
+	HealthHealthy     = statusHealthy
+	HealthProgressing = statusProgressing
+	HealthUnhealthy   = statusUnhealthy
+)
+
+// GetResourceHealth fetches the live state of obj and assesses if it finished rolling out. It returns
+// one of the Health statuses and a message describing why the resource is not healthy.
+func (k *KubernetesClient) GetResourceHealth(
+	resource v1alpha1.GroupVersionResource,
+	obj *unstructured.Unstructured,
+	targetNamespace string,
+) (string, string, error) {
+	resourceClient, err := k.resourceClientForObject(resource, obj, targetNamespace)
+	if err != nil {
+		return statusUnknown, "", err
+	}
+
+	live, err := resourceClient.Get(context.Background(), obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return HealthProgressing, "resource not found", nil
+		}
+
+		return statusUnknown, "", err
+	}
+
+	return ResourceHealth(live)
+}
+
+// ResourceHealth assesses the rollout health of a live resource. Workloads, jobs, pods and persistent volume
+// claims are checked by kind, all other resources by their status conditions in the kstatus convention.
+// Resources without status are healthy as soon as they exist.
+func ResourceHealth(obj *unstructured.Unstructured) (string, string, error) {
+	group, version, kind := obj.GroupVersionKind().Group, obj.GroupVersionKind().Version, obj.GetKind()
+
+	switch {
+	case isDeployment(group, version, kind):
+		var deployment appsv1.Deployment
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment); err != nil {
+			return statusUnknown, "", err
+		}
+
+		return deploymentHealth(&deployment)
+	case isStatefulSet(group, version, kind):
+		var statefulset appsv1.StatefulSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &statefulset); err != nil {
+			return statusUnknown, "", err
+		}
+
+		if getStatefulSetStatus(&statefulset) == statusHealthy {
+			return HealthHealthy, "", nil
+		}
+
+		return HealthProgressing, fmt.Sprintf(
+			"%v of %v replicas updated and ready",
+			statefulset.Status.UpdatedReplicas,
+			statefulset.Status.Replicas,
+		), nil
+	case isDaemonSet(group, version, kind):
+		var daemonset appsv1.DaemonSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &daemonset); err != nil {
+			return statusUnknown, "", err
+		}
+
+		if getDaemonSetStatus(&daemonset) == statusHealthy {
+			return HealthHealthy, "", nil
+		}
+
+		return HealthProgressing, fmt.Sprintf(
+			"%v of %v pods updated, %v unavailable",
+			daemonset.Status.UpdatedNumberScheduled,
+			daemonset.Status.DesiredNumberScheduled,
+			daemonset.Status.NumberUnavailable,
+		), nil
+	case isJob(group, version, kind):
+		var job batchv1.Job
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &job); err != nil {
+			return statusUnknown, "", err
+		}
+
+		return jobHealth(&job)
+	case isPod(group, version, kind):
+		var pod apiv1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+			return statusUnknown, "", err
+		}
+
+		return podHealth(&pod)
+	case isPersistentVolumeClaims(group, version, kind):
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		if phase == string(apiv1.ClaimBound) {
+			return HealthHealthy, "", nil
+		}
+
+		if phase == string(apiv1.ClaimLost) {
+			return HealthUnhealthy, "persistent volume claim lost its volume", nil
+		}
+
+		return HealthProgressing, "persistent volume claim is not bound", nil
+	}
+
+	return conditionsHealth(obj)
+}
+
+func deploymentHealth(deployment *appsv1.Deployment) (string, string, error) {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == apiv1.ConditionFalse {
+			return HealthUnhealthy, condition.Message, nil
+		}
+	}
+
+	if getDeploymentStatus(deployment) == statusHealthy {
+		return HealthHealthy, "", nil
+	}
+
+	return HealthProgressing, fmt.Sprintf(
+		"%v of %v replicas updated, %v unavailable",
+		deployment.Status.UpdatedReplicas,
+		deployment.Status.Replicas,
+		deployment.Status.UnavailableReplicas,
+	), nil
+}
+
+func jobHealth(job *batchv1.Job) (string, string, error) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != apiv1.ConditionTrue {
+			continue
+		}
+
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return HealthHealthy, "", nil
+		case batchv1.JobFailed:
+			return HealthUnhealthy, condition.Message, nil
+		}
+	}
+
+	return HealthProgressing, "job has not completed", nil
+}
+
+func podHealth(pod *apiv1.Pod) (string, string, error) {
+	switch pod.Status.Phase {
+	case apiv1.PodSucceeded:
+		return HealthHealthy, "", nil
+	case apiv1.PodFailed:
+		return HealthUnhealthy, pod.Status.Message, nil
+	}
+
+	for _, cnt := range pod.Spec.Containers {
+		var status apiv1.ContainerStatus
+		for _, c := range pod.Status.ContainerStatuses {
+			if c.Name == cnt.Name {
+				status = c
+				break
+			}
+		}
+
+		if !containerStatus(status).Running || !status.Ready {
+			return HealthProgressing, fmt.Sprintf("container %v is not ready", cnt.Name), nil
+		}
+	}
+
+	return HealthHealthy, "", nil
+}
+
+// conditionsHealth follows the kstatus conventions: a resource is progressing until its controller observed
+// the latest generation and while its Reconciling condition is true or its Ready condition is not, and it is
+// unhealthy if its Stalled condition is true.
+func conditionsHealth(obj *unstructured.Unstructured) (string, string, error) {
+	observedGeneration, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if found && observedGeneration < obj.GetGeneration() {
+		return HealthProgressing, "latest generation not observed yet", nil
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+
+	statuses := make(map[string]string)
+	messages := make(map[string]string)
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		statuses[conditionType], _, _ = unstructured.NestedString(condition, "status")
+		messages[conditionType], _, _ = unstructured.NestedString(condition, "message")
+	}
+
+	if statuses["Stalled"] == string(metav1.ConditionTrue) {
+		return HealthUnhealthy, messages["Stalled"], nil
+	}
+
+	if statuses["Reconciling"] == string(metav1.ConditionTrue) {
+		return HealthProgressing, messages["Reconciling"], nil
+	}
+
+	if status, ok := statuses["Ready"]; ok && status != string(metav1.ConditionTrue) {
+		return HealthProgressing, messages["Ready"], nil
+	}
+
+	return HealthHealthy, "", nil
+}
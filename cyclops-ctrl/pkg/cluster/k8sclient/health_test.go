@@ -0,0 +1,126 @@
+package k8sclient
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("ResourceHealth", func() {
+	type testCase struct {
+		description string
+		obj         map[string]interface{}
+		health      string
+	}
+
+	testCases := []testCase{
+		{
+			description: "deployment rolled out",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "app", "generation": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"replicas":           int64(3),
+					"updatedReplicas":    int64(3),
+				},
+			},
+			health: HealthHealthy,
+		},
+		{
+			description: "deployment rolling out",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "app", "generation": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration":  int64(2),
+					"replicas":            int64(3),
+					"updatedReplicas":     int64(1),
+					"unavailableReplicas": int64(1),
+				},
+			},
+			health: HealthProgressing,
+		},
+		{
+			description: "deployment exceeded its progress deadline",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "app"},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{
+							"type":   "Progressing",
+							"status": "False",
+							"reason": "ProgressDeadlineExceeded",
+						},
+					},
+				},
+			},
+			health: HealthUnhealthy,
+		},
+		{
+			description: "failed job",
+			obj: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"metadata":   map[string]interface{}{"name": "migrate"},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Failed", "status": "True"},
+					},
+				},
+			},
+			health: HealthUnhealthy,
+		},
+		{
+			description: "custom resource that is not ready",
+			obj: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Database",
+				"metadata":   map[string]interface{}{"name": "db"},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "False"},
+					},
+				},
+			},
+			health: HealthProgressing,
+		},
+		{
+			description: "custom resource that stalled",
+			obj: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Database",
+				"metadata":   map[string]interface{}{"name": "db"},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "False"},
+						map[string]interface{}{"type": "Stalled", "status": "True"},
+					},
+				},
+			},
+			health: HealthUnhealthy,
+		},
+		{
+			description: "resource without status",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "config"},
+			},
+			health: HealthHealthy,
+		},
+	}
+
+	for _, t := range testCases {
+		It(t.description, func() {
+			health, _, err := ResourceHealth(&unstructured.Unstructured{Object: t.obj})
+
+			Expect(err).To(BeNil())
+			Expect(health).To(Equal(t.health))
+		})
+	}
+})
@@ -0,0 +1,66 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/csaupgrade"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// legacyFieldManagers own fields of resources that Cyclops created and updated before it switched to server-side
+// apply. Updates without a field manager are recorded under the name of the binary taken from the user agent,
+// which is ctrl in the Cyclops image.
+var legacyFieldManagers = sets.New[string](
+	fieldManager,
+	"ctrl",
+	strings.Split(rest.DefaultKubernetesUserAgent(), "/")[0],
+)
+
+// upgradeManagedFields moves fields owned by legacy Update operations to the Apply operation of the Cyclops field
+// manager, same as kubectl does before the first server-side apply. Otherwise, applies conflict with the legacy
+// owner and fields removed from the template are never pruned. Each object is upgraded once.
+func (k *KubernetesClient) upgradeManagedFields(
+	resourceClient dynamic.ResourceInterface,
+	resource v1alpha1.GroupVersionResource,
+	obj *unstructured.Unstructured,
+) error {
+	key := fmt.Sprintf("%v/%v/%v/%v/%v", resource.Group, resource.Version, resource.Resource, obj.GetNamespace(), obj.GetName())
+	if _, ok := k.upgradedObjects.Load(key); ok {
+		return nil
+	}
+
+	live, err := resourceClient.Get(context.Background(), obj.GetName(), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		k.upgradedObjects.Store(key, struct{}{})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(live, legacyFieldManagers, fieldManager)
+	if err != nil {
+		return err
+	}
+
+	if patch != nil {
+		_, err := resourceClient.Patch(context.Background(), obj.GetName(), types.JSONPatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to upgrade managed fields of %v %v: %w", live.GetKind(), live.GetName(), err)
+		}
+	}
+
+	k.upgradedObjects.Store(key, struct{}{})
+
+	return nil
+}
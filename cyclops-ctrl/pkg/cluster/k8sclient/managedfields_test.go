@@ -0,0 +1,100 @@
+package k8sclient
+
+import (
+	"context"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+var _ = Describe("Upgrade managed fields", func() {
+	gvr := v1alpha1.GroupVersionResource{
+		Group:    "",
+		Version:  "v1",
+		Resource: "configmaps",
+	}
+
+	newConfigMap := func(managedFields []metav1.ManagedFieldsEntry) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetName("app")
+		obj.SetNamespace("default")
+		obj.SetResourceVersion("1")
+		obj.SetManagedFields(managedFields)
+		_ = unstructured.SetNestedStringMap(obj.Object, map[string]string{"key": "value"}, "data")
+		return obj
+	}
+
+	fieldsData := &metav1.FieldsV1{Raw: []byte(`{"f:data":{".":{},"f:key":{}}}`)}
+
+	It("moves fields owned by the legacy update manager to the apply manager", func() {
+		live := newConfigMap([]metav1.ManagedFieldsEntry{
+			{
+				Manager:    "ctrl",
+				Operation:  metav1.ManagedFieldsOperationUpdate,
+				APIVersion: "v1",
+				FieldsType: "FieldsV1",
+				FieldsV1:   fieldsData,
+			},
+			{
+				Manager:    "kube-controller-manager",
+				Operation:  metav1.ManagedFieldsOperationUpdate,
+				APIVersion: "v1",
+				FieldsType: "FieldsV1",
+				FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:app":{}}}}`)},
+			},
+		})
+
+		dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), live)
+		k := &KubernetesClient{
+			Dynamic:         dynamicClient,
+			upgradedObjects: &sync.Map{},
+		}
+
+		resourceClient := dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("default")
+
+		err := k.upgradeManagedFields(resourceClient, gvr, newConfigMap(nil))
+		Expect(err).To(BeNil())
+
+		upgraded, err := resourceClient.Get(context.Background(), "app", metav1.GetOptions{})
+		Expect(err).To(BeNil())
+
+		managers := make(map[string]metav1.ManagedFieldsOperationType)
+		for _, entry := range upgraded.GetManagedFields() {
+			managers[entry.Manager] = entry.Operation
+		}
+
+		Expect(managers).To(Equal(map[string]metav1.ManagedFieldsOperationType{
+			fieldManager:              metav1.ManagedFieldsOperationApply,
+			"kube-controller-manager": metav1.ManagedFieldsOperationUpdate,
+		}))
+
+		_, ok := k.upgradedObjects.Load("/v1/configmaps/default/app")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("skips objects that do not exist", func() {
+		dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+		k := &KubernetesClient{
+			Dynamic:         dynamicClient,
+			upgradedObjects: &sync.Map{},
+		}
+
+		resourceClient := dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("default")
+
+		err := k.upgradeManagedFields(resourceClient, gvr, newConfigMap(nil))
+		Expect(err).To(BeNil())
+
+		_, ok := k.upgradedObjects.Load("/v1/configmaps/default/app")
+		Expect(ok).To(BeTrue())
+	})
+})
@@ -15,6 +15,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	yaml2 "k8s.io/apimachinery/pkg/util/yaml"
@@ -30,47 +31,149 @@ const (
 	statusProgressing = "progressing"
 )
 
+// ModuleNamespaceLabel is set on module resources to the namespace of the module they belong to. Resources
+// without it belong to the module in the default module namespace.
+const ModuleNamespaceLabel = "cyclops.module-namespace"
+
+// ListModules lists modules from all namespaces watched for modules
 func (k *KubernetesClient) ListModules() ([]cyclopsv1alpha1.Module, error) {
-	moduleList, err := k.moduleset.Modules(k.moduleNamespace).List(metav1.ListOptions{})
+	modules := make([]cyclopsv1alpha1.Module, 0)
+	for _, namespace := range k.moduleNamespaces() {
+		moduleList, err := k.moduleset.Modules(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		modules = append(modules, moduleList...)
+	}
 
-	return moduleList, err
+	return modules, nil
 }
 
 func (k *KubernetesClient) CreateModule(module cyclopsv1alpha1.Module) error {
-	_, err := k.moduleset.Modules(k.moduleNamespace).Create(&module)
+	module.Namespace = k.ModuleNamespace(module.Namespace)
+	if !k.isWatchedNamespace(module.Namespace) {
+		return errors.Errorf("namespace %v is not watched for modules", module.Namespace)
+	}
+
+	_, err := k.moduleset.Modules(module.Namespace).Create(&module)
 	return err
 }
 
 func (k *KubernetesClient) UpdateModule(module *cyclopsv1alpha1.Module) error {
-	_, err := k.moduleset.Modules(k.moduleNamespace).Update(module)
+	_, err := k.moduleset.Modules(k.ModuleNamespace(module.Namespace)).Update(module)
 	return err
 }
 
 func (k *KubernetesClient) UpdateModuleStatus(module *cyclopsv1alpha1.Module) (*cyclopsv1alpha1.Module, error) {
-	return k.moduleset.Modules(k.moduleNamespace).PatchStatus(module)
+	return k.moduleset.Modules(k.ModuleNamespace(module.Namespace)).PatchStatus(module)
+}
+
+func (k *KubernetesClient) DeleteModule(namespace, name string) error {
+	return k.moduleset.Modules(k.ModuleNamespace(namespace)).Delete(name)
 }
 
-func (k *KubernetesClient) DeleteModule(name string) error {
-	return k.moduleset.Modules(k.moduleNamespace).Delete(name)
+func (k *KubernetesClient) GetModule(namespace, name string) (*cyclopsv1alpha1.Module, error) {
+	return k.moduleset.Modules(k.ModuleNamespace(namespace)).Get(name)
+}
+
+// ModuleNamespace returns the given module namespace, or the default module namespace if it is empty
+func (k *KubernetesClient) ModuleNamespace(namespace string) string {
+	if len(namespace) == 0 {
+		return k.moduleNamespace
+	}
+
+	return namespace
+}
+
+// moduleNamespaces returns namespaces to list modules from. A single metav1.NamespaceAll is returned if all
+// namespaces are watched.
+func (k *KubernetesClient) moduleNamespaces() []string {
+	namespaces := []string{k.moduleNamespace}
+	for _, namespace := range k.watchNamespaces {
+		if namespace == metav1.NamespaceAll {
+			return []string{metav1.NamespaceAll}
+		}
+
+		if namespace != k.moduleNamespace {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	return namespaces
+}
+
+func (k *KubernetesClient) isWatchedNamespace(namespace string) bool {
+	for _, watched := range k.moduleNamespaces() {
+		if watched == metav1.NamespaceAll || watched == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isModuleResource checks if a resource with the given labels belongs to the module in the given namespace. It
+// is used on top of the cyclops.module label selector since modules in different namespaces can share a name.
+func (k *KubernetesClient) isModuleResource(resourceLabels map[string]string, namespace string) bool {
+	moduleNamespace, ok := resourceLabels[ModuleNamespaceLabel]
+	if !ok {
+		moduleNamespace = k.moduleNamespace
+	}
+
+	return moduleNamespace == k.ModuleNamespace(namespace)
+}
+
+// SetModuleLabels labels obj as a resource managed by the module with the given namespace and name
+func SetModuleLabels(obj *unstructured.Unstructured, moduleNamespace, moduleName string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+
+	labels["app.kubernetes.io/managed-by"] = "cyclops"
+	labels["cyclops.module"] = moduleName
+	labels[ModuleNamespaceLabel] = moduleNamespace
+	obj.SetLabels(labels)
+}
+
+// GetModuleEvents lists events recorded on the module with the given namespace and name
+func (k *KubernetesClient) GetModuleEvents(namespace, name string) ([]apiv1.Event, error) {
+	events, err := k.clientset.CoreV1().Events(k.ModuleNamespace(namespace)).List(context.Background(), metav1.ListOptions{
+		FieldSelector: fields.AndSelectors(
+			fields.OneTermEqualSelector("involvedObject.kind", "Module"),
+			fields.OneTermEqualSelector("involvedObject.name", name),
+		).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events.Items, nil
 }
 
-func (k *KubernetesClient) GetModule(name string) (*cyclopsv1alpha1.Module, error) {
-	return k.moduleset.Modules(k.moduleNamespace).Get(name)
+// ListModuleRevisions lists revisions of the module with the given namespace and name
+func (k *KubernetesClient) ListModuleRevisions(namespace, name string) ([]cyclopsv1alpha1.ModuleRevision, error) {
+	return k.moduleset.ModuleRevisions(k.ModuleNamespace(namespace)).List(metav1.ListOptions{
+		LabelSelector: labels.Set{cyclopsv1alpha1.ModuleRevisionModuleLabel: name}.String(),
+	})
 }
 
-func (k *KubernetesClient) GetResourcesForModule(name string) ([]*dto.Resource, error) {
+func (k *KubernetesClient) GetResourcesForModule(namespace, name string) ([]*dto.Resource, error) {
 	out := make([]*dto.Resource, 0, 0)
 
-	managedGVRs, err := k.getManagedGVRs(name)
+	managedGVRs, err := k.getManagedGVRs(namespace, name)
 	if err != nil {
 		return nil, err
 	}
 
+	resourcesNamespace := k.moduleResourcesNamespace(namespace, name)
+
 	other := make([]unstructured.Unstructured, 0)
 	for _, gvr := range managedGVRs {
 		var rs *unstructured.UnstructuredList
-		if len(k.moduleTargetNamespace) > 0 {
-			rs, err = k.Dynamic.Resource(gvr).Namespace(k.moduleTargetNamespace).List(context.Background(), metav1.ListOptions{
+		if len(resourcesNamespace) > 0 {
+			rs, err = k.Dynamic.Resource(gvr).Namespace(resourcesNamespace).List(context.Background(), metav1.ListOptions{
 				LabelSelector: "cyclops.module=" + name,
 			})
 		} else {
@@ -89,6 +192,10 @@ func (k *KubernetesClient) GetResourcesForModule(name string) ([]*dto.Resource,
 		}
 
 		for _, item := range rs.Items {
+			if !k.isModuleResource(item.GetLabels(), namespace) {
+				continue
+			}
+
 			other = append(other, item)
 		}
 	}
@@ -130,10 +237,27 @@ func (k *KubernetesClient) MapUnstructuredResource(u unstructured.Unstructured)
 	}, nil
 }
 
-func (k *KubernetesClient) GetWorkloadsForModule(name string) ([]*dto.Resource, error) {
+// moduleResourcesNamespace returns the namespace to list resources of the module with the given namespace and name
+// from. Resources are listed from all namespaces, unless Cyclops is restricted to moduleTargetNamespace. Modules
+// applied to the cluster directly can still set another target namespace, so it is used if the module sets one.
+func (k *KubernetesClient) moduleResourcesNamespace(namespace, name string) string {
+	if len(k.moduleTargetNamespace) == 0 {
+		return metav1.NamespaceAll
+	}
+
+	module, err := k.GetModule(namespace, name)
+	if err == nil && len(strings.TrimSpace(module.Spec.TargetNamespace)) != 0 {
+		return strings.TrimSpace(module.Spec.TargetNamespace)
+	}
+
+	return k.moduleTargetNamespace
+}
+
+func (k *KubernetesClient) GetWorkloadsForModule(namespace, name string) ([]*dto.Resource, error) {
 	out := make([]*dto.Resource, 0, 0)
+	resourcesNamespace := k.moduleResourcesNamespace(namespace, name)
 
-	deployments, err := k.clientset.AppsV1().Deployments(k.moduleTargetNamespace).List(context.Background(), metav1.ListOptions{
+	deployments, err := k.clientset.AppsV1().Deployments(resourcesNamespace).List(context.Background(), metav1.ListOptions{
 		LabelSelector: "cyclops.module=" + name,
 	})
 	if err != nil {
@@ -141,6 +265,10 @@ func (k *KubernetesClient) GetWorkloadsForModule(name string) ([]*dto.Resource,
 	}
 
 	for _, item := range deployments.Items {
+		if !k.isModuleResource(item.Labels, namespace) {
+			continue
+		}
+
 		out = append(out, &dto.Resource{
 			Group:     "apps",
 			Version:   "v1",
@@ -150,7 +278,7 @@ func (k *KubernetesClient) GetWorkloadsForModule(name string) ([]*dto.Resource,
 		})
 	}
 
-	statefulset, err := k.clientset.AppsV1().StatefulSets(k.moduleTargetNamespace).List(context.Background(), metav1.ListOptions{
+	statefulset, err := k.clientset.AppsV1().StatefulSets(resourcesNamespace).List(context.Background(), metav1.ListOptions{
 		LabelSelector: "cyclops.module=" + name,
 	})
 	if err != nil {
@@ -158,6 +286,10 @@ func (k *KubernetesClient) GetWorkloadsForModule(name string) ([]*dto.Resource,
 	}
 
 	for _, item := range statefulset.Items {
+		if !k.isModuleResource(item.Labels, namespace) {
+			continue
+		}
+
 		out = append(out, &dto.Resource{
 			Group:     "apps",
 			Version:   "v1",
@@ -167,7 +299,7 @@ func (k *KubernetesClient) GetWorkloadsForModule(name string) ([]*dto.Resource,
 		})
 	}
 
-	daemonsets, err := k.clientset.AppsV1().DaemonSets(k.moduleTargetNamespace).List(context.Background(), metav1.ListOptions{
+	daemonsets, err := k.clientset.AppsV1().DaemonSets(resourcesNamespace).List(context.Background(), metav1.ListOptions{
 		LabelSelector: "cyclops.module=" + name,
 	})
 	if err != nil {
@@ -175,6 +307,10 @@ func (k *KubernetesClient) GetWorkloadsForModule(name string) ([]*dto.Resource,
 	}
 
 	for _, item := range daemonsets.Items {
+		if !k.isModuleResource(item.Labels, namespace) {
+			continue
+		}
+
 		out = append(out, &dto.Resource{
 			Group:     "apps",
 			Version:   "v1",
@@ -187,8 +323,8 @@ func (k *KubernetesClient) GetWorkloadsForModule(name string) ([]*dto.Resource,
 	return out, nil
 }
 
-func (k *KubernetesClient) getManagedGVRs(moduleName string) ([]schema.GroupVersionResource, error) {
-	module, _ := k.GetModule(moduleName)
+func (k *KubernetesClient) getManagedGVRs(moduleNamespace, moduleName string) ([]schema.GroupVersionResource, error) {
+	module, _ := k.GetModule(moduleNamespace, moduleName)
 
 	if module != nil && len(module.Status.ManagedGVRs) != 0 {
 		existing := make([]schema.GroupVersionResource, 0, len(module.Status.ManagedGVRs))
@@ -320,18 +456,23 @@ func (k *KubernetesClient) GetDeletedResources(
 	return out, nil
 }
 
-func (k *KubernetesClient) GetModuleResourcesHealth(name string) (string, error) {
+func (k *KubernetesClient) GetModuleResourcesHealth(namespace, name string) (string, error) {
 	resourcesWithHealth := 0
+	resourcesNamespace := k.moduleResourcesNamespace(namespace, name)
 
-	deployments, err := k.clientset.AppsV1().Deployments(k.moduleTargetNamespace).List(context.Background(), metav1.ListOptions{
+	deployments, err := k.clientset.AppsV1().Deployments(resourcesNamespace).List(context.Background(), metav1.ListOptions{
 		LabelSelector: "cyclops.module=" + name,
 	})
 	if err != nil {
 		return statusUnknown, err
 	}
 
-	resourcesWithHealth += len(deployments.Items)
 	for _, item := range deployments.Items {
+		if !k.isModuleResource(item.Labels, namespace) {
+			continue
+		}
+
+		resourcesWithHealth++
 		if isDeploymentProgressing(item.Status.Conditions) {
 			return statusProgressing, nil
 		}
@@ -343,15 +484,19 @@ func (k *KubernetesClient) GetModuleResourcesHealth(name string) (string, error)
 		}
 	}
 
-	statefulsets, err := k.clientset.AppsV1().StatefulSets(k.moduleTargetNamespace).List(context.Background(), metav1.ListOptions{
+	statefulsets, err := k.clientset.AppsV1().StatefulSets(resourcesNamespace).List(context.Background(), metav1.ListOptions{
 		LabelSelector: "cyclops.module=" + name,
 	})
 	if err != nil {
 		return statusUnknown, err
 	}
 
-	resourcesWithHealth += len(statefulsets.Items)
 	for _, item := range statefulsets.Items {
+		if !k.isModuleResource(item.Labels, namespace) {
+			continue
+		}
+
+		resourcesWithHealth++
 		if isStatefulSetProgressing(item.Status, item.Spec.Replicas, item.Generation) {
 			return statusProgressing, nil
 		}
@@ -363,15 +508,19 @@ func (k *KubernetesClient) GetModuleResourcesHealth(name string) (string, error)
 		}
 	}
 
-	daemonsets, err := k.clientset.AppsV1().DaemonSets(k.moduleTargetNamespace).List(context.Background(), metav1.ListOptions{
+	daemonsets, err := k.clientset.AppsV1().DaemonSets(resourcesNamespace).List(context.Background(), metav1.ListOptions{
 		LabelSelector: "cyclops.module=" + name,
 	})
 	if err != nil {
 		return statusUnknown, err
 	}
 
-	resourcesWithHealth += len(daemonsets.Items)
 	for _, item := range daemonsets.Items {
+		if !k.isModuleResource(item.Labels, namespace) {
+			continue
+		}
+
+		resourcesWithHealth++
 		if item.Generation != item.Status.ObservedGeneration ||
 			item.Status.UpdatedNumberScheduled != item.Status.DesiredNumberScheduled ||
 			item.Status.NumberUnavailable != 0 {
@@ -379,29 +528,37 @@ func (k *KubernetesClient) GetModuleResourcesHealth(name string) (string, error)
 		}
 	}
 
-	pvcs, err := k.clientset.CoreV1().PersistentVolumeClaims(k.moduleTargetNamespace).List(context.Background(), metav1.ListOptions{
+	pvcs, err := k.clientset.CoreV1().PersistentVolumeClaims(resourcesNamespace).List(context.Background(), metav1.ListOptions{
 		LabelSelector: "cyclops.module=" + name,
 	})
 	if err != nil {
 		return statusUnknown, err
 	}
 
-	resourcesWithHealth += len(pvcs.Items)
 	for _, item := range pvcs.Items {
+		if !k.isModuleResource(item.Labels, namespace) {
+			continue
+		}
+
+		resourcesWithHealth++
 		if item.Status.Phase != apiv1.ClaimBound {
 			return statusUnhealthy, nil
 		}
 	}
 
-	pods, err := k.clientset.CoreV1().Pods(k.moduleTargetNamespace).List(context.Background(), metav1.ListOptions{
+	pods, err := k.clientset.CoreV1().Pods(resourcesNamespace).List(context.Background(), metav1.ListOptions{
 		LabelSelector: "cyclops.module=" + name,
 	})
 	if err != nil {
 		return statusUnknown, err
 	}
 
-	resourcesWithHealth += len(pods.Items)
 	for _, item := range pods.Items {
+		if !k.isModuleResource(item.Labels, namespace) {
+			continue
+		}
+
+		resourcesWithHealth++
 		for _, cnt := range item.Spec.Containers {
 			var status apiv1.ContainerStatus
 			for _, c := range item.Status.ContainerStatuses {
@@ -19,6 +19,7 @@ import (
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
@@ -75,16 +76,7 @@ func (k *KubernetesClient) RestartDaemonSet(name, namespace string) error {
 }
 
 func (k *KubernetesClient) GetManifest(group, version, kind, name, namespace string, includeManagedFields bool) (string, error) {
-	apiResourceName, err := k.GVKtoAPIResourceName(schema.GroupVersion{Group: group, Version: version}, kind)
-	if err != nil {
-		return "", err
-	}
-
-	resource, err := k.Dynamic.Resource(schema.GroupVersionResource{
-		Group:    group,
-		Version:  version,
-		Resource: apiResourceName,
-	}).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	resource, err := k.GetDynamic(group, version, kind, name, namespace)
 	if err != nil {
 		return "", err
 	}
@@ -101,6 +93,19 @@ func (k *KubernetesClient) GetManifest(group, version, kind, name, namespace str
 	return string(data), nil
 }
 
+func (k *KubernetesClient) GetDynamic(group, version, kind, name, namespace string) (*unstructured.Unstructured, error) {
+	apiResourceName, err := k.GVKtoAPIResourceName(schema.GroupVersion{Group: group, Version: version}, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.Dynamic.Resource(schema.GroupVersionResource{
+		Group:    group,
+		Version:  version,
+		Resource: apiResourceName,
+	}).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+}
+
 func (k *KubernetesClient) Restart(group, version, kind, name, namespace string) error {
 	switch {
 	case isDeployment(group, version, kind):
@@ -176,109 +181,127 @@ func (k *KubernetesClient) CreateDynamic(
 	resource v1alpha1.GroupVersionResource,
 	obj *unstructured.Unstructured,
 	targetNamespace string,
+	forceConflicts bool,
 ) error {
-	gvr := schema.GroupVersionResource{
-		Group:    resource.Group,
-		Version:  resource.Version,
-		Resource: resource.Resource,
+	resourceClient, err := k.resourceClientForObject(resource, obj, targetNamespace)
+	if err != nil {
+		return err
 	}
 
-	objNamespace := apiv1.NamespaceDefault
-
-	if len(strings.TrimSpace(targetNamespace)) != 0 {
-		objNamespace = strings.TrimSpace(targetNamespace)
+	if err := k.upgradeManagedFields(resourceClient, resource, obj); err != nil {
+		return err
 	}
 
-	if len(strings.TrimSpace(obj.GetNamespace())) != 0 {
-		objNamespace = obj.GetNamespace()
-	}
-	obj.SetNamespace(objNamespace)
+	_, err = resourceClient.Apply(
+		context.Background(),
+		obj.GetName(),
+		obj,
+		metav1.ApplyOptions{
+			FieldManager: fieldManager,
+			Force:        forceConflicts,
+		},
+	)
 
-	isNamespaced, err := k.isResourceNamespaced(obj.GroupVersionKind())
+	return err
+}
+
+// DeleteDynamic deletes the resource of obj from the namespace it would be applied to. Dependents, like pods
+// of a job, are deleted in the background. Deleting a resource that does not exist is not an error.
+func (k *KubernetesClient) DeleteDynamic(
+	resource v1alpha1.GroupVersionResource,
+	obj *unstructured.Unstructured,
+	targetNamespace string,
+) error {
+	resourceClient, err := k.resourceClientForObject(resource, obj, targetNamespace)
 	if err != nil {
 		return err
 	}
 
-	if !isNamespaced {
-		return k.createDynamicNonNamespaced(gvr, obj)
+	propagationPolicy := metav1.DeletePropagationBackground
+	err = resourceClient.Delete(context.Background(), obj.GetName(), metav1.DeleteOptions{
+		PropagationPolicy: &propagationPolicy,
+	})
+	if k8serrors.IsNotFound(err) {
+		return nil
 	}
 
-	return k.createDynamicNamespaced(gvr, objNamespace, obj)
+	return err
 }
 
-func (k *KubernetesClient) createDynamicNamespaced(
-	gvr schema.GroupVersionResource,
-	namespace string,
+// DryRunDynamic server-side applies obj in dry-run mode. It returns the current live object, or nil if it does
+// not exist, together with the object the API server would persist if obj was applied. Without forceConflicts,
+// fields owned by other field managers are returned as a conflict error, same as on a real apply.
+func (k *KubernetesClient) DryRunDynamic(
+	resource v1alpha1.GroupVersionResource,
 	obj *unstructured.Unstructured,
-) error {
-	current, err := k.Dynamic.Resource(gvr).Namespace(namespace).Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+	targetNamespace string,
+	forceConflicts bool,
+) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	resourceClient, err := k.resourceClientForObject(resource, obj, targetNamespace)
 	if err != nil {
-		if k8serrors.IsNotFound(err) {
-			_, err := k.Dynamic.Resource(gvr).Namespace(namespace).Create(
-				context.Background(),
-				obj,
-				metav1.CreateOptions{},
-			)
-
-			return err
-		}
-		return err
-	}
-
-	if isJob(obj.GroupVersionKind().Group, obj.GroupVersionKind().Version, obj.GroupVersionKind().Kind) {
-		if err := copyJobSelectors(current, obj); err != nil {
-			return err
-		}
+		return nil, nil, err
 	}
 
-	if isPersistentVolumeClaims(obj.GroupVersionKind().Group, obj.GroupVersionKind().Version, obj.GroupVersionKind().Kind) {
-		if err := mergePVCWithCurrent(current, obj); err != nil {
-			return err
+	live, err := resourceClient.Get(context.Background(), obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return nil, nil, err
 		}
+		live = nil
 	}
 
-	obj.SetResourceVersion(current.GetResourceVersion())
-	obj.SetAnnotations(mergeAnnotations(current.GetAnnotations(), obj.GetAnnotations()))
-	obj.SetFinalizers(current.GetFinalizers())
-
-	_, err = k.Dynamic.Resource(gvr).Namespace(namespace).Update(
+	applied, err := resourceClient.Apply(
 		context.Background(),
+		obj.GetName(),
 		obj,
-		metav1.UpdateOptions{},
+		metav1.ApplyOptions{
+			FieldManager: fieldManager,
+			Force:        forceConflicts,
+			DryRun:       []string{metav1.DryRunAll},
+		},
 	)
+	if err != nil {
+		return live, nil, err
+	}
 
-	return err
+	return live, applied, nil
 }
 
-func (k *KubernetesClient) createDynamicNonNamespaced(
-	gvr schema.GroupVersionResource,
+// resourceClientForObject sets the namespace obj will be applied to and returns a client for its resource.
+// Objects without a namespace end up in the target namespace, or the default namespace if none is set.
+func (k *KubernetesClient) resourceClientForObject(
+	resource v1alpha1.GroupVersionResource,
 	obj *unstructured.Unstructured,
-) error {
-	current, err := k.Dynamic.Resource(gvr).Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
-	if err != nil {
-		if k8serrors.IsNotFound(err) {
-			_, err := k.Dynamic.Resource(gvr).Create(
-				context.Background(),
-				obj,
-				metav1.CreateOptions{},
-			)
+	targetNamespace string,
+) (dynamic.ResourceInterface, error) {
+	gvr := schema.GroupVersionResource{
+		Group:    resource.Group,
+		Version:  resource.Version,
+		Resource: resource.Resource,
+	}
 
-			return err
-		}
-		return err
+	objNamespace := apiv1.NamespaceDefault
+
+	if len(strings.TrimSpace(targetNamespace)) != 0 {
+		objNamespace = strings.TrimSpace(targetNamespace)
+	}
+
+	if len(strings.TrimSpace(obj.GetNamespace())) != 0 {
+		objNamespace = obj.GetNamespace()
 	}
+	obj.SetNamespace(objNamespace)
 
-	obj.SetResourceVersion(current.GetResourceVersion())
-	obj.SetAnnotations(mergeAnnotations(current.GetAnnotations(), obj.GetAnnotations()))
-	obj.SetFinalizers(current.GetFinalizers())
+	isNamespaced, err := k.isResourceNamespaced(obj.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
 
-	_, err = k.Dynamic.Resource(gvr).Update(
-		context.Background(),
-		obj,
-		metav1.UpdateOptions{},
-	)
+	if !isNamespaced {
+		obj.SetNamespace("")
+		return k.Dynamic.Resource(gvr), nil
+	}
 
-	return err
+	return k.Dynamic.Resource(gvr).Namespace(objNamespace), nil
 }
 
 func (k *KubernetesClient) ApplyCRD(obj *unstructured.Unstructured) error {
@@ -293,65 +316,33 @@ func (k *KubernetesClient) ApplyCRD(obj *unstructured.Unstructured) error {
 		obj.GetName(),
 		obj,
 		metav1.ApplyOptions{
-			FieldManager: "cyclops-ctrl",
+			FieldManager: fieldManager,
 		},
 	)
 
 	return err
 }
 
-func copyJobSelectors(source, destination *unstructured.Unstructured) error {
-	selectors, ok, err := unstructured.NestedMap(source.Object, "spec", "selector")
-	if err != nil {
-		return err
-	}
-	if !ok {
-		return errors.New(fmt.Sprintf("job %v selectors not found", source.GetName()))
-	}
-
-	templateLabels, ok, err := unstructured.NestedMap(source.Object, "spec", "template", "metadata", "labels")
-	if err != nil {
-		return err
-	}
-	if !ok {
-		return errors.New(fmt.Sprintf("job %v selectors not found", source.GetName()))
-	}
-
-	if err := unstructured.SetNestedMap(destination.Object, selectors, "spec", "selector"); err != nil {
-		return err
-	}
-
-	return unstructured.SetNestedMap(destination.Object, templateLabels, "spec", "template", "metadata", "labels")
-}
-
-func mergePVCWithCurrent(current, obj *unstructured.Unstructured) error {
-	requests, ok, err := unstructured.NestedMap(obj.Object, "spec", "resources", "requests")
-	if err != nil {
-		return err
+// FieldManagerConflicts returns the fields that could not be applied because they are owned by another field manager.
+// It returns nil if err is not a server-side apply conflict.
+func FieldManagerConflicts(err error) []metav1.StatusCause {
+	if !k8serrors.IsConflict(err) {
+		return nil
 	}
-	if !ok {
-		return fmt.Errorf("PVC %v spec.resources.requests not found", obj.GetName())
-	}
-
-	for key, value := range current.Object {
-		obj.Object[key] = value
-	}
-
-	return unstructured.SetNestedMap(current.Object, requests, "spec", "resources", "requests")
-}
-
-func mergeAnnotations(existing, new map[string]string) map[string]string {
-	out := make(map[string]string)
 
-	for k, v := range existing {
-		out[k] = v
+	var statusErr k8serrors.APIStatus
+	if !errors.As(err, &statusErr) || statusErr.Status().Details == nil {
+		return nil
 	}
 
-	for k, v := range new {
-		out[k] = v
+	conflicts := make([]metav1.StatusCause, 0, len(statusErr.Status().Details.Causes))
+	for _, cause := range statusErr.Status().Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			conflicts = append(conflicts, cause)
+		}
 	}
 
-	return out
+	return conflicts
 }
 
 func (k *KubernetesClient) ListNodes() ([]apiv1.Node, error) {
@@ -554,3 +545,55 @@ func (k *KubernetesClient) WatchKubernetesResources(gvrs []ResourceWatchSpec, st
 
 	return eventChan, nil
 }
+
+// WatchModuleResources starts an informer for resources of the given GVR that belong to a Cyclops module and
+// calls onChange whenever they are changed or deleted, until stopCh is closed. Resources are watched in all
+// namespaces, since modules can be deployed to any target namespace. Updates that do not change anything besides
+// the resource status are skipped. onChange must not block.
+func (k *KubernetesClient) WatchModuleResources(
+	gvr schema.GroupVersionResource,
+	onChange func(*unstructured.Unstructured),
+	stopCh <-chan struct{},
+) {
+	resourceClient := k.Dynamic.Resource(gvr)
+
+	informer := cache.NewSharedInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				options.LabelSelector = "cyclops.module"
+				return resourceClient.List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				options.LabelSelector = "cyclops.module"
+				return resourceClient.Watch(context.TODO(), options)
+			},
+		},
+		&unstructured.Unstructured{},
+		0,
+	)
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if len(DiffObjects(oldObj.(*unstructured.Unstructured), newObj.(*unstructured.Unstructured))) == 0 {
+				return
+			}
+
+			onChange(newObj.(*unstructured.Unstructured))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				onChange(u)
+			}
+		},
+	})
+	if err != nil {
+		k.logger.Error(err, "failed to watch module resources", "gvr", gvr.String())
+		return
+	}
+
+	go informer.Run(stopCh)
+}
@@ -0,0 +1,46 @@
+package k8sclient
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// GetValuesReference returns the value of the ConfigMap or Secret key referenced by module values. The returned
+// bool is false if the object or the key do not exist.
+func (k *KubernetesClient) GetValuesReference(namespace string, reference cyclopsv1alpha1.ValuesReference) (string, bool, error) {
+	switch reference.Kind {
+	case cyclopsv1alpha1.ValuesReferenceKindConfigMap:
+		configMap, err := k.clientset.CoreV1().ConfigMaps(namespace).Get(context.Background(), reference.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+
+		if value, ok := configMap.Data[reference.Key]; ok {
+			return value, true, nil
+		}
+
+		value, ok := configMap.BinaryData[reference.Key]
+		return string(value), ok, nil
+	case cyclopsv1alpha1.ValuesReferenceKindSecret:
+		secret, err := k.clientset.CoreV1().Secrets(namespace).Get(context.Background(), reference.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+
+		value, ok := secret.Data[reference.Key]
+		return string(value), ok, nil
+	default:
+		return "", false, errors.Errorf("unsupported values reference kind %v", reference.Kind)
+	}
+}
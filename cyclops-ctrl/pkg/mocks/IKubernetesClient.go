@@ -10,6 +10,8 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	dynamic "k8s.io/client-go/dynamic"
+
 	remotecommand "k8s.io/client-go/tools/remotecommand"
 
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -38,6 +40,63 @@ func (_m *IKubernetesClient) EXPECT() *IKubernetesClient_Expecter {
 	return &IKubernetesClient_Expecter{mock: &_m.Mock}
 }
 
+// APIVersions provides a mock function with no fields
+func (_m *IKubernetesClient) APIVersions() ([]string, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for APIVersions")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]string, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IKubernetesClient_APIVersions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'APIVersions'
+type IKubernetesClient_APIVersions_Call struct {
+	*mock.Call
+}
+
+// APIVersions is a helper method to define mock.On call
+func (_e *IKubernetesClient_Expecter) APIVersions() *IKubernetesClient_APIVersions_Call {
+	return &IKubernetesClient_APIVersions_Call{Call: _e.mock.On("APIVersions")}
+}
+
+func (_c *IKubernetesClient_APIVersions_Call) Run(run func()) *IKubernetesClient_APIVersions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_APIVersions_Call) Return(_a0 []string, _a1 error) *IKubernetesClient_APIVersions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IKubernetesClient_APIVersions_Call) RunAndReturn(run func() ([]string, error)) *IKubernetesClient_APIVersions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ApplyCRD provides a mock function with given fields: obj
 func (_m *IKubernetesClient) ApplyCRD(obj *unstructured.Unstructured) error {
 	ret := _m.Called(obj)
@@ -144,17 +203,17 @@ func (_c *IKubernetesClient_CommandExecutor_Call) RunAndReturn(run func(string,
 	return _c
 }
 
-// CreateDynamic provides a mock function with given fields: _a0, _a1, _a2
-func (_m *IKubernetesClient) CreateDynamic(_a0 v1alpha1.GroupVersionResource, _a1 *unstructured.Unstructured, _a2 string) error {
-	ret := _m.Called(_a0, _a1, _a2)
+// CreateDynamic provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *IKubernetesClient) CreateDynamic(_a0 v1alpha1.GroupVersionResource, _a1 *unstructured.Unstructured, _a2 string, _a3 bool) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateDynamic")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string) error); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -171,13 +230,14 @@ type IKubernetesClient_CreateDynamic_Call struct {
 //   - _a0 v1alpha1.GroupVersionResource
 //   - _a1 *unstructured.Unstructured
 //   - _a2 string
-func (_e *IKubernetesClient_Expecter) CreateDynamic(_a0 interface{}, _a1 interface{}, _a2 interface{}) *IKubernetesClient_CreateDynamic_Call {
-	return &IKubernetesClient_CreateDynamic_Call{Call: _e.mock.On("CreateDynamic", _a0, _a1, _a2)}
+//   - _a3 bool
+func (_e *IKubernetesClient_Expecter) CreateDynamic(_a0 interface{}, _a1 interface{}, _a2 interface{}, _a3 interface{}) *IKubernetesClient_CreateDynamic_Call {
+	return &IKubernetesClient_CreateDynamic_Call{Call: _e.mock.On("CreateDynamic", _a0, _a1, _a2, _a3)}
 }
 
-func (_c *IKubernetesClient_CreateDynamic_Call) Run(run func(_a0 v1alpha1.GroupVersionResource, _a1 *unstructured.Unstructured, _a2 string)) *IKubernetesClient_CreateDynamic_Call {
+func (_c *IKubernetesClient_CreateDynamic_Call) Run(run func(_a0 v1alpha1.GroupVersionResource, _a1 *unstructured.Unstructured, _a2 string, _a3 bool)) *IKubernetesClient_CreateDynamic_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(v1alpha1.GroupVersionResource), args[1].(*unstructured.Unstructured), args[2].(string))
+		run(args[0].(v1alpha1.GroupVersionResource), args[1].(*unstructured.Unstructured), args[2].(string), args[3].(bool))
 	})
 	return _c
 }
@@ -187,7 +247,7 @@ func (_c *IKubernetesClient_CreateDynamic_Call) Return(_a0 error) *IKubernetesCl
 	return _c
 }
 
-func (_c *IKubernetesClient_CreateDynamic_Call) RunAndReturn(run func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string) error) *IKubernetesClient_CreateDynamic_Call {
+func (_c *IKubernetesClient_CreateDynamic_Call) RunAndReturn(run func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) error) *IKubernetesClient_CreateDynamic_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -330,17 +390,65 @@ func (_c *IKubernetesClient_Delete_Call) RunAndReturn(run func(*dto.Resource) er
 	return _c
 }
 
-// DeleteModule provides a mock function with given fields: name
-func (_m *IKubernetesClient) DeleteModule(name string) error {
-	ret := _m.Called(name)
+// DeleteDynamic provides a mock function with given fields: resource, obj, targetNamespace
+func (_m *IKubernetesClient) DeleteDynamic(resource v1alpha1.GroupVersionResource, obj *unstructured.Unstructured, targetNamespace string) error {
+	ret := _m.Called(resource, obj, targetNamespace)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDynamic")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string) error); ok {
+		r0 = rf(resource, obj, targetNamespace)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IKubernetesClient_DeleteDynamic_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteDynamic'
+type IKubernetesClient_DeleteDynamic_Call struct {
+	*mock.Call
+}
+
+// DeleteDynamic is a helper method to define mock.On call
+//   - resource v1alpha1.GroupVersionResource
+//   - obj *unstructured.Unstructured
+//   - targetNamespace string
+func (_e *IKubernetesClient_Expecter) DeleteDynamic(resource interface{}, obj interface{}, targetNamespace interface{}) *IKubernetesClient_DeleteDynamic_Call {
+	return &IKubernetesClient_DeleteDynamic_Call{Call: _e.mock.On("DeleteDynamic", resource, obj, targetNamespace)}
+}
+
+func (_c *IKubernetesClient_DeleteDynamic_Call) Run(run func(resource v1alpha1.GroupVersionResource, obj *unstructured.Unstructured, targetNamespace string)) *IKubernetesClient_DeleteDynamic_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(v1alpha1.GroupVersionResource), args[1].(*unstructured.Unstructured), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_DeleteDynamic_Call) Return(_a0 error) *IKubernetesClient_DeleteDynamic_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IKubernetesClient_DeleteDynamic_Call) RunAndReturn(run func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string) error) *IKubernetesClient_DeleteDynamic_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteModule provides a mock function with given fields: namespace, name
+func (_m *IKubernetesClient) DeleteModule(namespace string, name string) error {
+	ret := _m.Called(namespace, name)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteModule")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(namespace, name)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -354,14 +462,15 @@ type IKubernetesClient_DeleteModule_Call struct {
 }
 
 // DeleteModule is a helper method to define mock.On call
+//   - namespace string
 //   - name string
-func (_e *IKubernetesClient_Expecter) DeleteModule(name interface{}) *IKubernetesClient_DeleteModule_Call {
-	return &IKubernetesClient_DeleteModule_Call{Call: _e.mock.On("DeleteModule", name)}
+func (_e *IKubernetesClient_Expecter) DeleteModule(namespace interface{}, name interface{}) *IKubernetesClient_DeleteModule_Call {
+	return &IKubernetesClient_DeleteModule_Call{Call: _e.mock.On("DeleteModule", namespace, name)}
 }
 
-func (_c *IKubernetesClient_DeleteModule_Call) Run(run func(name string)) *IKubernetesClient_DeleteModule_Call {
+func (_c *IKubernetesClient_DeleteModule_Call) Run(run func(namespace string, name string)) *IKubernetesClient_DeleteModule_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].(string))
 	})
 	return _c
 }
@@ -371,7 +480,7 @@ func (_c *IKubernetesClient_DeleteModule_Call) Return(_a0 error) *IKubernetesCli
 	return _c
 }
 
-func (_c *IKubernetesClient_DeleteModule_Call) RunAndReturn(run func(string) error) *IKubernetesClient_DeleteModule_Call {
+func (_c *IKubernetesClient_DeleteModule_Call) RunAndReturn(run func(string, string) error) *IKubernetesClient_DeleteModule_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -469,6 +578,165 @@ func (_c *IKubernetesClient_DeleteTemplateStore_Call) RunAndReturn(run func(stri
 	return _c
 }
 
+// DryRunDynamic provides a mock function with given fields: resource, obj, targetNamespace, forceConflicts
+func (_m *IKubernetesClient) DryRunDynamic(resource v1alpha1.GroupVersionResource, obj *unstructured.Unstructured, targetNamespace string, forceConflicts bool) (*unstructured.Unstructured, *unstructured.Unstructured, error) {
+	ret := _m.Called(resource, obj, targetNamespace, forceConflicts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DryRunDynamic")
+	}
+
+	var r0 *unstructured.Unstructured
+	var r1 *unstructured.Unstructured
+	var r2 error
+	if rf, ok := ret.Get(0).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) (*unstructured.Unstructured, *unstructured.Unstructured, error)); ok {
+		return rf(resource, obj, targetNamespace, forceConflicts)
+	}
+	if rf, ok := ret.Get(0).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) *unstructured.Unstructured); ok {
+		r0 = rf(resource, obj, targetNamespace, forceConflicts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*unstructured.Unstructured)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) *unstructured.Unstructured); ok {
+		r1 = rf(resource, obj, targetNamespace, forceConflicts)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*unstructured.Unstructured)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) error); ok {
+		r2 = rf(resource, obj, targetNamespace, forceConflicts)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// IKubernetesClient_DryRunDynamic_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DryRunDynamic'
+type IKubernetesClient_DryRunDynamic_Call struct {
+	*mock.Call
+}
+
+// DryRunDynamic is a helper method to define mock.On call
+//   - resource v1alpha1.GroupVersionResource
+//   - obj *unstructured.Unstructured
+//   - targetNamespace string
+//   - forceConflicts bool
+func (_e *IKubernetesClient_Expecter) DryRunDynamic(resource interface{}, obj interface{}, targetNamespace interface{}, forceConflicts interface{}) *IKubernetesClient_DryRunDynamic_Call {
+	return &IKubernetesClient_DryRunDynamic_Call{Call: _e.mock.On("DryRunDynamic", resource, obj, targetNamespace, forceConflicts)}
+}
+
+func (_c *IKubernetesClient_DryRunDynamic_Call) Run(run func(resource v1alpha1.GroupVersionResource, obj *unstructured.Unstructured, targetNamespace string, forceConflicts bool)) *IKubernetesClient_DryRunDynamic_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(v1alpha1.GroupVersionResource), args[1].(*unstructured.Unstructured), args[2].(string), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_DryRunDynamic_Call) Return(_a0 *unstructured.Unstructured, _a1 *unstructured.Unstructured, _a2 error) *IKubernetesClient_DryRunDynamic_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *IKubernetesClient_DryRunDynamic_Call) RunAndReturn(run func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string, bool) (*unstructured.Unstructured, *unstructured.Unstructured, error)) *IKubernetesClient_DryRunDynamic_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ForCluster provides a mock function with given fields: name
+func (_m *IKubernetesClient) ForCluster(name string) (k8sclient.IKubernetesClient, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForCluster")
+	}
+
+	var r0 k8sclient.IKubernetesClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (k8sclient.IKubernetesClient, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) k8sclient.IKubernetesClient); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Get(0).(k8sclient.IKubernetesClient)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IKubernetesClient_ForCluster_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ForCluster'
+type IKubernetesClient_ForCluster_Call struct {
+	*mock.Call
+}
+
+// ForCluster is a helper method to define mock.On call
+//   - name string
+func (_e *IKubernetesClient_Expecter) ForCluster(name interface{}) *IKubernetesClient_ForCluster_Call {
+	return &IKubernetesClient_ForCluster_Call{Call: _e.mock.On("ForCluster", name)}
+}
+
+func (_c *IKubernetesClient_ForCluster_Call) Run(run func(name string)) *IKubernetesClient_ForCluster_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_ForCluster_Call) Return(_a0 k8sclient.IKubernetesClient, _a1 error) *IKubernetesClient_ForCluster_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IKubernetesClient_ForCluster_Call) RunAndReturn(run func(string) (k8sclient.IKubernetesClient, error)) *IKubernetesClient_ForCluster_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ForgetCluster provides a mock function with given fields: name
+func (_m *IKubernetesClient) ForgetCluster(name string) {
+	_m.Called(name)
+}
+
+// IKubernetesClient_ForgetCluster_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ForgetCluster'
+type IKubernetesClient_ForgetCluster_Call struct {
+	*mock.Call
+}
+
+// ForgetCluster is a helper method to define mock.On call
+//   - name string
+func (_e *IKubernetesClient_Expecter) ForgetCluster(name interface{}) *IKubernetesClient_ForgetCluster_Call {
+	return &IKubernetesClient_ForgetCluster_Call{Call: _e.mock.On("ForgetCluster", name)}
+}
+
+func (_c *IKubernetesClient_ForgetCluster_Call) Run(run func(name string)) *IKubernetesClient_ForgetCluster_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_ForgetCluster_Call) Return() *IKubernetesClient_ForgetCluster_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IKubernetesClient_ForgetCluster_Call) RunAndReturn(run func(string)) *IKubernetesClient_ForgetCluster_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GVKtoAPIResourceName provides a mock function with given fields: gv, kind
 func (_m *IKubernetesClient) GVKtoAPIResourceName(gv schema.GroupVersion, kind string) (string, error) {
 	ret := _m.Called(gv, kind)
@@ -647,6 +915,68 @@ func (_c *IKubernetesClient_GetDeploymentLogs_Call) RunAndReturn(run func(string
 	return _c
 }
 
+// GetDynamic provides a mock function with given fields: group, _a1, kind, name, namespace
+func (_m *IKubernetesClient) GetDynamic(group string, _a1 string, kind string, name string, namespace string) (*unstructured.Unstructured, error) {
+	ret := _m.Called(group, _a1, kind, name, namespace)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDynamic")
+	}
+
+	var r0 *unstructured.Unstructured
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string) (*unstructured.Unstructured, error)); ok {
+		return rf(group, _a1, kind, name, namespace)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string) *unstructured.Unstructured); ok {
+		r0 = rf(group, _a1, kind, name, namespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*unstructured.Unstructured)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string, string) error); ok {
+		r1 = rf(group, _a1, kind, name, namespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IKubernetesClient_GetDynamic_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDynamic'
+type IKubernetesClient_GetDynamic_Call struct {
+	*mock.Call
+}
+
+// GetDynamic is a helper method to define mock.On call
+//   - group string
+//   - _a1 string
+//   - kind string
+//   - name string
+//   - namespace string
+func (_e *IKubernetesClient_Expecter) GetDynamic(group interface{}, _a1 interface{}, kind interface{}, name interface{}, namespace interface{}) *IKubernetesClient_GetDynamic_Call {
+	return &IKubernetesClient_GetDynamic_Call{Call: _e.mock.On("GetDynamic", group, _a1, kind, name, namespace)}
+}
+
+func (_c *IKubernetesClient_GetDynamic_Call) Run(run func(group string, _a1 string, kind string, name string, namespace string)) *IKubernetesClient_GetDynamic_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string), args[3].(string), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_GetDynamic_Call) Return(_a0 *unstructured.Unstructured, _a1 error) *IKubernetesClient_GetDynamic_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IKubernetesClient_GetDynamic_Call) RunAndReturn(run func(string, string, string, string, string) (*unstructured.Unstructured, error)) *IKubernetesClient_GetDynamic_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetManifest provides a mock function with given fields: group, _a1, kind, name, namespace, includeManagedFields
 func (_m *IKubernetesClient) GetManifest(group string, _a1 string, kind string, name string, namespace string, includeManagedFields bool) (string, error) {
 	ret := _m.Called(group, _a1, kind, name, namespace, includeManagedFields)
@@ -708,9 +1038,9 @@ func (_c *IKubernetesClient_GetManifest_Call) RunAndReturn(run func(string, stri
 	return _c
 }
 
-// GetModule provides a mock function with given fields: name
-func (_m *IKubernetesClient) GetModule(name string) (*v1alpha1.Module, error) {
-	ret := _m.Called(name)
+// GetModule provides a mock function with given fields: namespace, name
+func (_m *IKubernetesClient) GetModule(namespace string, name string) (*v1alpha1.Module, error) {
+	ret := _m.Called(namespace, name)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetModule")
@@ -718,19 +1048,19 @@ func (_m *IKubernetesClient) GetModule(name string) (*v1alpha1.Module, error) {
 
 	var r0 *v1alpha1.Module
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*v1alpha1.Module, error)); ok {
-		return rf(name)
+	if rf, ok := ret.Get(0).(func(string, string) (*v1alpha1.Module, error)); ok {
+		return rf(namespace, name)
 	}
-	if rf, ok := ret.Get(0).(func(string) *v1alpha1.Module); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(string, string) *v1alpha1.Module); ok {
+		r0 = rf(namespace, name)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*v1alpha1.Module)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(name)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(namespace, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -744,14 +1074,15 @@ type IKubernetesClient_GetModule_Call struct {
 }
 
 // GetModule is a helper method to define mock.On call
+//   - namespace string
 //   - name string
-func (_e *IKubernetesClient_Expecter) GetModule(name interface{}) *IKubernetesClient_GetModule_Call {
-	return &IKubernetesClient_GetModule_Call{Call: _e.mock.On("GetModule", name)}
+func (_e *IKubernetesClient_Expecter) GetModule(namespace interface{}, name interface{}) *IKubernetesClient_GetModule_Call {
+	return &IKubernetesClient_GetModule_Call{Call: _e.mock.On("GetModule", namespace, name)}
 }
 
-func (_c *IKubernetesClient_GetModule_Call) Run(run func(name string)) *IKubernetesClient_GetModule_Call {
+func (_c *IKubernetesClient_GetModule_Call) Run(run func(namespace string, name string)) *IKubernetesClient_GetModule_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].(string))
 	})
 	return _c
 }
@@ -761,14 +1092,73 @@ func (_c *IKubernetesClient_GetModule_Call) Return(_a0 *v1alpha1.Module, _a1 err
 	return _c
 }
 
-func (_c *IKubernetesClient_GetModule_Call) RunAndReturn(run func(string) (*v1alpha1.Module, error)) *IKubernetesClient_GetModule_Call {
+func (_c *IKubernetesClient_GetModule_Call) RunAndReturn(run func(string, string) (*v1alpha1.Module, error)) *IKubernetesClient_GetModule_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetModuleResourcesHealth provides a mock function with given fields: name
-func (_m *IKubernetesClient) GetModuleResourcesHealth(name string) (string, error) {
-	ret := _m.Called(name)
+// GetModuleEvents provides a mock function with given fields: namespace, name
+func (_m *IKubernetesClient) GetModuleEvents(namespace string, name string) ([]v1.Event, error) {
+	ret := _m.Called(namespace, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetModuleEvents")
+	}
+
+	var r0 []v1.Event
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]v1.Event, error)); ok {
+		return rf(namespace, name)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []v1.Event); ok {
+		r0 = rf(namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]v1.Event)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IKubernetesClient_GetModuleEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetModuleEvents'
+type IKubernetesClient_GetModuleEvents_Call struct {
+	*mock.Call
+}
+
+// GetModuleEvents is a helper method to define mock.On call
+//   - namespace string
+//   - name string
+func (_e *IKubernetesClient_Expecter) GetModuleEvents(namespace interface{}, name interface{}) *IKubernetesClient_GetModuleEvents_Call {
+	return &IKubernetesClient_GetModuleEvents_Call{Call: _e.mock.On("GetModuleEvents", namespace, name)}
+}
+
+func (_c *IKubernetesClient_GetModuleEvents_Call) Run(run func(namespace string, name string)) *IKubernetesClient_GetModuleEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_GetModuleEvents_Call) Return(_a0 []v1.Event, _a1 error) *IKubernetesClient_GetModuleEvents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IKubernetesClient_GetModuleEvents_Call) RunAndReturn(run func(string, string) ([]v1.Event, error)) *IKubernetesClient_GetModuleEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetModuleResourcesHealth provides a mock function with given fields: namespace, name
+func (_m *IKubernetesClient) GetModuleResourcesHealth(namespace string, name string) (string, error) {
+	ret := _m.Called(namespace, name)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetModuleResourcesHealth")
@@ -776,17 +1166,17 @@ func (_m *IKubernetesClient) GetModuleResourcesHealth(name string) (string, erro
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
-		return rf(name)
+	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
+		return rf(namespace, name)
 	}
-	if rf, ok := ret.Get(0).(func(string) string); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(namespace, name)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(name)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(namespace, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -800,14 +1190,15 @@ type IKubernetesClient_GetModuleResourcesHealth_Call struct {
 }
 
 // GetModuleResourcesHealth is a helper method to define mock.On call
+//   - namespace string
 //   - name string
-func (_e *IKubernetesClient_Expecter) GetModuleResourcesHealth(name interface{}) *IKubernetesClient_GetModuleResourcesHealth_Call {
-	return &IKubernetesClient_GetModuleResourcesHealth_Call{Call: _e.mock.On("GetModuleResourcesHealth", name)}
+func (_e *IKubernetesClient_Expecter) GetModuleResourcesHealth(namespace interface{}, name interface{}) *IKubernetesClient_GetModuleResourcesHealth_Call {
+	return &IKubernetesClient_GetModuleResourcesHealth_Call{Call: _e.mock.On("GetModuleResourcesHealth", namespace, name)}
 }
 
-func (_c *IKubernetesClient_GetModuleResourcesHealth_Call) Run(run func(name string)) *IKubernetesClient_GetModuleResourcesHealth_Call {
+func (_c *IKubernetesClient_GetModuleResourcesHealth_Call) Run(run func(namespace string, name string)) *IKubernetesClient_GetModuleResourcesHealth_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].(string))
 	})
 	return _c
 }
@@ -817,7 +1208,7 @@ func (_c *IKubernetesClient_GetModuleResourcesHealth_Call) Return(_a0 string, _a
 	return _c
 }
 
-func (_c *IKubernetesClient_GetModuleResourcesHealth_Call) RunAndReturn(run func(string) (string, error)) *IKubernetesClient_GetModuleResourcesHealth_Call {
+func (_c *IKubernetesClient_GetModuleResourcesHealth_Call) RunAndReturn(run func(string, string) (string, error)) *IKubernetesClient_GetModuleResourcesHealth_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1012,58 +1403,123 @@ func (_m *IKubernetesClient) GetResource(group string, _a1 string, kind string,
 	if rf, ok := ret.Get(0).(func(string, string, string, string, string) (interface{}, error)); ok {
 		return rf(group, _a1, kind, name, namespace)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, string, string, string) interface{}); ok {
-		r0 = rf(group, _a1, kind, name, namespace)
+	if rf, ok := ret.Get(0).(func(string, string, string, string, string) interface{}); ok {
+		r0 = rf(group, _a1, kind, name, namespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interface{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string, string) error); ok {
+		r1 = rf(group, _a1, kind, name, namespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IKubernetesClient_GetResource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetResource'
+type IKubernetesClient_GetResource_Call struct {
+	*mock.Call
+}
+
+// GetResource is a helper method to define mock.On call
+//   - group string
+//   - _a1 string
+//   - kind string
+//   - name string
+//   - namespace string
+func (_e *IKubernetesClient_Expecter) GetResource(group interface{}, _a1 interface{}, kind interface{}, name interface{}, namespace interface{}) *IKubernetesClient_GetResource_Call {
+	return &IKubernetesClient_GetResource_Call{Call: _e.mock.On("GetResource", group, _a1, kind, name, namespace)}
+}
+
+func (_c *IKubernetesClient_GetResource_Call) Run(run func(group string, _a1 string, kind string, name string, namespace string)) *IKubernetesClient_GetResource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string), args[3].(string), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_GetResource_Call) Return(_a0 interface{}, _a1 error) *IKubernetesClient_GetResource_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IKubernetesClient_GetResource_Call) RunAndReturn(run func(string, string, string, string, string) (interface{}, error)) *IKubernetesClient_GetResource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetResourceHealth provides a mock function with given fields: resource, obj, targetNamespace
+func (_m *IKubernetesClient) GetResourceHealth(resource v1alpha1.GroupVersionResource, obj *unstructured.Unstructured, targetNamespace string) (string, string, error) {
+	ret := _m.Called(resource, obj, targetNamespace)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetResourceHealth")
+	}
+
+	var r0 string
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string) (string, string, error)); ok {
+		return rf(resource, obj, targetNamespace)
+	}
+	if rf, ok := ret.Get(0).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string) string); ok {
+		r0 = rf(resource, obj, targetNamespace)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string) string); ok {
+		r1 = rf(resource, obj, targetNamespace)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(interface{})
-		}
+		r1 = ret.Get(1).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, string, string, string) error); ok {
-		r1 = rf(group, _a1, kind, name, namespace)
+	if rf, ok := ret.Get(2).(func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string) error); ok {
+		r2 = rf(resource, obj, targetNamespace)
 	} else {
-		r1 = ret.Error(1)
+		r2 = ret.Error(2)
 	}
 
-	return r0, r1
+	return r0, r1, r2
 }
 
-// IKubernetesClient_GetResource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetResource'
-type IKubernetesClient_GetResource_Call struct {
+// IKubernetesClient_GetResourceHealth_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetResourceHealth'
+type IKubernetesClient_GetResourceHealth_Call struct {
 	*mock.Call
 }
 
-// GetResource is a helper method to define mock.On call
-//   - group string
-//   - _a1 string
-//   - kind string
-//   - name string
-//   - namespace string
-func (_e *IKubernetesClient_Expecter) GetResource(group interface{}, _a1 interface{}, kind interface{}, name interface{}, namespace interface{}) *IKubernetesClient_GetResource_Call {
-	return &IKubernetesClient_GetResource_Call{Call: _e.mock.On("GetResource", group, _a1, kind, name, namespace)}
+// GetResourceHealth is a helper method to define mock.On call
+//   - resource v1alpha1.GroupVersionResource
+//   - obj *unstructured.Unstructured
+//   - targetNamespace string
+func (_e *IKubernetesClient_Expecter) GetResourceHealth(resource interface{}, obj interface{}, targetNamespace interface{}) *IKubernetesClient_GetResourceHealth_Call {
+	return &IKubernetesClient_GetResourceHealth_Call{Call: _e.mock.On("GetResourceHealth", resource, obj, targetNamespace)}
 }
 
-func (_c *IKubernetesClient_GetResource_Call) Run(run func(group string, _a1 string, kind string, name string, namespace string)) *IKubernetesClient_GetResource_Call {
+func (_c *IKubernetesClient_GetResourceHealth_Call) Run(run func(resource v1alpha1.GroupVersionResource, obj *unstructured.Unstructured, targetNamespace string)) *IKubernetesClient_GetResourceHealth_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string), args[2].(string), args[3].(string), args[4].(string))
+		run(args[0].(v1alpha1.GroupVersionResource), args[1].(*unstructured.Unstructured), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *IKubernetesClient_GetResource_Call) Return(_a0 interface{}, _a1 error) *IKubernetesClient_GetResource_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *IKubernetesClient_GetResourceHealth_Call) Return(_a0 string, _a1 string, _a2 error) *IKubernetesClient_GetResourceHealth_Call {
+	_c.Call.Return(_a0, _a1, _a2)
 	return _c
 }
 
-func (_c *IKubernetesClient_GetResource_Call) RunAndReturn(run func(string, string, string, string, string) (interface{}, error)) *IKubernetesClient_GetResource_Call {
+func (_c *IKubernetesClient_GetResourceHealth_Call) RunAndReturn(run func(v1alpha1.GroupVersionResource, *unstructured.Unstructured, string) (string, string, error)) *IKubernetesClient_GetResourceHealth_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetResourcesForModule provides a mock function with given fields: name
-func (_m *IKubernetesClient) GetResourcesForModule(name string) ([]*dto.Resource, error) {
-	ret := _m.Called(name)
+// GetResourcesForModule provides a mock function with given fields: namespace, name
+func (_m *IKubernetesClient) GetResourcesForModule(namespace string, name string) ([]*dto.Resource, error) {
+	ret := _m.Called(namespace, name)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetResourcesForModule")
@@ -1071,19 +1527,19 @@ func (_m *IKubernetesClient) GetResourcesForModule(name string) ([]*dto.Resource
 
 	var r0 []*dto.Resource
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*dto.Resource, error)); ok {
-		return rf(name)
+	if rf, ok := ret.Get(0).(func(string, string) ([]*dto.Resource, error)); ok {
+		return rf(namespace, name)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*dto.Resource); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(string, string) []*dto.Resource); ok {
+		r0 = rf(namespace, name)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*dto.Resource)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(name)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(namespace, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1097,14 +1553,15 @@ type IKubernetesClient_GetResourcesForModule_Call struct {
 }
 
 // GetResourcesForModule is a helper method to define mock.On call
+//   - namespace string
 //   - name string
-func (_e *IKubernetesClient_Expecter) GetResourcesForModule(name interface{}) *IKubernetesClient_GetResourcesForModule_Call {
-	return &IKubernetesClient_GetResourcesForModule_Call{Call: _e.mock.On("GetResourcesForModule", name)}
+func (_e *IKubernetesClient_Expecter) GetResourcesForModule(namespace interface{}, name interface{}) *IKubernetesClient_GetResourcesForModule_Call {
+	return &IKubernetesClient_GetResourcesForModule_Call{Call: _e.mock.On("GetResourcesForModule", namespace, name)}
 }
 
-func (_c *IKubernetesClient_GetResourcesForModule_Call) Run(run func(name string)) *IKubernetesClient_GetResourcesForModule_Call {
+func (_c *IKubernetesClient_GetResourcesForModule_Call) Run(run func(namespace string, name string)) *IKubernetesClient_GetResourcesForModule_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].(string))
 	})
 	return _c
 }
@@ -1114,7 +1571,7 @@ func (_c *IKubernetesClient_GetResourcesForModule_Call) Return(_a0 []*dto.Resour
 	return _c
 }
 
-func (_c *IKubernetesClient_GetResourcesForModule_Call) RunAndReturn(run func(string) ([]*dto.Resource, error)) *IKubernetesClient_GetResourcesForModule_Call {
+func (_c *IKubernetesClient_GetResourcesForModule_Call) RunAndReturn(run func(string, string) ([]*dto.Resource, error)) *IKubernetesClient_GetResourcesForModule_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1404,9 +1861,73 @@ func (_c *IKubernetesClient_GetTemplateStore_Call) RunAndReturn(run func(string)
 	return _c
 }
 
-// GetWorkloadsForModule provides a mock function with given fields: name
-func (_m *IKubernetesClient) GetWorkloadsForModule(name string) ([]*dto.Resource, error) {
-	ret := _m.Called(name)
+// GetValuesReference provides a mock function with given fields: namespace, reference
+func (_m *IKubernetesClient) GetValuesReference(namespace string, reference v1alpha1.ValuesReference) (string, bool, error) {
+	ret := _m.Called(namespace, reference)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetValuesReference")
+	}
+
+	var r0 string
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, v1alpha1.ValuesReference) (string, bool, error)); ok {
+		return rf(namespace, reference)
+	}
+	if rf, ok := ret.Get(0).(func(string, v1alpha1.ValuesReference) string); ok {
+		r0 = rf(namespace, reference)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, v1alpha1.ValuesReference) bool); ok {
+		r1 = rf(namespace, reference)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, v1alpha1.ValuesReference) error); ok {
+		r2 = rf(namespace, reference)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// IKubernetesClient_GetValuesReference_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetValuesReference'
+type IKubernetesClient_GetValuesReference_Call struct {
+	*mock.Call
+}
+
+// GetValuesReference is a helper method to define mock.On call
+//   - namespace string
+//   - reference v1alpha1.ValuesReference
+func (_e *IKubernetesClient_Expecter) GetValuesReference(namespace interface{}, reference interface{}) *IKubernetesClient_GetValuesReference_Call {
+	return &IKubernetesClient_GetValuesReference_Call{Call: _e.mock.On("GetValuesReference", namespace, reference)}
+}
+
+func (_c *IKubernetesClient_GetValuesReference_Call) Run(run func(namespace string, reference v1alpha1.ValuesReference)) *IKubernetesClient_GetValuesReference_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(v1alpha1.ValuesReference))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_GetValuesReference_Call) Return(_a0 string, _a1 bool, _a2 error) *IKubernetesClient_GetValuesReference_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *IKubernetesClient_GetValuesReference_Call) RunAndReturn(run func(string, v1alpha1.ValuesReference) (string, bool, error)) *IKubernetesClient_GetValuesReference_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWorkloadsForModule provides a mock function with given fields: namespace, name
+func (_m *IKubernetesClient) GetWorkloadsForModule(namespace string, name string) ([]*dto.Resource, error) {
+	ret := _m.Called(namespace, name)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetWorkloadsForModule")
@@ -1414,19 +1935,19 @@ func (_m *IKubernetesClient) GetWorkloadsForModule(name string) ([]*dto.Resource
 
 	var r0 []*dto.Resource
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*dto.Resource, error)); ok {
-		return rf(name)
+	if rf, ok := ret.Get(0).(func(string, string) ([]*dto.Resource, error)); ok {
+		return rf(namespace, name)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*dto.Resource); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(string, string) []*dto.Resource); ok {
+		r0 = rf(namespace, name)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*dto.Resource)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(name)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(namespace, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1440,14 +1961,15 @@ type IKubernetesClient_GetWorkloadsForModule_Call struct {
 }
 
 // GetWorkloadsForModule is a helper method to define mock.On call
+//   - namespace string
 //   - name string
-func (_e *IKubernetesClient_Expecter) GetWorkloadsForModule(name interface{}) *IKubernetesClient_GetWorkloadsForModule_Call {
-	return &IKubernetesClient_GetWorkloadsForModule_Call{Call: _e.mock.On("GetWorkloadsForModule", name)}
+func (_e *IKubernetesClient_Expecter) GetWorkloadsForModule(namespace interface{}, name interface{}) *IKubernetesClient_GetWorkloadsForModule_Call {
+	return &IKubernetesClient_GetWorkloadsForModule_Call{Call: _e.mock.On("GetWorkloadsForModule", namespace, name)}
 }
 
-func (_c *IKubernetesClient_GetWorkloadsForModule_Call) Run(run func(name string)) *IKubernetesClient_GetWorkloadsForModule_Call {
+func (_c *IKubernetesClient_GetWorkloadsForModule_Call) Run(run func(namespace string, name string)) *IKubernetesClient_GetWorkloadsForModule_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].(string))
 	})
 	return _c
 }
@@ -1457,7 +1979,7 @@ func (_c *IKubernetesClient_GetWorkloadsForModule_Call) Return(_a0 []*dto.Resour
 	return _c
 }
 
-func (_c *IKubernetesClient_GetWorkloadsForModule_Call) RunAndReturn(run func(string) ([]*dto.Resource, error)) *IKubernetesClient_GetWorkloadsForModule_Call {
+func (_c *IKubernetesClient_GetWorkloadsForModule_Call) RunAndReturn(run func(string, string) ([]*dto.Resource, error)) *IKubernetesClient_GetWorkloadsForModule_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1520,6 +2042,122 @@ func (_c *IKubernetesClient_GetWorkloadsForRelease_Call) RunAndReturn(run func(s
 	return _c
 }
 
+// ListClusters provides a mock function with no fields
+func (_m *IKubernetesClient) ListClusters() ([]v1alpha1.Cluster, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListClusters")
+	}
+
+	var r0 []v1alpha1.Cluster
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]v1alpha1.Cluster, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []v1alpha1.Cluster); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]v1alpha1.Cluster)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IKubernetesClient_ListClusters_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListClusters'
+type IKubernetesClient_ListClusters_Call struct {
+	*mock.Call
+}
+
+// ListClusters is a helper method to define mock.On call
+func (_e *IKubernetesClient_Expecter) ListClusters() *IKubernetesClient_ListClusters_Call {
+	return &IKubernetesClient_ListClusters_Call{Call: _e.mock.On("ListClusters")}
+}
+
+func (_c *IKubernetesClient_ListClusters_Call) Run(run func()) *IKubernetesClient_ListClusters_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_ListClusters_Call) Return(_a0 []v1alpha1.Cluster, _a1 error) *IKubernetesClient_ListClusters_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IKubernetesClient_ListClusters_Call) RunAndReturn(run func() ([]v1alpha1.Cluster, error)) *IKubernetesClient_ListClusters_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListModuleRevisions provides a mock function with given fields: namespace, name
+func (_m *IKubernetesClient) ListModuleRevisions(namespace string, name string) ([]v1alpha1.ModuleRevision, error) {
+	ret := _m.Called(namespace, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListModuleRevisions")
+	}
+
+	var r0 []v1alpha1.ModuleRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]v1alpha1.ModuleRevision, error)); ok {
+		return rf(namespace, name)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []v1alpha1.ModuleRevision); ok {
+		r0 = rf(namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]v1alpha1.ModuleRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IKubernetesClient_ListModuleRevisions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListModuleRevisions'
+type IKubernetesClient_ListModuleRevisions_Call struct {
+	*mock.Call
+}
+
+// ListModuleRevisions is a helper method to define mock.On call
+//   - namespace string
+//   - name string
+func (_e *IKubernetesClient_Expecter) ListModuleRevisions(namespace interface{}, name interface{}) *IKubernetesClient_ListModuleRevisions_Call {
+	return &IKubernetesClient_ListModuleRevisions_Call{Call: _e.mock.On("ListModuleRevisions", namespace, name)}
+}
+
+func (_c *IKubernetesClient_ListModuleRevisions_Call) Run(run func(namespace string, name string)) *IKubernetesClient_ListModuleRevisions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_ListModuleRevisions_Call) Return(_a0 []v1alpha1.ModuleRevision, _a1 error) *IKubernetesClient_ListModuleRevisions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IKubernetesClient_ListModuleRevisions_Call) RunAndReturn(run func(string, string) ([]v1alpha1.ModuleRevision, error)) *IKubernetesClient_ListModuleRevisions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListModules provides a mock function with no fields
 func (_m *IKubernetesClient) ListModules() ([]v1alpha1.Module, error) {
 	ret := _m.Called()
@@ -1863,6 +2501,116 @@ func (_c *IKubernetesClient_MapUnstructuredResource_Call) RunAndReturn(run func(
 	return _c
 }
 
+// ModuleNamespace provides a mock function with given fields: namespace
+func (_m *IKubernetesClient) ModuleNamespace(namespace string) string {
+	ret := _m.Called(namespace)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ModuleNamespace")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(namespace)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// IKubernetesClient_ModuleNamespace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ModuleNamespace'
+type IKubernetesClient_ModuleNamespace_Call struct {
+	*mock.Call
+}
+
+// ModuleNamespace is a helper method to define mock.On call
+//   - namespace string
+func (_e *IKubernetesClient_Expecter) ModuleNamespace(namespace interface{}) *IKubernetesClient_ModuleNamespace_Call {
+	return &IKubernetesClient_ModuleNamespace_Call{Call: _e.mock.On("ModuleNamespace", namespace)}
+}
+
+func (_c *IKubernetesClient_ModuleNamespace_Call) Run(run func(namespace string)) *IKubernetesClient_ModuleNamespace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_ModuleNamespace_Call) Return(_a0 string) *IKubernetesClient_ModuleNamespace_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IKubernetesClient_ModuleNamespace_Call) RunAndReturn(run func(string) string) *IKubernetesClient_ModuleNamespace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResourceClient provides a mock function with given fields: apiVersion, kind
+func (_m *IKubernetesClient) ResourceClient(apiVersion string, kind string) (dynamic.NamespaceableResourceInterface, bool, error) {
+	ret := _m.Called(apiVersion, kind)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResourceClient")
+	}
+
+	var r0 dynamic.NamespaceableResourceInterface
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, string) (dynamic.NamespaceableResourceInterface, bool, error)); ok {
+		return rf(apiVersion, kind)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) dynamic.NamespaceableResourceInterface); ok {
+		r0 = rf(apiVersion, kind)
+	} else {
+		r0 = ret.Get(0).(dynamic.NamespaceableResourceInterface)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) bool); ok {
+		r1 = rf(apiVersion, kind)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, string) error); ok {
+		r2 = rf(apiVersion, kind)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// IKubernetesClient_ResourceClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResourceClient'
+type IKubernetesClient_ResourceClient_Call struct {
+	*mock.Call
+}
+
+// ResourceClient is a helper method to define mock.On call
+//   - apiVersion string
+//   - kind string
+func (_e *IKubernetesClient_Expecter) ResourceClient(apiVersion interface{}, kind interface{}) *IKubernetesClient_ResourceClient_Call {
+	return &IKubernetesClient_ResourceClient_Call{Call: _e.mock.On("ResourceClient", apiVersion, kind)}
+}
+
+func (_c *IKubernetesClient_ResourceClient_Call) Run(run func(apiVersion string, kind string)) *IKubernetesClient_ResourceClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_ResourceClient_Call) Return(_a0 dynamic.NamespaceableResourceInterface, _a1 bool, _a2 error) *IKubernetesClient_ResourceClient_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *IKubernetesClient_ResourceClient_Call) RunAndReturn(run func(string, string) (dynamic.NamespaceableResourceInterface, bool, error)) *IKubernetesClient_ResourceClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Restart provides a mock function with given fields: group, _a1, kind, name, namespace
 func (_m *IKubernetesClient) Restart(group string, _a1 string, kind string, name string, namespace string) error {
 	ret := _m.Called(group, _a1, kind, name, namespace)
@@ -2320,6 +3068,41 @@ func (_c *IKubernetesClient_WatchKubernetesResources_Call) RunAndReturn(run func
 	return _c
 }
 
+// WatchModuleResources provides a mock function with given fields: gvr, onChange, stopCh
+func (_m *IKubernetesClient) WatchModuleResources(gvr schema.GroupVersionResource, onChange func(*unstructured.Unstructured), stopCh <-chan struct{}) {
+	_m.Called(gvr, onChange, stopCh)
+}
+
+// IKubernetesClient_WatchModuleResources_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WatchModuleResources'
+type IKubernetesClient_WatchModuleResources_Call struct {
+	*mock.Call
+}
+
+// WatchModuleResources is a helper method to define mock.On call
+//   - gvr schema.GroupVersionResource
+//   - onChange func(*unstructured.Unstructured)
+//   - stopCh <-chan struct{}
+func (_e *IKubernetesClient_Expecter) WatchModuleResources(gvr interface{}, onChange interface{}, stopCh interface{}) *IKubernetesClient_WatchModuleResources_Call {
+	return &IKubernetesClient_WatchModuleResources_Call{Call: _e.mock.On("WatchModuleResources", gvr, onChange, stopCh)}
+}
+
+func (_c *IKubernetesClient_WatchModuleResources_Call) Run(run func(gvr schema.GroupVersionResource, onChange func(*unstructured.Unstructured), stopCh <-chan struct{})) *IKubernetesClient_WatchModuleResources_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(schema.GroupVersionResource), args[1].(func(*unstructured.Unstructured)), args[2].(<-chan struct{}))
+	})
+	return _c
+}
+
+func (_c *IKubernetesClient_WatchModuleResources_Call) Return() *IKubernetesClient_WatchModuleResources_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IKubernetesClient_WatchModuleResources_Call) RunAndReturn(run func(schema.GroupVersionResource, func(*unstructured.Unstructured), <-chan struct{})) *IKubernetesClient_WatchModuleResources_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // WatchResource provides a mock function with given fields: group, _a1, resource, name, namespace
 func (_m *IKubernetesClient) WatchResource(group string, _a1 string, resource string, name string, namespace string) (watch.Interface, error) {
 	ret := _m.Called(group, _a1, resource, name, namespace)
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// diskStore keeps cache entries as files in a directory, named by the hash of their key. Once the size of all
+// entries exceeds maxSize, the least recently used entries are removed.
+type diskStore struct {
+	dir     string
+	maxSize int64
+
+	mu   sync.Mutex
+	size int64
+}
+
+// NewDiskTemplatesCache returns a cache that keeps templates on immutable versions in dir, so they survive
+// restarts when dir is on a persistent volume. Templates are also cached in memory.
+func NewDiskTemplatesCache(dir string, maxSize int64) (Templates, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Templates{}, errors.Wrapf(err, "failed to create template cache dir %v", dir)
+	}
+
+	disk := &diskStore{
+		dir:     dir,
+		maxSize: maxSize,
+	}
+
+	entries, err := disk.entries()
+	if err != nil {
+		return Templates{}, errors.Wrapf(err, "failed to read template cache dir %v", dir)
+	}
+
+	for _, e := range entries {
+		disk.size += e.size
+	}
+
+	templates := NewInMemoryTemplatesCache()
+	templates.persistent = disk
+
+	return templates, nil
+}
+
+func (d *diskStore) get(key string) ([]byte, bool) {
+	path := d.path(key)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	// modification time marks when the entry was last used, for evicting the least recently used entries
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return data, true
+}
+
+func (d *diskStore) set(key string, data []byte) {
+	if int64(len(data)) > d.maxSize {
+		return
+	}
+
+	path := d.path(key)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if info, err := os.Stat(path); err == nil {
+		d.size -= info.Size()
+	}
+
+	// write to a temporary file first, so other readers of the dir never see partially written entries
+	tmp, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+
+	d.size += int64(len(data))
+
+	if d.size > d.maxSize {
+		d.evict()
+	}
+}
+
+// evict removes the least recently used entries until the size of the remaining entries is below maxSize
+func (d *diskStore) evict() {
+	entries, err := d.entries()
+	if err != nil {
+		return
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed.Before(entries[j].lastUsed)
+	})
+
+	d.size = 0
+	for _, e := range entries {
+		d.size += e.size
+	}
+
+	for _, e := range entries {
+		if d.size <= d.maxSize {
+			return
+		}
+
+		if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+			continue
+		}
+
+		d.size -= e.size
+	}
+}
+
+type diskEntry struct {
+	path     string
+	size     int64
+	lastUsed time.Time
+}
+
+func (d *diskStore) entries() ([]diskEntry, error) {
+	entries := make([]diskEntry, 0)
+
+	err := filepath.WalkDir(d.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		entries = append(entries, diskEntry{
+			path:     path,
+			size:     info.Size(),
+			lastUsed: info.ModTime(),
+		})
+
+		return nil
+	})
+
+	return entries, err
+}
+
+func (d *diskStore) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(hash[:])+".json")
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
+)
+
+func TestTemplatesCache(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test templates cache")
+}
+
+var _ = Describe("Disk templates cache", func() {
+	const (
+		repo      = "https://github.com/my-org/templates"
+		path      = "charts/api"
+		commitSHA = "3e1c2a7b9f0d4e5c6b7a8f9e0d1c2b3a4f5e6d7c"
+	)
+
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("keeps templates on immutable versions between restarts", func() {
+		templates, err := NewDiskTemplatesCache(dir, 1<<20)
+		Expect(err).To(BeNil())
+
+		templates.SetTemplate(repo, path, commitSHA, "git", &models.Template{Name: "api", ResolvedVersion: commitSHA})
+		templates.SetTemplateInitialValues(repo, path, commitSHA, "git", map[string]interface{}{"replicas": "3"})
+
+		restarted, err := NewDiskTemplatesCache(dir, 1<<20)
+		Expect(err).To(BeNil())
+
+		template, ok := restarted.GetTemplate(repo, path, commitSHA, "git")
+		Expect(ok).To(BeTrue())
+		Expect(template).To(Equal(&models.Template{Name: "api", ResolvedVersion: commitSHA}))
+
+		values, ok := restarted.GetTemplateInitialValues(repo, path, commitSHA, "git")
+		Expect(ok).To(BeTrue())
+		Expect(values).To(Equal(map[string]interface{}{"replicas": "3"}))
+	})
+
+	It("keeps templates on mutable versions only in memory", func() {
+		templates, err := NewDiskTemplatesCache(dir, 1<<20)
+		Expect(err).To(BeNil())
+
+		templates.SetTemplate(repo, path, "main", "git", &models.Template{Name: "api"})
+
+		_, ok := templates.GetTemplate(repo, path, "main", "git")
+		Expect(ok).To(BeTrue())
+
+		restarted, err := NewDiskTemplatesCache(dir, 1<<20)
+		Expect(err).To(BeNil())
+
+		_, ok = restarted.GetTemplate(repo, path, "main", "git")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("keeps charts of OCI registries only in memory", func() {
+		templates, err := NewDiskTemplatesCache(dir, 1<<20)
+		Expect(err).To(BeNil())
+
+		templates.SetTemplate("oci://registry.my-org.com/charts", "api", "1.0.0", "oci", &models.Template{Name: "api"})
+
+		restarted, err := NewDiskTemplatesCache(dir, 1<<20)
+		Expect(err).To(BeNil())
+
+		_, ok := restarted.GetTemplate("oci://registry.my-org.com/charts", "api", "1.0.0", "oci")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("evicts entries over the max size", func() {
+		templates, err := NewDiskTemplatesCache(dir, 2000)
+		Expect(err).To(BeNil())
+
+		for _, version := range []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0"} {
+			templates.SetTemplate("https://charts.my-org.com", "api", version, "helm", &models.Template{Name: "api", Version: version})
+		}
+
+		files, err := os.ReadDir(dir)
+		Expect(err).To(BeNil())
+
+		size := int64(0)
+		for _, f := range files {
+			info, err := f.Info()
+			Expect(err).To(BeNil())
+			size += info.Size()
+		}
+
+		Expect(files).To(HaveLen(3))
+		Expect(size).To(BeNumerically("<=", 2000))
+
+		_, ok := templates.GetTemplate("https://charts.my-org.com", "api", "1.3.0", "helm")
+		Expect(ok).To(BeTrue())
+	})
+})
@@ -10,8 +10,15 @@ import (
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
 )
 
+// mutableTTL is how long templates on versions that can change are kept in memory
+const mutableTTL = time.Minute * 15
+
 type Templates struct {
 	cache *ristretto.Cache
+
+	// persistent keeps templates on immutable versions between restarts and shares them between replicas. Nil if
+	// templates are only cached in memory.
+	persistent store
 }
 
 func NewInMemoryTemplatesCache() Templates {
@@ -31,22 +38,17 @@ func NewInMemoryTemplatesCache() Templates {
 }
 
 func (t Templates) GetTemplate(repo, path, version, sourceType string) (*models.Template, bool) {
-	value, found := t.cache.Get(templateKey(repo, path, version, sourceType))
+	data, found := t.get(templateKey(repo, path, version, sourceType), isImmutable(version, sourceType))
 	if !found {
 		return nil, false
 	}
 
-	data, ok := value.([]byte)
-	if !ok {
-		return nil, false
-	}
-
 	var template *models.Template
 	if err := json.Unmarshal(data, &template); err != nil {
 		return nil, false
 	}
 
-	return template, ok
+	return template, true
 }
 
 func (t Templates) SetTemplate(repo, path, version, sourceType string, template *models.Template) {
@@ -55,22 +57,21 @@ func (t Templates) SetTemplate(repo, path, version, sourceType string, template
 		return
 	}
 
-	t.cache.SetWithTTL(templateKey(repo, path, version, sourceType), data, int64(len(data)), time.Minute*15)
-	t.cache.Wait()
+	t.set(templateKey(repo, path, version, sourceType), data, isImmutable(version, sourceType))
 }
 
 func (t Templates) GetTemplateInitialValues(repo, path, version, sourceType string) (map[string]interface{}, bool) {
-	data, found := t.cache.Get(initialValuesKey(repo, path, version, sourceType))
+	data, found := t.get(initialValuesKey(repo, path, version, sourceType), isImmutable(version, sourceType))
 	if !found {
 		return nil, false
 	}
 
-	values, ok := data.(map[string]interface{})
-	if !ok {
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
 		return nil, false
 	}
 
-	return values, ok
+	return values, true
 }
 
 func (t Templates) SetTemplateInitialValues(repo, path, version, sourceType string, values map[string]interface{}) {
@@ -79,14 +80,52 @@ func (t Templates) SetTemplateInitialValues(repo, path, version, sourceType stri
 		return
 	}
 
-	t.cache.SetWithTTL(initialValuesKey(repo, path, version, sourceType), values, int64(len(data)), time.Minute*15)
-	t.cache.Wait()
+	t.set(initialValuesKey(repo, path, version, sourceType), data, isImmutable(version, sourceType))
 }
 
 func (t Templates) ReturnCache() *ristretto.Cache {
 	return t.cache
 }
 
+// get reads the entry from memory, and falls back to the persistent store for immutable versions
+func (t Templates) get(key string, immutable bool) ([]byte, bool) {
+	value, found := t.cache.Get(key)
+	if found {
+		data, ok := value.([]byte)
+		return data, ok
+	}
+
+	if t.persistent == nil || !immutable {
+		return nil, false
+	}
+
+	data, found := t.persistent.get(key)
+	if !found {
+		return nil, false
+	}
+
+	t.cache.SetWithTTL(key, data, int64(len(data)), 0)
+	t.cache.Wait()
+
+	return data, true
+}
+
+// set caches entries on immutable versions without expiration, and writes them to the persistent store. Entries on
+// mutable versions are kept in memory only.
+func (t Templates) set(key string, data []byte, immutable bool) {
+	ttl := mutableTTL
+	if immutable {
+		ttl = 0
+	}
+
+	t.cache.SetWithTTL(key, data, int64(len(data)), ttl)
+	t.cache.Wait()
+
+	if t.persistent != nil && immutable {
+		t.persistent.set(key, data)
+	}
+}
+
 func templateKey(repo, path, version, sourceType string) string {
 	return fmt.Sprintf("template:%v:%v/%v@%v", sourceType, repo, path, version)
 }
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"regexp"
+
+	"github.com/Masterminds/semver/v3"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+// store persists cache entries outside of the controller process. Failing reads are treated as cache misses and
+// failing writes are ignored, since templates can always be fetched again.
+type store interface {
+	get(key string) ([]byte, bool)
+	set(key string, data []byte)
+}
+
+var commitSHARegex = regexp.MustCompile("^[0-9a-f]{40}([0-9a-f]{24})?$")
+
+// isImmutable returns true if the template on the version can't change; git commits, and chart versions of Helm
+// repos, which are treated as immutable same as Helm does. Charts in OCI registries are versioned with tags that
+// can be pushed again, so they are never persisted.
+func isImmutable(version, sourceType string) bool {
+	switch cyclopsv1alpha1.TemplateSourceType(sourceType) {
+	case cyclopsv1alpha1.TemplateSourceTypeGit:
+		return commitSHARegex.MatchString(version)
+	case cyclopsv1alpha1.TemplateSourceTypeHelm:
+		_, err := semver.StrictNewVersion(version)
+		return err == nil
+	}
+
+	return false
+}
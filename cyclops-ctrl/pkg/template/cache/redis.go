@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+)
+
+// redisKeyPrefix namespaces Cyclops entries in a Redis instance that might be shared with other applications
+const redisKeyPrefix = "cyclops:"
+
+// redisStore keeps cache entries in Redis, or any server speaking the Redis protocol. Entries are set without
+// expiration; the size of the cache is bounded by the maxmemory and eviction policy of the server.
+type redisStore struct {
+	pool *redis.Pool
+}
+
+// NewRedisTemplatesCache returns a cache that keeps templates on immutable versions in Redis, shared by all
+// replicas of the controller. redisURL is in the redis://[:password@]host[:port][/db] format, or rediss:// for TLS.
+// Templates are also cached in memory.
+func NewRedisTemplatesCache(redisURL string) (Templates, error) {
+	pool := &redis.Pool{
+		MaxIdle:     10,
+		IdleTimeout: 5 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(
+				redisURL,
+				redis.DialConnectTimeout(5*time.Second),
+				redis.DialReadTimeout(5*time.Second),
+				redis.DialWriteTimeout(5*time.Second),
+			)
+		},
+	}
+
+	conn := pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("PING"); err != nil {
+		return Templates{}, errors.Wrap(err, "failed to connect to template cache redis")
+	}
+
+	templates := NewInMemoryTemplatesCache()
+	templates.persistent = &redisStore{
+		pool: pool,
+	}
+
+	return templates, nil
+}
+
+func (r *redisStore) get(key string) ([]byte, bool) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	data, err := redis.Bytes(conn.Do("GET", redisKeyPrefix+key))
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+func (r *redisStore) set(key string, data []byte) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	_, _ = conn.Do("SET", redisKeyPrefix+key, data)
+}
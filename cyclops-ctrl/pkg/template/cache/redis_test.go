@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
+)
+
+// fakeRedis serves the PING, GET and SET commands of the Redis protocol from a map
+type fakeRedis struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func newFakeRedis() *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).To(BeNil())
+
+	r := &fakeRedis{
+		listener: listener,
+		entries:  map[string][]byte{},
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go r.serve(conn)
+		}
+	}()
+
+	return r
+}
+
+func (r *fakeRedis) url() string {
+	return "redis://" + r.listener.Addr().String()
+}
+
+func (r *fakeRedis) keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]string, 0, len(r.entries))
+	for key := range r.entries {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		var reply string
+		r.mu.Lock()
+		switch strings.ToUpper(args[0]) {
+		case "PING":
+			reply = "+PONG\r\n"
+		case "GET":
+			if data, ok := r.entries[args[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(data), data)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case "SET":
+			r.entries[args[1]] = []byte(args[2])
+			reply = "+OK\r\n"
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		r.mu.Unlock()
+
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	readLine := func(prefix byte) (int, error) {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+
+		if len(line) < 3 || line[0] != prefix {
+			return 0, fmt.Errorf("unexpected line %q", line)
+		}
+
+		return strconv.Atoi(strings.TrimSuffix(line[1:], "\r\n"))
+	}
+
+	count, err := readLine('*')
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		length, err := readLine('$')
+		if err != nil {
+			return nil, err
+		}
+
+		arg := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+
+		args = append(args, string(arg[:length]))
+	}
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	return args, nil
+}
+
+var _ = Describe("Redis templates cache", func() {
+	const (
+		repo      = "https://github.com/my-org/templates"
+		path      = "charts/api"
+		commitSHA = "3e1c2a7b9f0d4e5c6b7a8f9e0d1c2b3a4f5e6d7c"
+	)
+
+	var server *fakeRedis
+
+	BeforeEach(func() {
+		server = newFakeRedis()
+		DeferCleanup(func() {
+			_ = server.listener.Close()
+		})
+	})
+
+	It("shares templates on immutable versions between replicas", func() {
+		templates, err := NewRedisTemplatesCache(server.url())
+		Expect(err).To(BeNil())
+
+		templates.SetTemplate(repo, path, commitSHA, "git", &models.Template{Name: "api", ResolvedVersion: commitSHA})
+		templates.SetTemplateInitialValues(repo, path, commitSHA, "git", map[string]interface{}{"replicas": "3"})
+
+		replica, err := NewRedisTemplatesCache(server.url())
+		Expect(err).To(BeNil())
+
+		template, ok := replica.GetTemplate(repo, path, commitSHA, "git")
+		Expect(ok).To(BeTrue())
+		Expect(template).To(Equal(&models.Template{Name: "api", ResolvedVersion: commitSHA}))
+
+		values, ok := replica.GetTemplateInitialValues(repo, path, commitSHA, "git")
+		Expect(ok).To(BeTrue())
+		Expect(values).To(Equal(map[string]interface{}{"replicas": "3"}))
+
+		Expect(server.keys()).To(HaveLen(2))
+		for _, key := range server.keys() {
+			Expect(key).To(HavePrefix(redisKeyPrefix))
+		}
+	})
+
+	It("keeps templates on mutable versions only in memory", func() {
+		templates, err := NewRedisTemplatesCache(server.url())
+		Expect(err).To(BeNil())
+
+		templates.SetTemplate(repo, path, "main", "git", &models.Template{Name: "api"})
+		templates.SetTemplate("oci://registry.my-org.com/charts", "api", "1.0.0", "oci", &models.Template{Name: "api"})
+
+		_, ok := templates.GetTemplate(repo, path, "main", "git")
+		Expect(ok).To(BeTrue())
+		Expect(server.keys()).To(BeEmpty())
+	})
+
+	It("fails if the server is unreachable", func() {
+		Expect(server.listener.Close()).To(Succeed())
+
+		_, err := NewRedisTemplatesCache(server.url())
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	path2 "path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/auth"
@@ -23,7 +25,6 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
 	"github.com/go-git/go-git/v5/plumbing/transport"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"helm.sh/helm/v3/pkg/chart"
 
@@ -54,18 +55,18 @@ func (r Repo) LoadTemplate(repoURL, path, commit, resolvedVersion string) (*mode
 		return cached, nil
 	}
 
-	if gitproviders2.IsGitHubSource(repoURL) {
-		ghTemplate, err := r.mapGitHubRepoTemplate(repoURL, path, commitSHA, creds)
+	if repoFiles, parsedURL, ok := providerRepoFiles(repoURL, commitSHA, creds); ok {
+		providerTemplate, err := r.mapProviderRepoTemplate(parsedURL, path, commitSHA, repoFiles)
 		if err != nil {
 			return nil, err
 		}
 
-		ghTemplate.Version = commit
-		ghTemplate.ResolvedVersion = commitSHA
+		providerTemplate.Version = commit
+		providerTemplate.ResolvedVersion = commitSHA
 
-		r.cache.SetTemplate(repoURL, path, commitSHA, string(cyclopsv1alpha1.TemplateSourceTypeGit), ghTemplate)
+		r.cache.SetTemplate(repoURL, path, commitSHA, string(cyclopsv1alpha1.TemplateSourceTypeGit), providerTemplate)
 
-		return ghTemplate, nil
+		return providerTemplate, nil
 	}
 
 	fs, err := clone(repoURL, commit, creds)
@@ -208,15 +209,15 @@ func (r Repo) LoadInitialTemplateValues(repoURL, path, commit string) (map[strin
 		return cached, nil
 	}
 
-	if gitproviders2.IsGitHubSource(repoURL) {
-		ghInitialValues, err := r.mapGitHubRepoInitialValues(repoURL, path, commitSHA, creds)
+	if repoFiles, parsedURL, ok := providerRepoFiles(repoURL, commitSHA, creds); ok {
+		providerInitialValues, err := r.mapProviderRepoInitialValues(parsedURL, path, commitSHA, repoFiles)
 		if err != nil {
 			return nil, err
 		}
 
-		r.cache.SetTemplateInitialValues(repoURL, path, commitSHA, string(cyclopsv1alpha1.TemplateSourceTypeGit), ghInitialValues)
+		r.cache.SetTemplateInitialValues(repoURL, path, commitSHA, string(cyclopsv1alpha1.TemplateSourceTypeGit), providerInitialValues)
 
-		return ghInitialValues, nil
+		return providerInitialValues, nil
 	}
 
 	fs, err := clone(repoURL, commit, creds)
@@ -274,29 +275,53 @@ func (r Repo) LoadInitialTemplateValues(repoURL, path, commit string) (map[strin
 	return initialValues, nil
 }
 
-func (r Repo) listRemoteRefs(repo string, creds *auth.Credentials) ([]string, error) {
+func (r Repo) listRemoteRefs(repo string, creds *auth.Credentials, includePrerelease bool) ([]models.TemplateRevision, error) {
+	gitURL, gitAuth, err := auth.GitAuth(repo, creds)
+	if err != nil {
+		return nil, err
+	}
+
 	rem := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
 		Name: "origin",
-		URLs: []string{repo},
+		URLs: []string{gitURL},
 	})
 
 	refs, err := rem.List(&git.ListOptions{
 		PeelingOption: git.AppendPeeled,
-		Auth:          httpBasicAuthCredentials(creds),
+		Auth:          gitAuth,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("repo %s was not cloned successfully; authentication might be required; check if repository exists and you referenced it correctly", repo))
 	}
 
-	branches := make([]string, 0)
+	branches := make([]models.TemplateRevision, 0)
+	tags := make([]models.TemplateRevision, 0)
 
 	for _, ref := range refs {
 		if ref.Name().IsBranch() {
-			branches = append(branches, ref.Name().Short())
+			branches = append(branches, models.TemplateRevision{
+				Name: ref.Name().Short(),
+				Type: models.TemplateRevisionTypeBranch,
+			})
+		}
+
+		// annotated tags are listed twice; as the tag and as the peeled commit with the ^{} suffix
+		if ref.Name().IsTag() && !strings.HasSuffix(ref.Name().String(), "^{}") {
+			tags = append(tags, models.TemplateRevision{
+				Name: ref.Name().Short(),
+				Type: models.TemplateRevisionTypeTag,
+			})
 		}
 	}
 
-	return branches, nil
+	sort.Slice(branches, func(i, j int) bool {
+		return branches[i].Name < branches[j].Name
+	})
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+
+	return append(branches, sortRevisions(tags, includePrerelease)...), nil
 }
 
 func resolveRef(repo, version string, creds *auth.Credentials) (string, error) {
@@ -304,15 +329,20 @@ func resolveRef(repo, version string, creds *auth.Credentials) (string, error) {
 		return resolveDefaultBranchRef(repo, creds)
 	}
 
+	gitURL, gitAuth, err := auth.GitAuth(repo, creds)
+	if err != nil {
+		return "", err
+	}
+
 	rem := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
 		Name: "origin",
-		URLs: []string{repo},
+		URLs: []string{gitURL},
 	})
 
 	// We can then use every Remote functions to retrieve wanted information
 	refs, err := rem.List(&git.ListOptions{
 		PeelingOption: git.AppendPeeled,
-		Auth:          httpBasicAuthCredentials(creds),
+		Auth:          gitAuth,
 	})
 	if err != nil {
 		return "", errors.Wrap(err, fmt.Sprintf("repo %s was not cloned successfully; authentication might be required; check if repository exists and you referenced it correctly", repo))
@@ -329,15 +359,20 @@ func resolveRef(repo, version string, creds *auth.Credentials) (string, error) {
 }
 
 func resolveDefaultBranchRef(repo string, creds *auth.Credentials) (string, error) {
+	gitURL, gitAuth, err := auth.GitAuth(repo, creds)
+	if err != nil {
+		return "", err
+	}
+
 	rem := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
 		Name: "origin",
-		URLs: []string{repo},
+		URLs: []string{gitURL},
 	})
 
 	// We can then use every Remote functions to retrieve wanted information
 	refs, err := rem.List(&git.ListOptions{
 		PeelingOption: git.AppendPeeled,
-		Auth:          httpBasicAuthCredentials(creds),
+		Auth:          gitAuth,
 	})
 	if err != nil {
 		return "", errors.Wrap(err, fmt.Sprintf("repo %s was not cloned successfully; authentication might be required; check if repository exists and you referenced it correctly", repo))
@@ -384,10 +419,15 @@ func clone(repoURL, commit string, creds *auth.Credentials) (billy.Filesystem, e
 		}
 	}
 
+	gitURL, gitAuth, err := auth.GitAuth(repoURL, creds)
+	if err != nil {
+		return nil, err
+	}
+
 	repo, err := git.Clone(memory.NewStorage(), memfs.New(), &git.CloneOptions{
-		URL:  repoURL,
+		URL:  gitURL,
 		Tags: git.AllTags,
-		Auth: httpBasicAuthCredentials(creds),
+		Auth: gitAuth,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("repo %s was not cloned successfully; authentication might be required; check if repository exists and you referenced it correctly", repoURL))
@@ -407,7 +447,7 @@ func clone(repoURL, commit string, creds *auth.Credentials) (billy.Filesystem, e
 			return nil, err
 		}
 		refList, err := remote.List(&git.ListOptions{
-			Auth: httpBasicAuthCredentials(creds),
+			Auth: gitAuth,
 		})
 		if err != nil {
 			return nil, err
@@ -536,23 +576,35 @@ func readFiles(path string, fs billy.Filesystem) ([]*chart.File, error) {
 	return chartFiles, nil
 }
 
-func (r Repo) mapGitHubRepoTemplate(repoURL, path, commitSHA string, creds *auth.Credentials) (*models.Template, error) {
-	tgzData, err := gitproviders2.GitHubClone(repoURL, commitSHA, creds)
-	if err != nil {
-		return nil, err
+// providerRepoFiles downloads the repo archive from the git provider hosting the repo. It returns false if the
+// repo is not hosted on a known provider or the archive can't be downloaded, for example because the provider
+// API is not reachable or the credentials only grant git access, in which case the repo needs to be cloned.
+func providerRepoFiles(repoURL, commitSHA string, creds *auth.Credentials) (map[string][]byte, *url.URL, bool) {
+	provider, parsedURL, ok := gitproviders2.ForRepo(repoURL)
+	if !ok || sshOnly(creds) {
+		return nil, nil, false
 	}
 
-	ghRepoFiles, err := unpackTgzInMemory(tgzData)
+	repoFiles, err := provider.Files(parsedURL, commitSHA, creds)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to clone repo from GitHub: make sure that the version (branch or commit) exist on the specified repo")
+		fmt.Println("failed to download repo archive, cloning the repo instead", repoURL, err)
+		return nil, nil, false
 	}
 
-	ghRepoFiles, exists := gitproviders2.SanitizeGHFiles(ghRepoFiles, path)
+	return repoFiles, parsedURL, true
+}
+
+func (r Repo) mapProviderRepoTemplate(
+	repoURL *url.URL,
+	path, commitSHA string,
+	repoFiles map[string][]byte,
+) (*models.Template, error) {
+	repoFiles, exists := gitproviders2.FilesOnPath(repoFiles, path)
 	if !exists {
 		return nil, errors.Errorf("provided path %v for repo %v does not exist on version %v", path, repoURL, commitSHA)
 	}
 
-	template, err := r.mapHelmChart(path, ghRepoFiles)
+	template, err := r.mapHelmChart(path, repoFiles)
 	if err != nil {
 		return nil, err
 	}
@@ -560,23 +612,17 @@ func (r Repo) mapGitHubRepoTemplate(repoURL, path, commitSHA string, creds *auth
 	return template, nil
 }
 
-func (r Repo) mapGitHubRepoInitialValues(repoURL, path, commitSHA string, creds *auth.Credentials) (map[string]interface{}, error) {
-	tgzData, err := gitproviders2.GitHubClone(repoURL, commitSHA, creds)
-	if err != nil {
-		return nil, err
-	}
-
-	ghRepoFiles, err := unpackTgzInMemory(tgzData)
-	if err != nil {
-		return nil, err
-	}
-
-	ghRepoFiles, exists := gitproviders2.SanitizeGHFiles(ghRepoFiles, path)
+func (r Repo) mapProviderRepoInitialValues(
+	repoURL *url.URL,
+	path, commitSHA string,
+	repoFiles map[string][]byte,
+) (map[string]interface{}, error) {
+	repoFiles, exists := gitproviders2.FilesOnPath(repoFiles, path)
 	if !exists {
 		return nil, errors.Errorf("provided path %v for repo %v does not exist on version %v", path, repoURL, commitSHA)
 	}
 
-	initial, err := r.mapHelmChartInitialValues(ghRepoFiles)
+	initial, err := r.mapHelmChartInitialValues(repoFiles)
 	if err != nil {
 		return nil, err
 	}
@@ -584,13 +630,7 @@ func (r Repo) mapGitHubRepoInitialValues(repoURL, path, commitSHA string, creds
 	return initial, nil
 }
 
-func httpBasicAuthCredentials(creds *auth.Credentials) *http.BasicAuth {
-	if creds == nil {
-		return nil
-	}
-
-	return &http.BasicAuth{
-		Username: creds.Username,
-		Password: creds.Password,
-	}
+// sshOnly returns true if the repo can only be accessed over SSH, instead of the archive API of its git provider
+func sshOnly(creds *auth.Credentials) bool {
+	return creds != nil && creds.SSHOnly()
 }
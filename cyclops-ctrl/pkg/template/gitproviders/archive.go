@@ -0,0 +1,96 @@
+package gitproviders
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+)
+
+// untarRepo unpacks a gzipped tar archive of a repo. Archives of all providers except Azure DevOps hold the repo
+// in a single top level directory, which is removed from file names.
+func untarRepo(tgzData []byte) (map[string][]byte, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(tgzData))
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+
+	files := make(map[string][]byte)
+
+	for {
+		header, err := tarReader.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := removeFirstSegment(header.Name)
+		if len(name) == 0 {
+			continue
+		}
+
+		var fileBuffer bytes.Buffer
+		if _, err := io.Copy(&fileBuffer, tarReader); err != nil {
+			return nil, err
+		}
+
+		files[name] = fileBuffer.Bytes()
+	}
+
+	return files, nil
+}
+
+// unzipRepo unpacks a zip archive holding repo files in its root
+func unzipRepo(zipData []byte) (map[string][]byte, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+
+	for _, f := range zipReader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		var fileBuffer bytes.Buffer
+		_, err = io.Copy(&fileBuffer, rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		files[strings.TrimPrefix(f.Name, "/")] = fileBuffer.Bytes()
+	}
+
+	return files, nil
+}
+
+func removeFirstSegment(path string) string {
+	segments := strings.Split(path, `/`)
+
+	if len(segments) <= 1 {
+		return ""
+	}
+
+	return strings.Join(segments[1:], `/`)
+}
@@ -1,6 +1,13 @@
 package gitproviders
 
-import "net/url"
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/auth"
+)
 
 func IsAzureRepo(repoURL string) bool {
 	host, err := url.Parse(repoURL)
@@ -8,5 +15,52 @@ func IsAzureRepo(repoURL string) bool {
 		return false
 	}
 
-	return host.Host == "dev.azure.com"
+	return azureDevOps{}.Matches(host)
+}
+
+// azureDevOps downloads zip archives of repos from the Azure DevOps items API. Personal access tokens are passed
+// with basic auth, where the username can be left empty.
+type azureDevOps struct{}
+
+func (azureDevOps) Matches(repoURL *url.URL) bool {
+	host := repoURL.Hostname()
+	return host == "dev.azure.com" || strings.HasSuffix(host, ".visualstudio.com")
+}
+
+func (azureDevOps) Files(repoURL *url.URL, commitSHA string, creds *auth.Credentials) (map[string][]byte, error) {
+	// https://dev.azure.com/<org>/<project>/_git/<repo> or https://<org>.visualstudio.com/<project>/_git/<repo>
+	project, repo, found := strings.Cut(strings.TrimSuffix(repoURL.Path, "/"), "/_git/")
+	if !found || len(repo) == 0 || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("invalid azure devops repo URL; should be https://dev.azure.com/<org>/<project>/_git/<repo>")
+	}
+
+	archiveURL := url.URL{
+		Scheme: repoURL.Scheme,
+		Host:   repoURL.Host,
+		Path:   fmt.Sprintf("%v/_apis/git/repositories/%v/items", project, repo),
+		RawQuery: url.Values{
+			"path":                          []string{"/"},
+			"versionDescriptor.version":     []string{commitSHA},
+			"versionDescriptor.versionType": []string{"commit"},
+			"$format":                       []string{"zip"},
+			"download":                      []string{"true"},
+			"api-version":                   []string{"7.1"},
+		}.Encode(),
+	}
+
+	req, err := http.NewRequest(http.MethodGet, archiveURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if creds != nil && len(creds.Password) != 0 {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+
+	zipData, err := download(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return unzipRepo(zipData)
 }
@@ -0,0 +1,109 @@
+package gitproviders
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/auth"
+)
+
+// bitbucketCloudURL is the URL repo archives of Bitbucket Cloud are downloaded from
+var bitbucketCloudURL = "https://bitbucket.org"
+
+// bitbucketServerRepoPath matches paths of Bitbucket Server clone URLs, /scm/<project>/<repo>, optionally on a
+// context path
+var bitbucketServerRepoPath = regexp.MustCompile(`^(/[^/]+)*/scm/[^/]+/[^/]+?(\.git)?/?$`)
+
+// bitbucketCloud downloads repo archives from bitbucket.org. Usernames with app passwords are passed with basic
+// auth, and access tokens without a username as bearer tokens.
+type bitbucketCloud struct{}
+
+func (bitbucketCloud) Matches(repoURL *url.URL) bool {
+	return repoURL.Hostname() == "bitbucket.org"
+}
+
+func (bitbucketCloud) Files(repoURL *url.URL, commitSHA string, creds *auth.Credentials) (map[string][]byte, error) {
+	pathParts := strings.Split(repoPath(repoURL), "/")
+	if len(pathParts) != 2 {
+		return nil, fmt.Errorf("invalid bitbucket repo URL; should be https://bitbucket.org/<workspace>/<repo>")
+	}
+
+	req, err := http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%v/%v/%v/get/%v.tar.gz", bitbucketCloudURL, pathParts[0], pathParts[1], commitSHA),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	setBitbucketAuth(req, creds)
+
+	tgzData, err := download(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return untarRepo(tgzData)
+}
+
+// bitbucketServer downloads repo archives from the REST API of Bitbucket Server and Data Center. Repos are
+// recognized by their clone URL, like https://bitbucket.example.com/scm/<project>/<repo>.git. HTTP access tokens
+// are passed as bearer tokens, or with basic auth if a username is set.
+type bitbucketServer struct{}
+
+func (bitbucketServer) Matches(repoURL *url.URL) bool {
+	return bitbucketServerRepoPath.MatchString(repoURL.Path)
+}
+
+func (bitbucketServer) Files(repoURL *url.URL, commitSHA string, creds *auth.Credentials) (map[string][]byte, error) {
+	// Bitbucket can be served on a context path, like https://example.com/bitbucket/scm/<project>/<repo>.git
+	contextPath, repoRef, _ := strings.Cut(repoURL.Path, "/scm/")
+
+	pathParts := strings.Split(strings.TrimSuffix(strings.Trim(repoRef, "/"), ".git"), "/")
+	if len(pathParts) != 2 {
+		return nil, fmt.Errorf("invalid bitbucket repo URL; should be https://%v%v/scm/<project>/<repo>", repoURL.Host, contextPath)
+	}
+
+	archiveURL := url.URL{
+		Scheme: repoURL.Scheme,
+		Host:   repoURL.Host,
+		Path:   fmt.Sprintf("%v/rest/api/latest/projects/%v/repos/%v/archive", contextPath, pathParts[0], pathParts[1]),
+		RawQuery: url.Values{
+			"at":     []string{commitSHA},
+			"format": []string{"tar.gz"},
+			// archives are not prefixed with a top level directory by default
+			"prefix": []string{pathParts[1] + "/"},
+		}.Encode(),
+	}
+
+	req, err := http.NewRequest(http.MethodGet, archiveURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	setBitbucketAuth(req, creds)
+
+	tgzData, err := download(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return untarRepo(tgzData)
+}
+
+func setBitbucketAuth(req *http.Request, creds *auth.Credentials) {
+	if creds == nil || len(creds.Password) == 0 {
+		return
+	}
+
+	if len(creds.Username) != 0 {
+		req.SetBasicAuth(creds.Username, creds.Password)
+		return
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", creds.Password))
+}
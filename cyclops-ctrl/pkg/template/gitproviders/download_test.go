@@ -0,0 +1,173 @@
+package gitproviders
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/auth"
+)
+
+var _ = Describe("Downloading repo archives", func() {
+	chart := []byte("name: app")
+
+	tarArchive := func() []byte {
+		var buf bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buf)
+		tarWriter := tar.NewWriter(gzipWriter)
+
+		Expect(tarWriter.WriteHeader(&tar.Header{Name: "templates-abc123/", Typeflag: tar.TypeDir, Mode: 0755})).To(Succeed())
+		Expect(tarWriter.WriteHeader(&tar.Header{Name: "templates-abc123/charts/app/Chart.yaml", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(chart))})).To(Succeed())
+		_, err := tarWriter.Write(chart)
+		Expect(err).To(BeNil())
+
+		Expect(tarWriter.Close()).To(Succeed())
+		Expect(gzipWriter.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+
+	zipArchive := func() []byte {
+		var buf bytes.Buffer
+		zipWriter := zip.NewWriter(&buf)
+
+		w, err := zipWriter.Create("charts/app/Chart.yaml")
+		Expect(err).To(BeNil())
+		_, err = w.Write(chart)
+		Expect(err).To(BeNil())
+
+		Expect(zipWriter.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+
+	var server *httptest.Server
+	var requests []*http.Request
+	var archive []byte
+
+	BeforeEach(func() {
+		requests = nil
+		archive = tarArchive()
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+			_, _ = w.Write(archive)
+		}))
+
+		previousGitHubAPIURL, previousBitbucketCloudURL := gitHubAPIURL, bitbucketCloudURL
+		gitHubAPIURL, bitbucketCloudURL = server.URL, server.URL
+
+		DeferCleanup(func() {
+			gitHubAPIURL, bitbucketCloudURL = previousGitHubAPIURL, previousBitbucketCloudURL
+			server.Close()
+		})
+	})
+
+	repoURL := func(repo string) *url.URL {
+		parsed, err := url.Parse(repo)
+		Expect(err).To(BeNil())
+		return parsed
+	}
+
+	basicAuth := func(r *http.Request) (string, string) {
+		username, password, ok := r.BasicAuth()
+		Expect(ok).To(BeTrue())
+		return username, password
+	}
+
+	It("downloads github tarballs with a bearer token", func() {
+		files, err := gitHub{}.Files(repoURL("https://github.com/cyclops-ui/templates"), "abc123", &auth.Credentials{Password: "token"})
+
+		Expect(err).To(BeNil())
+		Expect(files).To(Equal(map[string][]byte{"charts/app/Chart.yaml": chart}))
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].URL.Path).To(Equal("/repos/cyclops-ui/templates/tarball/abc123"))
+		Expect(requests[0].Header.Get("Authorization")).To(Equal("Bearer token"))
+	})
+
+	It("downloads gitlab archives of nested projects with a private token", func() {
+		files, err := gitLab{}.Files(repoURL(server.URL+"/my-group/my-subgroup/templates.git"), "abc123", &auth.Credentials{Password: "token"})
+
+		Expect(err).To(BeNil())
+		Expect(files).To(Equal(map[string][]byte{"charts/app/Chart.yaml": chart}))
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].URL.EscapedPath()).To(Equal("/api/v4/projects/my-group%2Fmy-subgroup%2Ftemplates/repository/archive.tar.gz"))
+		Expect(requests[0].URL.Query().Get("sha")).To(Equal("abc123"))
+		Expect(requests[0].Header.Get("PRIVATE-TOKEN")).To(Equal("token"))
+	})
+
+	It("downloads bitbucket cloud archives with an app password", func() {
+		files, err := bitbucketCloud{}.Files(repoURL("https://bitbucket.org/my-workspace/templates.git"), "abc123", &auth.Credentials{Username: "user", Password: "app-password"})
+
+		Expect(err).To(BeNil())
+		Expect(files).To(Equal(map[string][]byte{"charts/app/Chart.yaml": chart}))
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].URL.Path).To(Equal("/my-workspace/templates/get/abc123.tar.gz"))
+
+		username, password := basicAuth(requests[0])
+		Expect(username).To(Equal("user"))
+		Expect(password).To(Equal("app-password"))
+	})
+
+	It("downloads bitbucket cloud archives with an access token", func() {
+		_, err := bitbucketCloud{}.Files(repoURL("https://bitbucket.org/my-workspace/templates"), "abc123", &auth.Credentials{Password: "token"})
+
+		Expect(err).To(BeNil())
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Header.Get("Authorization")).To(Equal("Bearer token"))
+	})
+
+	It("downloads bitbucket server archives on a context path", func() {
+		files, err := bitbucketServer{}.Files(repoURL(server.URL+"/bitbucket/scm/proj/templates.git"), "abc123", &auth.Credentials{Password: "token"})
+
+		Expect(err).To(BeNil())
+		Expect(files).To(Equal(map[string][]byte{"charts/app/Chart.yaml": chart}))
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].URL.Path).To(Equal("/bitbucket/rest/api/latest/projects/proj/repos/templates/archive"))
+		Expect(requests[0].URL.Query().Get("at")).To(Equal("abc123"))
+		Expect(requests[0].URL.Query().Get("format")).To(Equal("tar.gz"))
+		Expect(requests[0].URL.Query().Get("prefix")).To(Equal("templates/"))
+		Expect(requests[0].Header.Get("Authorization")).To(Equal("Bearer token"))
+	})
+
+	It("downloads azure devops zip archives with a personal access token", func() {
+		archive = zipArchive()
+
+		files, err := azureDevOps{}.Files(repoURL(server.URL+"/my-org/my-project/_git/templates"), "abc123", &auth.Credentials{Password: "pat"})
+
+		Expect(err).To(BeNil())
+		Expect(files).To(Equal(map[string][]byte{"charts/app/Chart.yaml": chart}))
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].URL.Path).To(Equal("/my-org/my-project/_apis/git/repositories/templates/items"))
+		Expect(requests[0].URL.Query().Get("versionDescriptor.version")).To(Equal("abc123"))
+		Expect(requests[0].URL.Query().Get("$format")).To(Equal("zip"))
+
+		username, password := basicAuth(requests[0])
+		Expect(username).To(BeEmpty())
+		Expect(password).To(Equal("pat"))
+	})
+
+	It("doesn't send credentials if there are none", func() {
+		_, err := gitHub{}.Files(repoURL("https://github.com/cyclops-ui/templates"), "abc123", nil)
+
+		Expect(err).To(BeNil())
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Header.Get("Authorization")).To(BeEmpty())
+	})
+
+	It("fails on unsuccessful responses", func() {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+
+		_, err := gitLab{}.Files(repoURL(server.URL+"/my-group/templates"), "abc123", nil)
+
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("401"))
+	})
+})
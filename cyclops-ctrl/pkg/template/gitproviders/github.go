@@ -2,10 +2,8 @@ package gitproviders
 
 import (
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
-	path2 "path"
 	"strings"
 
 	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/auth"
@@ -22,28 +20,25 @@ func IsGitHubSource(repoURL string) bool {
 	return host.Host == "github.com"
 }
 
-func GitHubClone(repoURL, commitSHA string, creds *auth.Credentials) ([]byte, error) {
-	repoURLparsed, err := url.Parse(repoURL)
-	if err != nil {
-		return nil, err
-	}
+// gitHubAPIURL is the URL of the GitHub API repo tarballs are downloaded from
+var gitHubAPIURL = "https://api.github.com"
 
-	pathParts := strings.Split(repoURLparsed.Path, "/")
-	var organization, repository string
-	if len(pathParts) == 3 {
-		organization = pathParts[1]
-		repository = pathParts[2]
-	} else {
-		return nil, errors.New("invalid github repo URL; should be https://github.com/<org>/<repo>")
-	}
+// gitHub downloads repo tarballs from the GitHub API. Personal access tokens are passed as bearer tokens.
+type gitHub struct{}
 
-	return gitHubTarball(organization, repository, commitSHA, creds)
+func (gitHub) Matches(repoURL *url.URL) bool {
+	return repoURL.Host == "github.com"
 }
 
-func gitHubTarball(org, repo, commitSHA string, creds *auth.Credentials) ([]byte, error) {
+func (gitHub) Files(repoURL *url.URL, commitSHA string, creds *auth.Credentials) (map[string][]byte, error) {
+	pathParts := strings.Split(repoPath(repoURL), "/")
+	if len(pathParts) != 2 {
+		return nil, errors.New("invalid github repo URL; should be https://github.com/<org>/<repo>")
+	}
+
 	req, err := http.NewRequest(
 		http.MethodGet,
-		fmt.Sprintf("https://api.github.com/repos/%v/%v/tarball/%v", org, repo, commitSHA),
+		fmt.Sprintf("%v/repos/%v/%v/tarball/%v", gitHubAPIURL, pathParts[0], pathParts[1], commitSHA),
 		nil,
 	)
 	if err != nil {
@@ -55,55 +50,10 @@ func gitHubTarball(org, repo, commitSHA string, creds *auth.Credentials) ([]byte
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	tgzData, err := download(req)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	return ioutil.ReadAll(resp.Body)
-}
-
-func authToken(creds *auth.Credentials) string {
-	if creds == nil {
-		return ""
-	}
-
-	return creds.Password
-}
-
-func SanitizeGHFiles(files map[string][]byte, path string) (map[string][]byte, bool) {
-	out := make(map[string][]byte)
-
-	for key, value := range files {
-		repoFileName := removeFirstSegment(key)
-		if len(repoFileName) == 0 {
-			continue
-		}
-
-		if !strings.HasPrefix(repoFileName, fmt.Sprintf("%v/", path)) {
-			continue
-		}
-
-		trimmed := strings.TrimPrefix(repoFileName, path)
-		_, folder := path2.Split(path)
-		out[path2.Join(folder, trimmed)] = value
-	}
-
-	if len(out) == 0 {
-		return nil, false
-	}
-
-	return out, true
-}
-
-func removeFirstSegment(path string) string {
-	segments := strings.Split(path, `/`)
-
-	if len(segments) <= 1 {
-		return ""
-	}
 
-	return strings.Join(segments[1:], `/`)
+	return untarRepo(tgzData)
 }
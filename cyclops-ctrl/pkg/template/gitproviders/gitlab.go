@@ -0,0 +1,52 @@
+package gitproviders
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/auth"
+)
+
+// gitLab downloads repo archives from the GitLab API of gitlab.com and self-hosted GitLab instances. Self-hosted
+// instances are recognized by "gitlab" in their host name, like gitlab.example.com. Personal, project and group
+// access tokens are passed in the PRIVATE-TOKEN header.
+type gitLab struct{}
+
+func (gitLab) Matches(repoURL *url.URL) bool {
+	return strings.Contains(strings.ToLower(repoURL.Hostname()), "gitlab")
+}
+
+func (gitLab) Files(repoURL *url.URL, commitSHA string, creds *auth.Credentials) (map[string][]byte, error) {
+	// projects can be nested in subgroups, so the whole path is used as the project ID
+	project := repoPath(repoURL)
+	if len(project) == 0 || !strings.Contains(project, "/") {
+		return nil, fmt.Errorf("invalid gitlab repo URL; should be https://%v/<group>/<project>", repoURL.Host)
+	}
+
+	archiveURL := url.URL{
+		Scheme:   repoURL.Scheme,
+		Host:     repoURL.Host,
+		Path:     fmt.Sprintf("/api/v4/projects/%v/repository/archive.tar.gz", project),
+		RawPath:  fmt.Sprintf("/api/v4/projects/%v/repository/archive.tar.gz", url.PathEscape(project)),
+		RawQuery: url.Values{"sha": []string{commitSHA}}.Encode(),
+	}
+
+	req, err := http.NewRequest(http.MethodGet, archiveURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	token := authToken(creds)
+	if len(token) != 0 {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+
+	tgzData, err := download(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return untarRepo(tgzData)
+}
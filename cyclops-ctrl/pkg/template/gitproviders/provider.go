@@ -0,0 +1,100 @@
+package gitproviders
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	path2 "path"
+	"strings"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/auth"
+)
+
+// Provider downloads repository archives from a git hosting service. Fetching a single archive is much faster
+// than cloning the whole repository.
+type Provider interface {
+	// Matches returns true if the repo is hosted on the provider
+	Matches(repoURL *url.URL) bool
+
+	// Files downloads the repo archive on the given commit and returns the repo files keyed by their path
+	// relative to the root of the repo
+	Files(repoURL *url.URL, commitSHA string, creds *auth.Credentials) (map[string][]byte, error)
+}
+
+// providers are checked in order; the first provider matching the repo URL is used
+var providers = []Provider{
+	gitHub{},
+	gitLab{},
+	bitbucketCloud{},
+	bitbucketServer{},
+	azureDevOps{},
+}
+
+// ForRepo returns the provider hosting the repo, or false if the repo archive can't be downloaded from any of the
+// known providers and the repo needs to be cloned
+func ForRepo(repoURL string) (Provider, *url.URL, bool) {
+	parsed, err := url.Parse(repoURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return nil, nil, false
+	}
+
+	for _, p := range providers {
+		if p.Matches(parsed) {
+			return p, parsed, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+// FilesOnPath returns files placed under the given path of the repo. Keys of the returned files are relative to
+// the parent of the path, so they start with the name of the last path segment.
+func FilesOnPath(files map[string][]byte, path string) (map[string][]byte, bool) {
+	path = strings.Trim(path, "/")
+	out := make(map[string][]byte)
+
+	for key, value := range files {
+		if !strings.HasPrefix(key, fmt.Sprintf("%v/", path)) {
+			continue
+		}
+
+		trimmed := strings.TrimPrefix(key, path)
+		_, folder := path2.Split(path)
+		out[path2.Join(folder, trimmed)] = value
+	}
+
+	if len(out) == 0 {
+		return nil, false
+	}
+
+	return out, true
+}
+
+func download(req *http.Request) ([]byte, error) {
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download repo archive from %v; HTTP request failed with status: %s", req.URL.Host, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// repoPath returns the path of the repo without leading and trailing slashes and the .git suffix
+func repoPath(repoURL *url.URL) string {
+	return strings.TrimSuffix(strings.Trim(repoURL.Path, "/"), ".git")
+}
+
+func authToken(creds *auth.Credentials) string {
+	if creds == nil {
+		return ""
+	}
+
+	return creds.Password
+}
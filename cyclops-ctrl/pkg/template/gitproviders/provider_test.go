@@ -0,0 +1,107 @@
+package gitproviders
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestGitProviders(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test git providers")
+}
+
+var _ = Describe("Git providers", func() {
+	type testCase struct {
+		description string
+		repo        string
+		provider    Provider
+	}
+
+	testCases := []testCase{
+		{
+			description: "github repo",
+			repo:        "https://github.com/cyclops-ui/templates",
+			provider:    gitHub{},
+		},
+		{
+			description: "gitlab repo",
+			repo:        "https://gitlab.com/my-group/my-subgroup/templates.git",
+			provider:    gitLab{},
+		},
+		{
+			description: "self-hosted gitlab repo",
+			repo:        "https://gitlab.example.com/my-group/templates",
+			provider:    gitLab{},
+		},
+		{
+			description: "bitbucket cloud repo",
+			repo:        "https://user@bitbucket.org/my-workspace/templates.git",
+			provider:    bitbucketCloud{},
+		},
+		{
+			description: "bitbucket server repo",
+			repo:        "https://git.example.com/scm/proj/templates.git",
+			provider:    bitbucketServer{},
+		},
+		{
+			description: "bitbucket server repo on a context path",
+			repo:        "https://example.com/bitbucket/scm/proj/templates",
+			provider:    bitbucketServer{},
+		},
+		{
+			description: "nested repo in a group named scm",
+			repo:        "https://git.example.com/scm/my-group/my-subgroup/templates.git",
+		},
+		{
+			description: "azure devops repo",
+			repo:        "https://my-org@dev.azure.com/my-org/my-project/_git/templates",
+			provider:    azureDevOps{},
+		},
+		{
+			description: "legacy azure devops repo",
+			repo:        "https://my-org.visualstudio.com/my-project/_git/templates",
+			provider:    azureDevOps{},
+		},
+		{
+			description: "unknown host",
+			repo:        "https://git.example.com/my-org/templates.git",
+		},
+		{
+			description: "ssh repo",
+			repo:        "git@github.com:cyclops-ui/templates.git",
+		},
+	}
+
+	for _, testCase := range testCases {
+		tc := testCase
+		It(tc.description, func() {
+			provider, _, ok := ForRepo(tc.repo)
+			if tc.provider == nil {
+				Expect(ok).To(BeFalse())
+				return
+			}
+
+			Expect(ok).To(BeTrue())
+			Expect(provider).To(Equal(tc.provider))
+		})
+	}
+
+	It("returns files on path", func() {
+		files, ok := FilesOnPath(map[string][]byte{
+			"README.md":                     []byte("readme"),
+			"charts/app/Chart.yaml":         []byte("name: app"),
+			"charts/app/templates/a.yaml":   []byte("kind: Service"),
+			"charts/application/Chart.yaml": []byte("name: application"),
+		}, "/charts/app")
+		Expect(ok).To(BeTrue())
+		Expect(files).To(Equal(map[string][]byte{
+			"app/Chart.yaml":       []byte("name: app"),
+			"app/templates/a.yaml": []byte("kind: Service"),
+		}))
+
+		_, ok = FilesOnPath(map[string][]byte{"README.md": []byte("readme")}, "charts/app")
+		Expect(ok).To(BeFalse())
+	})
+})
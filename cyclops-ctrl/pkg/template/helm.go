@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path"
@@ -21,6 +20,7 @@ import (
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/mapper"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/helm"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/auth"
 )
 
 // extractPropertyOrder extracts property names from JSON schema in the order they appear
@@ -171,12 +171,16 @@ func findNestedPropertyOrder(jsonStr, propertyName string) []string {
 }
 
 func (r Repo) LoadHelmChart(repo, chart, version, resolvedVersion string) (*models.Template, error) {
-	var err error
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return nil, err
+	}
+
 	strictVersion := version
 	if len(resolvedVersion) > 0 {
 		strictVersion = resolvedVersion
 	} else if !isValidVersion(version) {
-		strictVersion, err = getRepoStrictVersion(repo, chart, version)
+		strictVersion, err = getRepoStrictVersion(repo, chart, version, creds)
 		if err != nil {
 			return nil, err
 		}
@@ -187,7 +191,7 @@ func (r Repo) LoadHelmChart(repo, chart, version, resolvedVersion string) (*mode
 		return cached, nil
 	}
 
-	tgzData, err := r.loadFromHelmChartRepo(repo, chart, version)
+	tgzData, err := loadFromHelmChartRepo(repo, chart, strictVersion, creds)
 	if err != nil {
 		return nil, err
 	}
@@ -211,10 +215,14 @@ func (r Repo) LoadHelmChart(repo, chart, version, resolvedVersion string) (*mode
 }
 
 func (r Repo) LoadHelmChartInitialValues(repo, chart, version string) (map[string]interface{}, error) {
-	var err error
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return nil, err
+	}
+
 	strictVersion := version
 	if !isValidVersion(version) {
-		strictVersion, err = getRepoStrictVersion(repo, chart, version)
+		strictVersion, err = getRepoStrictVersion(repo, chart, version, creds)
 		if err != nil {
 			return nil, err
 		}
@@ -225,7 +233,7 @@ func (r Repo) LoadHelmChartInitialValues(repo, chart, version string) (map[strin
 		return cached, nil
 	}
 
-	tgzData, err := r.loadFromHelmChartRepo(repo, chart, version)
+	tgzData, err := loadFromHelmChartRepo(repo, chart, strictVersion, creds)
 	if err != nil {
 		return nil, err
 	}
@@ -245,7 +253,7 @@ func (r Repo) LoadHelmChartInitialValues(repo, chart, version string) (map[strin
 	return initial, nil
 }
 
-func IsHelmRepo(repo string) (bool, error) {
+func IsHelmRepo(repo string, creds *auth.Credentials) (bool, error) {
 	indexURL, err := url.JoinPath(repo, "index.yaml")
 	if err != nil {
 		return false, err
@@ -255,6 +263,7 @@ func IsHelmRepo(repo string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	setBasicAuth(req, creds)
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
@@ -266,13 +275,18 @@ func IsHelmRepo(repo string) (bool, error) {
 	return resp.StatusCode == http.StatusOK, nil
 }
 
-func (r Repo) loadFromHelmChartRepo(repo, chart, version string) ([]byte, error) {
-	tgzURL, err := getTarUrl(repo, chart, version)
+func loadFromHelmChartRepo(repo, chart, version string, creds *auth.Credentials) ([]byte, error) {
+	tgzURL, err := getTarUrl(repo, chart, version, creds)
 	if err != nil {
 		return nil, err
 	}
 
-	return downloadFile(tgzURL)
+	// same as Helm, repo credentials are not passed to charts hosted on other domains
+	if !sameHost(repo, tgzURL) {
+		creds = nil
+	}
+
+	return downloadFile(tgzURL, creds)
 }
 
 func (r Repo) mapHelmChart(chartName string, files map[string][]byte) (*models.Template, error) {
@@ -479,25 +493,8 @@ func overlayValues(existing interface{}, overlay interface{}) interface{} {
 	return existingMap
 }
 
-func getTarUrl(repo, chart, version string) (string, error) {
-	indexURL, err := url.JoinPath(repo, "index.yaml")
-	if err != nil {
-		return "", err
-	}
-
-	response, err := http.Get(indexURL)
-	if err != nil {
-		return "", err
-	}
-	defer response.Body.Close()
-
-	body, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return "", err
-	}
-
-	var data helm.Index
-	err = yaml.Unmarshal(body, &data)
+func getTarUrl(repo, chart, version string, creds *auth.Credentials) (string, error) {
+	data, err := loadRepoIndex(repo, creds)
 	if err != nil {
 		return "", err
 	}
@@ -517,41 +514,85 @@ func getTarUrl(repo, chart, version string) (string, error) {
 				return "", errors.New(fmt.Sprintf("no URL on version %v of chart %v and repo %v", version, chart, repo))
 			}
 
-			return entry.URLs[0], nil
+			return resolveChartURL(repo, entry.URLs[0])
 		}
 	}
 
 	return "", errors.New(fmt.Sprintf("version %v not found in chart %v and repo %v", version, chart, repo))
 }
 
-func getRepoStrictVersion(repo, chart, version string) (string, error) {
-	indexURL, err := url.JoinPath(repo, "index.yaml")
+func getRepoStrictVersion(repo, chart, version string, creds *auth.Credentials) (string, error) {
+	data, err := loadRepoIndex(repo, creds)
 	if err != nil {
 		return "", err
 	}
 
-	response, err := http.Get(indexURL)
+	if _, ok := data.Entries[chart]; !ok {
+		return "", errors.New(fmt.Sprintf("chart %v not found in repo %v", chart, repo))
+	}
+
+	return resolveVersion(data.Entries[chart], version)
+}
+
+// loadRepoIndex fetches and parses the index of a Helm chart repo
+func loadRepoIndex(repo string, creds *auth.Credentials) (*helm.Index, error) {
+	indexURL, err := url.JoinPath(repo, "index.yaml")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer response.Body.Close()
 
-	body, err := ioutil.ReadAll(response.Body)
+	body, err := downloadFile(indexURL, creds)
 	if err != nil {
-		return "", err
+		return nil, errors.New(fmt.Sprintf("failed to fetch index of repo %v: %v", repo, err))
 	}
 
 	var data helm.Index
-	err = yaml.Unmarshal(body, &data)
+	if err := yaml.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// resolveChartURL resolves chart URLs relative to the repo, as listed in indexes of repos like ChartMuseum
+func resolveChartURL(repo, chartURL string) (string, error) {
+	parsed, err := url.Parse(chartURL)
 	if err != nil {
 		return "", err
 	}
 
-	if _, ok := data.Entries[chart]; !ok {
-		return "", errors.New(fmt.Sprintf("chart %v not found in repo %v", chart, repo))
+	if parsed.IsAbs() {
+		return chartURL, nil
 	}
 
-	return resolveVersion(data.Entries[chart], version)
+	base, err := url.Parse(strings.TrimSuffix(repo, "/") + "/")
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(parsed).String(), nil
+}
+
+func sameHost(a, b string) bool {
+	aURL, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+
+	bURL, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+
+	return aURL.Host == bURL.Host
+}
+
+func setBasicAuth(req *http.Request, creds *auth.Credentials) {
+	if creds == nil || (len(creds.Username) == 0 && len(creds.Password) == 0) {
+		return
+	}
+
+	req.SetBasicAuth(creds.Username, creds.Password)
 }
 
 func resolveVersion(indexEntries []helm.IndexEntry, version string) (string, error) {
@@ -567,8 +608,14 @@ func resolveVersion(indexEntries []helm.IndexEntry, version string) (string, err
 	return resolveSemver(version, versions)
 }
 
-func downloadFile(url string) ([]byte, error) {
-	response, err := http.Get(url)
+func downloadFile(url string, creds *auth.Credentials) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	setBasicAuth(req, creds)
+
+	response, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -629,3 +676,33 @@ func dependencyExists(name string, existing []*models.Template) bool {
 
 	return false
 }
+
+// listHelmChartVersions lists versions of the chart from the repo index, with the date each version was created
+func listHelmChartVersions(repo, chart string, creds *auth.Credentials, includePrerelease bool) ([]models.TemplateRevision, error) {
+	data, err := loadRepoIndex(repo, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, ok := data.Entries[chart]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("chart %v not found in repo %v", chart, repo))
+	}
+
+	revisions := make([]models.TemplateRevision, 0, len(entries))
+	for _, entry := range entries {
+		revision := models.TemplateRevision{
+			Name: entry.Version,
+			Type: models.TemplateRevisionTypeVersion,
+		}
+
+		if !entry.Created.IsZero() {
+			created := entry.Created
+			revision.Date = &created
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return sortRevisions(revisions, includePrerelease), nil
+}
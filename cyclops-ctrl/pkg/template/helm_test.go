@@ -0,0 +1,154 @@
+package template
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/auth"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/mocks"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/template/cache"
+)
+
+func TestTemplates(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test template repos")
+}
+
+// chartArchive packs a chart with a Chart.yaml of the given version the same way helm package does
+func chartArchive(version string) []byte {
+	files := []struct {
+		name    string
+		content string
+	}{
+		{name: "app/Chart.yaml", content: fmt.Sprintf("apiVersion: v2\nname: app\nversion: %v\n", version)},
+		{name: "app/values.yaml", content: "replicas: 1\n"},
+	}
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, file := range files {
+		name, content := file.name, file.content
+		Expect(tarWriter.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))})).To(Succeed())
+		_, err := tarWriter.Write([]byte(content))
+		Expect(err).To(BeNil())
+	}
+
+	Expect(tarWriter.Close()).To(Succeed())
+	Expect(gzipWriter.Close()).To(Succeed())
+
+	return buf.Bytes()
+}
+
+var _ = Describe("Helm chart repos", func() {
+	var repo, charts *httptest.Server
+	var repoRequests, chartsRequests []*http.Request
+	var index string
+
+	BeforeEach(func() {
+		repoRequests, chartsRequests = nil, nil
+
+		serve := func(requests *[]*http.Request) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				*requests = append(*requests, r)
+
+				switch r.URL.Path {
+				case "/index.yaml":
+					_, _ = w.Write([]byte(index))
+				case "/charts/app-1.0.0.tgz":
+					_, _ = w.Write(chartArchive("1.0.0"))
+				case "/charts/app-1.1.0.tgz":
+					_, _ = w.Write(chartArchive("1.1.0"))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}
+		}
+
+		repo = httptest.NewServer(serve(&repoRequests))
+		charts = httptest.NewServer(serve(&chartsRequests))
+		DeferCleanup(repo.Close)
+		DeferCleanup(charts.Close)
+
+		index = `
+entries:
+  app:
+    - version: 1.1.0
+      urls: [charts/app-1.1.0.tgz]
+    - version: 1.0.0
+      urls: [charts/app-1.0.0.tgz]
+`
+	})
+
+	creds := &auth.Credentials{Username: "user", Password: "password"}
+
+	expectBasicAuth := func(r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		Expect(ok).To(BeTrue())
+		Expect(username).To(Equal("user"))
+		Expect(password).To(Equal("password"))
+	}
+
+	It("downloads charts on relative URLs with the repo credentials", func() {
+		tgzData, err := loadFromHelmChartRepo(repo.URL, "app", "1.0.0", creds)
+
+		Expect(err).To(BeNil())
+		Expect(tgzData).To(Equal(chartArchive("1.0.0")))
+		Expect(repoRequests).To(HaveLen(2))
+		Expect(repoRequests[0].URL.Path).To(Equal("/index.yaml"))
+		expectBasicAuth(repoRequests[0])
+		Expect(repoRequests[1].URL.Path).To(Equal("/charts/app-1.0.0.tgz"))
+		expectBasicAuth(repoRequests[1])
+	})
+
+	It("resolves relative URLs of repos on a path", func() {
+		_, err := loadFromHelmChartRepo(repo.URL+"/", "app", "1.1.0", nil)
+
+		Expect(err).To(BeNil())
+		Expect(repoRequests).To(HaveLen(2))
+		Expect(repoRequests[1].URL.Path).To(Equal("/charts/app-1.1.0.tgz"))
+		Expect(repoRequests[1].Header.Get("Authorization")).To(BeEmpty())
+	})
+
+	It("doesn't pass repo credentials to charts hosted on other hosts", func() {
+		index = fmt.Sprintf(`
+entries:
+  app:
+    - version: 1.0.0
+      urls: [%v/charts/app-1.0.0.tgz]
+`, charts.URL)
+
+		tgzData, err := loadFromHelmChartRepo(repo.URL, "app", "1.0.0", creds)
+
+		Expect(err).To(BeNil())
+		Expect(tgzData).To(Equal(chartArchive("1.0.0")))
+		Expect(repoRequests).To(HaveLen(1))
+		expectBasicAuth(repoRequests[0])
+		Expect(chartsRequests).To(HaveLen(1))
+		Expect(chartsRequests[0].Header.Get("Authorization")).To(BeEmpty())
+	})
+
+	It("downloads the resolved version of version ranges", func() {
+		k8sClient := &mocks.IKubernetesClient{}
+		k8sClient.On("ListTemplateAuthRules").Return([]v1alpha1.TemplateAuthRule{}, nil)
+
+		templatesRepo := NewRepo(auth.NewTemplatesResolver(k8sClient), cache.NewInMemoryTemplatesCache())
+
+		template, err := templatesRepo.GetTemplate(repo.URL, "app", "^1.0.0", "1.0.0", v1alpha1.TemplateSourceTypeHelm)
+
+		Expect(err).To(BeNil())
+		Expect(template.ResolvedVersion).To(Equal("1.0.0"))
+		Expect(template.HelmChartMetadata.Version).To(Equal("1.0.0"))
+		Expect(repoRequests[len(repoRequests)-1].URL.Path).To(Equal("/charts/app-1.0.0.tgz"))
+	})
+})
@@ -1,6 +1,7 @@
 package template
 
 import (
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -12,16 +13,21 @@ import (
 
 	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/auth"
 )
 
 func (r Repo) LoadOCIHelmChart(repo, chart, version, resolvedVersion string) (*models.Template, error) {
-	var err error
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return nil, err
+	}
+
 	strictVersion := version
 
 	if len(resolvedVersion) > 0 {
 		strictVersion = resolvedVersion
 	} else if !isValidVersion(version) {
-		strictVersion, err = getOCIStrictVersion(repo, chart, version)
+		strictVersion, err = getOCIStrictVersion(repo, chart, version, creds)
 		if err != nil {
 			return nil, err
 		}
@@ -33,7 +39,7 @@ func (r Repo) LoadOCIHelmChart(repo, chart, version, resolvedVersion string) (*m
 	}
 
 	var tgzData []byte
-	tgzData, err = loadOCIHelmChartBytes(repo, chart, version)
+	tgzData, err = loadOCIHelmChartBytes(repo, chart, strictVersion, creds)
 	if err != nil {
 		return nil, err
 	}
@@ -57,10 +63,14 @@ func (r Repo) LoadOCIHelmChart(repo, chart, version, resolvedVersion string) (*m
 }
 
 func (r Repo) LoadOCIHelmChartInitialValues(repo, chart, version string) (map[string]interface{}, error) {
-	var err error
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return nil, err
+	}
+
 	strictVersion := version
 	if !isValidVersion(version) {
-		strictVersion, err = getOCIStrictVersion(repo, chart, version)
+		strictVersion, err = getOCIStrictVersion(repo, chart, version, creds)
 		if err != nil {
 			return nil, err
 		}
@@ -71,7 +81,7 @@ func (r Repo) LoadOCIHelmChartInitialValues(repo, chart, version string) (map[st
 		return cached, nil
 	}
 
-	tgzData, err := loadOCIHelmChartBytes(repo, chart, version)
+	tgzData, err := loadOCIHelmChartBytes(repo, chart, strictVersion, creds)
 	if err != nil {
 		return nil, err
 	}
@@ -91,34 +101,34 @@ func (r Repo) LoadOCIHelmChartInitialValues(repo, chart, version string) (map[st
 	return initial, nil
 }
 
-func loadOCIHelmChartBytes(repo, chart, version string) ([]byte, error) {
+func loadOCIHelmChartBytes(repo, chart, version string, creds *auth.Credentials) ([]byte, error) {
 	var err error
 	if !isValidVersion(version) {
-		version, err = getOCIStrictVersion(repo, chart, version)
+		version, err = getOCIStrictVersion(repo, chart, version, creds)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	token, err := authorizeOCI(repo, chart, version)
+	authorization, err := authorizeOCI(repo, chart, version, creds)
 	if err != nil {
 		return nil, err
 	}
 
-	digest, err := fetchDigest(repo, chart, version, token)
+	digest, err := fetchDigest(repo, chart, version, authorization)
 	if err != nil {
 		return nil, err
 	}
 
-	contentDigest, err := fetchContentDigest(repo, chart, digest, token)
+	contentDigest, err := fetchContentDigest(repo, chart, digest, authorization)
 	if err != nil {
 		return nil, err
 	}
 
-	return loadOCITar(repo, chart, contentDigest, token)
+	return loadOCITar(repo, chart, contentDigest, authorization)
 }
 
-func loadOCITar(repo, chart, digest, token string) ([]byte, error) {
+func loadOCITar(repo, chart, digest, authorization string) ([]byte, error) {
 	bURL, err := blobURL(repo, chart, digest)
 	if err != nil {
 		return nil, err
@@ -131,8 +141,8 @@ func loadOCITar(repo, chart, digest, token string) ([]byte, error) {
 
 	req.Header.Set("User-Agent", "Helm/3.13.3")
 	req.Header.Set("Accept", "application/vnd.cncf.helm.config.v1+json, */*")
-	if len(token) != 0 {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
+	if len(authorization) != 0 {
+		req.Header.Set("Authorization", authorization)
 	}
 
 	client := &http.Client{}
@@ -145,7 +155,7 @@ func loadOCITar(repo, chart, digest, token string) ([]byte, error) {
 	return ioutil.ReadAll(resp.Body)
 }
 
-func fetchContentDigest(repo, chart, digest, token string) (string, error) {
+func fetchContentDigest(repo, chart, digest, authorization string) (string, error) {
 	dURL, err := contentDigestURL(repo, chart, digest)
 	if err != nil {
 		return "", err
@@ -158,8 +168,8 @@ func fetchContentDigest(repo, chart, digest, token string) (string, error) {
 
 	req.Header.Set("User-Agent", "Helm/3.13.3")
 	req.Header.Set("Accept", "application/vnd.oci.image.manifest.v1+json, */*")
-	if len(token) != 0 {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
+	if len(authorization) != 0 {
+		req.Header.Set("Authorization", authorization)
 	}
 
 	client := &http.Client{}
@@ -198,7 +208,7 @@ func fetchContentDigest(repo, chart, digest, token string) (string, error) {
 	return ct.Layers[0].Digest, nil
 }
 
-func fetchDigest(repo, chart, version, token string) (string, error) {
+func fetchDigest(repo, chart, version, authorization string) (string, error) {
 	dURL, err := digestURL(repo, chart, version)
 	if err != nil {
 		return "", err
@@ -211,8 +221,8 @@ func fetchDigest(repo, chart, version, token string) (string, error) {
 
 	req.Header.Set("User-Agent", "Helm/3.13.3")
 	req.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v2+json, application/vnd.docker.distribution.manifest.list.v2+json, application/vnd.oci.image.manifest.v1+json, application/vnd.oci.image.index.v1+json, */*")
-	if len(token) != 0 {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
+	if len(authorization) != 0 {
+		req.Header.Set("Authorization", authorization)
 	}
 
 	client := &http.Client{}
@@ -225,8 +235,8 @@ func fetchDigest(repo, chart, version, token string) (string, error) {
 	return resp.Header.Get("docker-content-digest"), nil
 }
 
-func getOCIStrictVersion(repo, chart, version string) (string, error) {
-	allTags, err := GetOCIChartTags(repo, chart)
+func getOCIStrictVersion(repo, chart, version string, creds *auth.Credentials) (string, error) {
+	allTags, err := GetOCIChartTags(repo, chart, creds)
 	if err != nil {
 		return "", err
 	}
@@ -234,8 +244,8 @@ func getOCIStrictVersion(repo, chart, version string) (string, error) {
 	return resolveSemver(version, allTags)
 }
 
-func GetOCIChartTags(repo, chart string) ([]string, error) {
-	token, err := authorizeOCITags(repo, chart)
+func GetOCIChartTags(repo, chart string, creds *auth.Credentials) ([]string, error) {
+	authorization, err := authorizeOCITags(repo, chart, creds)
 	if err != nil {
 		return nil, err
 	}
@@ -254,8 +264,8 @@ func GetOCIChartTags(repo, chart string) ([]string, error) {
 		}
 
 		req.Header.Set("User-Agent", "Helm/3.13.3")
-		if len(token) != 0 {
-			req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
+		if len(authorization) != 0 {
+			req.Header.Set("Authorization", authorization)
 		}
 
 		resp, err := client.Do(req)
@@ -303,15 +313,12 @@ func GetOCIChartTags(repo, chart string) ([]string, error) {
 	return allTags, err
 }
 
-func authorizeOCI(repo, chart, version string) (string, error) {
-	// region head
+func authorizeOCI(repo, chart, version string, creds *auth.Credentials) (string, error) {
 	dURL, err := digestURL(repo, chart, version)
 	if err != nil {
 		return "", err
 	}
 
-	client := &http.Client{}
-
 	req, err := http.NewRequest(http.MethodHead, dURL.String(), nil)
 	if err != nil {
 		return "", err
@@ -320,97 +327,56 @@ func authorizeOCI(repo, chart, version string) (string, error) {
 	req.Header.Set("User-Agent", "Helm/3.13.3")
 	req.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v2+json, application/vnd.docker.distribution.manifest.list.v2+json, application/vnd.oci.image.manifest.v1+json, application/vnd.oci.image.index.v1+json, */*")
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var authUrlRealm, service, scope string
-	if resp.StatusCode == http.StatusOK {
-		return "", nil
-	} else if resp.StatusCode == http.StatusUnauthorized {
-		authUrlRealm, service, scope = parseAuthenticateHeader(resp.Header.Get("WWW-Authenticate"))
-	}
-
-	if resp.StatusCode != http.StatusUnauthorized {
-		return "", errors.New(fmt.Sprintf("unexpected status code: %v", resp.StatusCode))
-	}
-
-	// endregion
-
-	// region get token
-
-	params := url.Values{}
-	params.Add("service", service)
-	params.Add("scope", scope)
-
-	authUrl := fmt.Sprintf("%v?%v", authUrlRealm, params.Encode())
-
-	req, err = http.NewRequest(http.MethodGet, authUrl, nil)
-	if err != nil {
-		fmt.Println("Error creating request:", err)
-		return "", err
-	}
-
-	req.Header.Set("User-Agent", "Helm/3.13.3")
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	return authorizeOCIRequest(req, creds)
+}
 
-	resp, err = client.Do(req)
+func authorizeOCITags(repo, chart string, creds *auth.Credentials) (string, error) {
+	tURL, err := tagsURL(repo, chart)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	responseBody, err := ioutil.ReadAll(resp.Body)
+	req, err := http.NewRequest(http.MethodGet, tURL.String(), nil)
 	if err != nil {
 		return "", err
 	}
 
-	var ar struct {
-		Token string `json:"token"`
-	}
-
-	if err := json.Unmarshal(responseBody, &ar); err != nil {
-		return "", err
-	}
-
-	return ar.Token, nil
-
-	// endregion
+	return authorizeOCIRequest(req, creds)
 }
 
-func authorizeOCITags(repo, chart string) (string, error) {
+// authorizeOCIRequest sends the request anonymously and answers the registry challenge if it is rejected. Returns
+// the Authorization header for following requests to the registry, or an empty string if the registry allows
+// anonymous access. Registries asking for basic auth get the credentials directly, and registries asking for a
+// bearer token get one from their token service, authenticated with the credentials if there are any.
+func authorizeOCIRequest(req *http.Request, creds *auth.Credentials) (string, error) {
 	// region head
-	tURL, err := tagsURL(repo, chart)
-	if err != nil {
-		return "", err
-	}
-
 	client := &http.Client{}
 
-	req, err := http.NewRequest(http.MethodGet, tURL.String(), nil)
-	if err != nil {
-		return "", err
-	}
-
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
-	var authUrlRealm, service, scope string
 	if resp.StatusCode == http.StatusOK {
 		return "", nil
-	} else if resp.StatusCode == http.StatusUnauthorized {
-		authUrlRealm, service, scope = parseAuthenticateHeader(resp.Header.Get("WWW-Authenticate"))
 	}
 
 	if resp.StatusCode != http.StatusUnauthorized {
 		return "", errors.New(fmt.Sprintf("unexpected status code: %v", resp.StatusCode))
 	}
 
+	challenge := resp.Header.Get("WWW-Authenticate")
+	if strings.HasPrefix(strings.ToLower(challenge), "basic") {
+		if creds == nil {
+			return "", errors.New(fmt.Sprintf("registry %v requires credentials", req.URL.Host))
+		}
+
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds.Username+":"+creds.Password)), nil
+	}
+
+	authUrlRealm, service, scope := parseAuthenticateHeader(challenge)
+
 	// endregion
 
 	// region get token
@@ -420,35 +386,52 @@ func authorizeOCITags(repo, chart string) (string, error) {
 
 	authUrl := fmt.Sprintf("%v?%v", authUrlRealm, params.Encode())
 
-	req, err = http.NewRequest(http.MethodGet, authUrl, nil)
+	tokenReq, err := http.NewRequest(http.MethodGet, authUrl, nil)
 	if err != nil {
 		fmt.Println("Error creating request:", err)
 		return "", err
 	}
 
-	req.Header.Set("User-Agent", "Helm/3.13.3")
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	tokenReq.Header.Set("User-Agent", "Helm/3.13.3")
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	if creds != nil {
+		tokenReq.SetBasicAuth(creds.Username, creds.Password)
+	}
 
-	resp, err = client.Do(req)
+	tokenResp, err := client.Do(tokenReq)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
+	defer tokenResp.Body.Close()
 
-	responseBody, err := ioutil.ReadAll(resp.Body)
+	if tokenResp.StatusCode != http.StatusOK {
+		return "", errors.New(fmt.Sprintf("failed to get token for registry %v: unexpected status code: %v", req.URL.Host, tokenResp.StatusCode))
+	}
+
+	responseBody, err := ioutil.ReadAll(tokenResp.Body)
 	if err != nil {
 		return "", err
 	}
 
 	var ar struct {
-		Token string `json:"token"`
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
 	}
 
 	if err := json.Unmarshal(responseBody, &ar); err != nil {
 		return "", err
 	}
 
-	return ar.Token, nil
+	token := ar.Token
+	if len(token) == 0 {
+		token = ar.AccessToken
+	}
+
+	if len(token) == 0 {
+		return "", nil
+	}
+
+	return "Bearer " + token, nil
 
 	// endregion
 }
@@ -0,0 +1,87 @@
+package template
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/auth"
+)
+
+var _ = Describe("OCI registry authorization", func() {
+	var registry *httptest.Server
+	var challenge, tokenResponse string
+	var tokenRequests []*http.Request
+
+	BeforeEach(func() {
+		tokenRequests = nil
+
+		registry = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/token" {
+				tokenRequests = append(tokenRequests, r)
+				_, _ = w.Write([]byte(tokenResponse))
+				return
+			}
+
+			w.Header().Set("WWW-Authenticate", challenge)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		DeferCleanup(registry.Close)
+	})
+
+	manifestRequest := func() *http.Request {
+		req, err := http.NewRequest(http.MethodHead, registry.URL+"/v2/charts/app/manifests/1.0.0", nil)
+		Expect(err).To(BeNil())
+		return req
+	}
+
+	It("passes credentials to registries asking for basic auth", func() {
+		challenge = `Basic realm="registry"`
+
+		authorization, err := authorizeOCIRequest(manifestRequest(), &auth.Credentials{Username: "user", Password: "password"})
+
+		Expect(err).To(BeNil())
+		Expect(authorization).To(Equal("Basic dXNlcjpwYXNzd29yZA=="))
+	})
+
+	It("fails on basic auth challenges without credentials", func() {
+		challenge = `Basic realm="registry"`
+
+		_, err := authorizeOCIRequest(manifestRequest(), nil)
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("exchanges credentials for a bearer token", func() {
+		challenge = fmt.Sprintf(`Bearer realm="%v/token",service="registry.example.com",scope="repository:charts/app:pull"`, registry.URL)
+		tokenResponse = `{"token": "registry-token"}`
+
+		authorization, err := authorizeOCIRequest(manifestRequest(), &auth.Credentials{Username: "user", Password: "password"})
+
+		Expect(err).To(BeNil())
+		Expect(authorization).To(Equal("Bearer registry-token"))
+		Expect(tokenRequests).To(HaveLen(1))
+		Expect(tokenRequests[0].URL.Query().Get("service")).To(Equal("registry.example.com"))
+		Expect(tokenRequests[0].URL.Query().Get("scope")).To(Equal("repository:charts/app:pull"))
+
+		username, password, ok := tokenRequests[0].BasicAuth()
+		Expect(ok).To(BeTrue())
+		Expect(username).To(Equal("user"))
+		Expect(password).To(Equal("password"))
+	})
+
+	It("gets anonymous bearer tokens returned as access_token", func() {
+		challenge = fmt.Sprintf(`Bearer realm="%v/token",service="registry.example.com",scope="repository:charts/app:pull"`, registry.URL)
+		tokenResponse = `{"access_token": "anonymous-token"}`
+
+		authorization, err := authorizeOCIRequest(manifestRequest(), nil)
+
+		Expect(err).To(BeNil())
+		Expect(authorization).To(Equal("Bearer anonymous-token"))
+		Expect(tokenRequests).To(HaveLen(1))
+		Expect(tokenRequests[0].Header.Get("Authorization")).To(BeEmpty())
+	})
+})
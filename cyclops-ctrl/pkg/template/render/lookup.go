@@ -0,0 +1,121 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/cluster/k8sclient"
+)
+
+// lookupClientProvider provides clients to the Helm lookup template function. Only kinds in the allowlist can
+// be looked up, and namespaced resources only in the module target namespace. Lookups of other kinds return no
+// resources, the same as lookups with an empty allowlist.
+type lookupClientProvider struct {
+	k8sClient       k8sclient.IKubernetesClient
+	allowedKinds    []string
+	targetNamespace string
+}
+
+func (p lookupClientProvider) GetClientFor(apiVersion, kind string) (dynamic.NamespaceableResourceInterface, bool, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !isLookupAllowed(p.allowedKinds, gv.Group, kind) {
+		return emptyLookupClient{
+			resource: schema.GroupResource{Group: gv.Group, Resource: strings.ToLower(kind)},
+		}, false, nil
+	}
+
+	resourceClient, namespaced, err := p.k8sClient.ResourceClient(apiVersion, kind)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !namespaced {
+		return resourceClient, false, nil
+	}
+
+	return namespacedLookupClient{
+		NamespaceableResourceInterface: resourceClient,
+		namespace:                      p.targetNamespace,
+	}, true, nil
+}
+
+// isLookupAllowed checks if the kind is in the allowlist, either by itself or qualified with its group
+func isLookupAllowed(allowedKinds []string, group, kind string) bool {
+	for _, allowed := range allowedKinds {
+		if allowed == kind || allowed == fmt.Sprintf("%v/%v", group, kind) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// namespacedLookupClient limits lookups of namespaced resources to a single namespace. Lookups without a namespace
+// are done in it instead of in all namespaces.
+type namespacedLookupClient struct {
+	dynamic.NamespaceableResourceInterface
+	namespace string
+}
+
+func (c namespacedLookupClient) Namespace(namespace string) dynamic.ResourceInterface {
+	if namespace != c.namespace {
+		return deniedLookupClient{
+			ResourceInterface: c.NamespaceableResourceInterface.Namespace(namespace),
+			err:               errors.Errorf("lookup in namespace %v is not allowed, only in the module target namespace %v", namespace, c.namespace),
+		}
+	}
+
+	return c.NamespaceableResourceInterface.Namespace(namespace)
+}
+
+func (c namespacedLookupClient) Get(ctx context.Context, name string, options metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	return c.NamespaceableResourceInterface.Namespace(c.namespace).Get(ctx, name, options, subresources...)
+}
+
+func (c namespacedLookupClient) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return c.NamespaceableResourceInterface.Namespace(c.namespace).List(ctx, opts)
+}
+
+// deniedLookupClient fails all lookups, which only get and list resources
+type deniedLookupClient struct {
+	dynamic.ResourceInterface
+	err error
+}
+
+func (c deniedLookupClient) Get(context.Context, string, metav1.GetOptions, ...string) (*unstructured.Unstructured, error) {
+	return nil, c.err
+}
+
+func (c deniedLookupClient) List(context.Context, metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return nil, c.err
+}
+
+// emptyLookupClient finds no resources. Helm lookups return empty results for resources that are not found.
+type emptyLookupClient struct {
+	dynamic.NamespaceableResourceInterface
+	resource schema.GroupResource
+}
+
+func (c emptyLookupClient) Namespace(string) dynamic.ResourceInterface {
+	return c
+}
+
+func (c emptyLookupClient) Get(_ context.Context, name string, _ metav1.GetOptions, _ ...string) (*unstructured.Unstructured, error) {
+	return nil, apierrors.NewNotFound(c.resource, name)
+}
+
+func (c emptyLookupClient) List(context.Context, metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return nil, apierrors.NewNotFound(c.resource, "")
+}
@@ -0,0 +1,126 @@
+package render
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	helmchart "helm.sh/helm/v3/pkg/chart"
+	apiv1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/yaml"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/helm"
+	k8smocks "github.com/andersan81/cyclops/cyclops-ctrl/pkg/mocks"
+)
+
+var _ = Describe("Template lookups", func() {
+	var k8sClient *k8smocks.IKubernetesClient
+
+	template := &models.Template{
+		HelmChartMetadata: &helm.Metadata{Name: "demo"},
+		Templates: []*helmchart.File{
+			{
+				Name: "templates/lookups.yaml",
+				Data: []byte(`{{- $settings := lookup "v1" "ConfigMap" "" "settings" }}
+{{- $configMaps := lookup "v1" "ConfigMap" "" "" }}
+{{- $credentials := lookup "v1" "Secret" "apps" "credentials" }}
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: lookups
+data:
+  settings: "{{ (($settings).data).replicas }}"
+  configMaps: "{{ len (default list ($configMaps).items) }}"
+  credentialsFound: "{{ not (empty $credentials) }}"
+`),
+			},
+		},
+	}
+
+	configMap := func(namespace, name, replicas string) *apiv1.ConfigMap {
+		return &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       map[string]string{"replicas": replicas},
+		}
+	}
+
+	module := cyclopsv1alpha1.Module{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "team-a", Generation: 1},
+		Spec: cyclopsv1alpha1.ModuleSpec{
+			TargetNamespace: "apps",
+			Values:          apiextensionsv1.JSON{Raw: []byte(`{}`)},
+		},
+	}
+
+	renderedData := func(renderer *Renderer) map[string]string {
+		manifest, err := renderer.HelmTemplate(module, template)
+		Expect(err).To(BeNil())
+
+		var rendered struct {
+			Data map[string]string `json:"data"`
+		}
+		Expect(yaml.Unmarshal([]byte(manifest[:len(manifest)-len("\n---\n")]), &rendered)).To(Succeed())
+
+		return rendered.Data
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apiv1.AddToScheme(scheme)).To(Succeed())
+
+		dynamicClient := dynamicfake.NewSimpleDynamicClient(
+			scheme,
+			configMap("apps", "settings", "3"),
+			configMap("apps", "features", "1"),
+			configMap("other", "settings", "5"),
+			&apiv1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "apps"},
+				Data:       map[string][]byte{"password": []byte("secret")},
+			},
+		)
+
+		k8sClient = &k8smocks.IKubernetesClient{}
+		k8sClient.On("ForCluster", "").Return(k8sClient, nil)
+		k8sClient.On("VersionInfo").Return(&version.Info{Major: "1", Minor: "30"}, nil)
+		k8sClient.On("APIVersions").Return([]string{"v1"}, nil)
+		k8sClient.On("ResourceClient", "v1", "ConfigMap").
+			Return(dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}), true, nil)
+		k8sClient.On("ResourceClient", "v1", "Secret").
+			Return(dynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}), true, nil)
+	})
+
+	It("looks up allowed kinds in the module target namespace", func() {
+		data := renderedData(NewRenderer(k8sClient, []string{"ConfigMap"}))
+
+		Expect(data["settings"]).To(Equal("3"))
+		Expect(data["configMaps"]).To(Equal("2"))
+	})
+
+	It("returns no resources for kinds that are not allowed", func() {
+		data := renderedData(NewRenderer(k8sClient, []string{"ConfigMap"}))
+
+		Expect(data["credentialsFound"]).To(Equal("false"))
+		k8sClient.AssertNotCalled(GinkgoT(), "ResourceClient", "v1", "Secret")
+	})
+
+	It("looks up kinds qualified with their group", func() {
+		data := renderedData(NewRenderer(k8sClient, []string{"/Secret"}))
+
+		Expect(data["credentialsFound"]).To(Equal("true"))
+		Expect(data["configMaps"]).To(Equal("0"))
+	})
+
+	It("returns no resources without lookups", func() {
+		data := renderedData(NewRenderer(k8sClient, []string{"ConfigMap", "Secret"}).WithoutLookup())
+
+		Expect(data["settings"]).To(Equal(""))
+		Expect(data["credentialsFound"]).To(Equal("false"))
+		k8sClient.AssertNotCalled(GinkgoT(), "ResourceClient", "v1", "ConfigMap")
+	})
+})
@@ -0,0 +1,83 @@
+package render
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kustomizetypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/yaml"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+const renderedManifestFile = "manifest.yaml"
+
+// applyPatches applies module patches to the rendered manifest with kustomize, which detects the type of
+// each patch
+func applyPatches(manifest string, patches []cyclopsv1alpha1.Patch) (string, error) {
+	if len(patches) == 0 {
+		return manifest, nil
+	}
+
+	kustomization := kustomizetypes.Kustomization{
+		TypeMeta: kustomizetypes.TypeMeta{
+			APIVersion: kustomizetypes.KustomizationVersion,
+			Kind:       kustomizetypes.KustomizationKind,
+		},
+		Resources: []string{renderedManifestFile},
+		Patches:   make([]kustomizetypes.Patch, 0, len(patches)),
+	}
+
+	for _, patch := range patches {
+		kustomization.Patches = append(kustomization.Patches, kustomizetypes.Patch{
+			Patch:  patch.Patch,
+			Target: mapPatchTarget(patch.Target),
+		})
+	}
+
+	kustomizationFile, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return "", err
+	}
+
+	fs := filesys.MakeFsInMemory()
+	if err := fs.WriteFile(renderedManifestFile, []byte(manifest)); err != nil {
+		return "", err
+	}
+	if err := fs.WriteFile(konfig.DefaultKustomizationFileName(), kustomizationFile); err != nil {
+		return "", err
+	}
+
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fs, ".")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to apply module patches")
+	}
+
+	patched, err := resources.AsYaml()
+	if err != nil {
+		return "", err
+	}
+
+	return string(patched), nil
+}
+
+func mapPatchTarget(target *cyclopsv1alpha1.PatchTarget) *kustomizetypes.Selector {
+	if target == nil {
+		return nil
+	}
+
+	return &kustomizetypes.Selector{
+		ResId: resid.ResId{
+			Gvk: resid.Gvk{
+				Group:   target.Group,
+				Version: target.Version,
+				Kind:    target.Kind,
+			},
+			Name:      target.Name,
+			Namespace: target.Namespace,
+		},
+		LabelSelector: target.LabelSelector,
+	}
+}
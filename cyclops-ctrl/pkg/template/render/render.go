@@ -4,10 +4,10 @@ import (
 	"sort"
 	"strings"
 
-	json "github.com/json-iterator/go"
 	helmchart "helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/engine"
+	apiv1 "k8s.io/api/core/v1"
 
 	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
 	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
@@ -17,11 +17,24 @@ import (
 
 type Renderer struct {
 	k8sClient k8sclient.IKubernetesClient
+
+	// lookupKinds lists kinds the Helm lookup function can read from the cluster the module is deployed to. Kinds
+	// can be qualified with their group as in apps/Deployment. Lookups return no resources if it is empty.
+	lookupKinds []string
+}
+
+func NewRenderer(kubernetesClient k8sclient.IKubernetesClient, lookupKinds []string) *Renderer {
+	return &Renderer{
+		k8sClient:   kubernetesClient,
+		lookupKinds: lookupKinds,
+	}
 }
 
-func NewRenderer(kubernetesClient k8sclient.IKubernetesClient) *Renderer {
+// WithoutLookup returns a renderer for previews that shouldn't read cluster state. Lookups of the returned
+// renderer return no resources.
+func (r *Renderer) WithoutLookup() *Renderer {
 	return &Renderer{
-		k8sClient: kubernetesClient,
+		k8sClient: r.k8sClient,
 	}
 }
 
@@ -40,8 +53,8 @@ func (r *Renderer) HelmTemplate(module cyclopsv1alpha1.Module, moduleTemplate *m
 		Templates: moduleTemplate.Templates,
 	}
 
-	values := make(chartutil.Values)
-	if err := json.Unmarshal(module.Spec.Values.Raw, &values); err != nil {
+	values, err := r.moduleValues(module)
+	if err != nil {
 		return "", err
 	}
 
@@ -63,19 +76,27 @@ func (r *Renderer) HelmTemplate(module cyclopsv1alpha1.Module, moduleTemplate *m
 
 	top := make(chartutil.Values)
 	top["Values"] = values
-	top["Release"] = map[string]interface{}{
-		"Name":      module.Name,
-		"Namespace": mapTargetNamespace(module.Spec.TargetNamespace),
-		"Service":   "Helm",
+	top["Release"] = mapRelease(module)
+
+	// capabilities are those of the cluster the module is deployed to
+	k8sClient, err := r.k8sClient.ForCluster(module.Spec.Cluster)
+	if err != nil {
+		return "", err
 	}
 
-	versionInfo, err := r.k8sClient.VersionInfo()
+	versionInfo, err := k8sClient.VersionInfo()
+	if err != nil {
+		return "", err
+	}
+
+	apiVersions, err := k8sClient.APIVersions()
 	if err != nil {
 		return "", err
 	}
 
 	defaultCapabilites := chartutil.DefaultCapabilities
 	top["Capabilities"] = Capabilities{
+		APIVersions: chartutil.VersionSet(apiVersions),
 		KubeVersion: CapabilitiesKubeVersion{
 			Version:    versionInfo.String(),
 			Minor:      versionInfo.Minor,
@@ -85,14 +106,11 @@ func (r *Renderer) HelmTemplate(module cyclopsv1alpha1.Module, moduleTemplate *m
 		HelmVersion: mapCapabilitiesHelmVersion(defaultCapabilites),
 	}
 
-	// TODO fix dependency validation
-	//if len(chart.Schema) != 0 {
-	//	if err := chartutil.ValidateAgainstSchema(chart, values); err != nil {
-	//		return "", err
-	//	}
-	//}
+	if err := validateValues(moduleTemplate, values); err != nil {
+		return "", err
+	}
 
-	out, err := engine.Render(chart, top)
+	out, err := r.render(chart, top, k8sClient, lookupNamespace(module.Spec.TargetNamespace))
 	if err != nil {
 		return "", err
 	}
@@ -116,7 +134,44 @@ func (r *Renderer) HelmTemplate(module cyclopsv1alpha1.Module, moduleTemplate *m
 		manifest += "\n---\n"
 	}
 
-	return manifest, err
+	return applyPatches(manifest, module.Spec.Patches)
+}
+
+func (r *Renderer) render(
+	chart *helmchart.Chart,
+	values chartutil.Values,
+	k8sClient k8sclient.IKubernetesClient,
+	targetNamespace string,
+) (map[string]string, error) {
+	if len(r.lookupKinds) == 0 {
+		return engine.Render(chart, values)
+	}
+
+	return engine.RenderWithClientProvider(chart, values, lookupClientProvider{
+		k8sClient:       k8sClient,
+		allowedKinds:    r.lookupKinds,
+		targetNamespace: targetNamespace,
+	})
+}
+
+// mapRelease maps the module to the Helm release object. The first generation of a module is its install and
+// every later one an upgrade, with the module generation as the release revision.
+func mapRelease(module cyclopsv1alpha1.Module) map[string]interface{} {
+	revision := module.Generation
+	if revision < 1 {
+		revision = 1
+	}
+
+	isUpgrade := revision > 1 || len(module.History) > 0
+
+	return map[string]interface{}{
+		"Name":      module.Name,
+		"Namespace": mapTargetNamespace(module.Spec.TargetNamespace),
+		"Revision":  int(revision),
+		"IsUpgrade": isUpgrade,
+		"IsInstall": !isUpgrade,
+		"Service":   "Helm",
+	}
 }
 
 func mapMetadata(metadata *helm.Metadata) *helmchart.Metadata {
@@ -199,6 +254,16 @@ func mapTargetNamespace(namespace string) string {
 	return namespace
 }
 
+// lookupNamespace returns the namespace module resources without a namespace are applied to, which is the only
+// namespace lookups of namespaced resources can read from
+func lookupNamespace(targetNamespace string) string {
+	if len(strings.TrimSpace(targetNamespace)) == 0 {
+		return apiv1.NamespaceDefault
+	}
+
+	return strings.TrimSpace(targetNamespace)
+}
+
 func mapCapabilitiesHelmVersion(defaultCapabilities *chartutil.Capabilities) CapabilitiesHelmVersion {
 	if defaultCapabilities == nil {
 		return CapabilitiesHelmVersion{}
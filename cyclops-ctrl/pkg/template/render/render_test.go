@@ -0,0 +1,142 @@
+package render
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	helmchart "helm.sh/helm/v3/pkg/chart"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/yaml"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models/helm"
+	k8smocks "github.com/andersan81/cyclops/cyclops-ctrl/pkg/mocks"
+)
+
+func TestRender(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "test template rendering")
+}
+
+var _ = Describe("Rendering module templates", func() {
+	var k8sClient, clusterClient *k8smocks.IKubernetesClient
+
+	template := &models.Template{
+		HelmChartMetadata: &helm.Metadata{Name: "demo"},
+		Templates: []*helmchart.File{
+			{
+				Name: "templates/release.yaml",
+				Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: release
+data:
+  isInstall: "{{ .Release.IsInstall }}"
+  isUpgrade: "{{ .Release.IsUpgrade }}"
+  revision: "{{ .Release.Revision }}"
+  namespace: "{{ .Release.Namespace }}"
+  kubeVersion: "{{ .Capabilities.KubeVersion.Version }}"
+  kubeMinor: "{{ .Capabilities.KubeVersion.Minor }}"
+  hasIngress: "{{ .Capabilities.APIVersions.Has "networking.k8s.io/v1/Ingress" }}"
+  hasGateway: "{{ .Capabilities.APIVersions.Has "gateway.networking.k8s.io/v1" }}"
+`),
+			},
+		},
+	}
+
+	module := func(generation int64, history ...cyclopsv1alpha1.HistoryEntry) cyclopsv1alpha1.Module {
+		return cyclopsv1alpha1.Module{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "team-a", Generation: generation},
+			Spec: cyclopsv1alpha1.ModuleSpec{
+				TargetNamespace: "apps",
+				Cluster:         "staging",
+				Values:          apiextensionsv1.JSON{Raw: []byte(`{}`)},
+			},
+			History: history,
+		}
+	}
+
+	renderedData := func(m cyclopsv1alpha1.Module) map[string]string {
+		manifest, err := NewRenderer(k8sClient, nil).HelmTemplate(m, template)
+		Expect(err).To(BeNil())
+
+		var configMap struct {
+			Data map[string]string `json:"data"`
+		}
+		Expect(yaml.Unmarshal([]byte(manifest[:len(manifest)-len("\n---\n")]), &configMap)).To(Succeed())
+
+		return configMap.Data
+	}
+
+	BeforeEach(func() {
+		k8sClient = &k8smocks.IKubernetesClient{}
+		clusterClient = &k8smocks.IKubernetesClient{}
+
+		k8sClient.On("ForCluster", "staging").Return(clusterClient, nil)
+		clusterClient.On("VersionInfo").Return(&version.Info{Major: "1", Minor: "29", GitVersion: "v1.29.4"}, nil)
+		clusterClient.On("APIVersions").Return([]string{"v1", "apps/v1", "networking.k8s.io/v1", "networking.k8s.io/v1/Ingress"}, nil)
+	})
+
+	Describe("release", func() {
+		type testCase struct {
+			description string
+			module      cyclopsv1alpha1.Module
+			isInstall   string
+			revision    string
+		}
+
+		testCases := []testCase{
+			{
+				description: "installs a module without a generation",
+				module:      module(0),
+				isInstall:   "true",
+				revision:    "1",
+			},
+			{
+				description: "installs the first generation of a module",
+				module:      module(1),
+				isInstall:   "true",
+				revision:    "1",
+			},
+			{
+				description: "upgrades later generations of a module",
+				module:      module(4),
+				isInstall:   "false",
+				revision:    "4",
+			},
+			{
+				description: "upgrades modules with history",
+				module:      module(1, cyclopsv1alpha1.HistoryEntry{Generation: 1}),
+				isInstall:   "false",
+				revision:    "1",
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				data := renderedData(t.module)
+
+				Expect(data["isInstall"]).To(Equal(t.isInstall))
+				Expect(data["isUpgrade"]).ToNot(Equal(t.isInstall))
+				Expect(data["revision"]).To(Equal(t.revision))
+				Expect(data["namespace"]).To(Equal("apps"))
+			})
+		}
+	})
+
+	It("renders with the capabilities of the module cluster", func() {
+		data := renderedData(module(1))
+
+		Expect(data["kubeVersion"]).To(Equal("v1.29.4"))
+		Expect(data["kubeMinor"]).To(Equal("29"))
+		Expect(data["hasIngress"]).To(Equal("true"))
+		Expect(data["hasGateway"]).To(Equal("false"))
+
+		k8sClient.AssertNotCalled(GinkgoT(), "VersionInfo")
+		k8sClient.AssertNotCalled(GinkgoT(), "APIVersions")
+	})
+})
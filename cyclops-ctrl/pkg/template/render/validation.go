@@ -0,0 +1,124 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
+)
+
+// FieldError is a module value that does not match the values schema of the module template
+type FieldError struct {
+	// Field is the dot separated path of the value, e.g. image.tag. Values of dependencies are prefixed with
+	// the dependency name.
+	Field   string
+	Message string
+}
+
+// ValuesValidationError lists all module values that do not match the values schemas of the module template
+// and its dependencies
+type ValuesValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValuesValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		fields = append(fields, fmt.Sprintf("%v: %v", field.Field, field.Message))
+	}
+
+	return fmt.Sprintf("module values don't match the template values schema: %v", strings.Join(fields, "; "))
+}
+
+// ValidateValues validates module values, including values referenced by the module, against the values schema
+// of the template. Values of enabled dependencies are validated against the dependency schemas, scoped to the
+// dependency key. Returns *ValuesValidationError if values are invalid.
+func (r *Renderer) ValidateValues(module cyclopsv1alpha1.Module, moduleTemplate *models.Template) error {
+	if moduleTemplate == nil {
+		return nil
+	}
+
+	values, err := r.moduleValues(module)
+	if err != nil {
+		return err
+	}
+
+	return validateValues(moduleTemplate, values)
+}
+
+func validateValues(moduleTemplate *models.Template, values chartutil.Values) error {
+	fields, err := validateAgainstSchema(moduleTemplate.RawSchema, values, "")
+	if err != nil {
+		return err
+	}
+
+	for _, dependency := range moduleTemplate.Dependencies {
+		if !evaluateDependencyCondition(dependency.Condition, values) {
+			continue
+		}
+
+		// dependency values are scoped to the dependency chart name, as when rendering
+		name := dependency.HelmChartMetadata.Name
+
+		dependencyValues, ok := values[name].(map[string]interface{})
+		if !ok {
+			dependencyValues = map[string]interface{}{}
+		}
+
+		dependencyFields, err := validateAgainstSchema(dependency.RawSchema, dependencyValues, name)
+		if err != nil {
+			return err
+		}
+
+		fields = append(fields, dependencyFields...)
+	}
+
+	if len(fields) != 0 {
+		return &ValuesValidationError{Fields: fields}
+	}
+
+	return nil
+}
+
+func validateAgainstSchema(schema []byte, values map[string]interface{}, prefix string) ([]FieldError, error) {
+	if len(schema) == 0 {
+		return nil, nil
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewGoLoader(values))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to validate values against schema")
+	}
+
+	fields := make([]FieldError, 0, len(result.Errors()))
+	for _, resultError := range result.Errors() {
+		fields = append(fields, FieldError{
+			Field:   fieldPath(prefix, resultError.Field()),
+			Message: resultError.Description(),
+		})
+	}
+
+	return fields, nil
+}
+
+func fieldPath(prefix, field string) string {
+	if field == gojsonschema.STRING_CONTEXT_ROOT {
+		field = ""
+	}
+
+	switch {
+	case len(prefix) == 0 && len(field) == 0:
+		return gojsonschema.STRING_CONTEXT_ROOT
+	case len(prefix) == 0:
+		return field
+	case len(field) == 0:
+		return prefix
+	default:
+		return prefix + "." + field
+	}
+}
@@ -0,0 +1,70 @@
+package render
+
+import (
+	"strings"
+
+	json "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+)
+
+const defaultValuesReferenceKey = "values.yaml"
+
+// moduleValues returns module values with values of ConfigMaps and Secrets referenced by the module merged on top
+// of them. Referenced values are only kept in memory for rendering and are never written to the module.
+func (r *Renderer) moduleValues(module cyclopsv1alpha1.Module) (chartutil.Values, error) {
+	values := make(chartutil.Values)
+	if err := json.Unmarshal(module.Spec.Values.Raw, &values); err != nil {
+		return nil, err
+	}
+
+	for _, reference := range module.Spec.ValuesFrom {
+		if len(reference.Key) == 0 {
+			reference.Key = defaultValuesReferenceKey
+		}
+
+		value, found, err := r.k8sClient.GetValuesReference(r.k8sClient.ModuleNamespace(module.Namespace), reference)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get values from %v %v", reference.Kind, reference.Name)
+		}
+
+		if !found {
+			if reference.Optional {
+				continue
+			}
+
+			return nil, errors.Errorf("key %v of %v %v not found", reference.Key, reference.Kind, reference.Name)
+		}
+
+		if len(reference.TargetPath) != 0 {
+			setValue(values, strings.Split(reference.TargetPath, "."), value)
+			continue
+		}
+
+		referenceValues, err := chartutil.ReadValues([]byte(value))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse values from key %v of %v %v", reference.Key, reference.Kind, reference.Name)
+		}
+
+		values = chartutil.CoalesceTables(referenceValues, values)
+	}
+
+	return values, nil
+}
+
+// setValue sets the value at the path of nested maps, replacing values on the path that are not maps
+func setValue(values map[string]interface{}, path []string, value string) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := values[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			values[key] = next
+		}
+
+		values = next
+	}
+
+	values[path[len(path)-1]] = value
+}
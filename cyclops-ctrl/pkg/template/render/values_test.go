@@ -0,0 +1,149 @@
+package render
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"helm.sh/helm/v3/pkg/chartutil"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	k8smocks "github.com/andersan81/cyclops/cyclops-ctrl/pkg/mocks"
+)
+
+var _ = Describe("Module values", func() {
+	var k8sClient *k8smocks.IKubernetesClient
+
+	configMap := cyclopsv1alpha1.ValuesReference{Kind: cyclopsv1alpha1.ValuesReferenceKindConfigMap, Name: "defaults"}
+	secret := cyclopsv1alpha1.ValuesReference{Kind: cyclopsv1alpha1.ValuesReferenceKindSecret, Name: "credentials"}
+
+	withKey := func(reference cyclopsv1alpha1.ValuesReference) cyclopsv1alpha1.ValuesReference {
+		reference.Key = defaultValuesReferenceKey
+		return reference
+	}
+
+	module := func(values string, valuesFrom ...cyclopsv1alpha1.ValuesReference) cyclopsv1alpha1.Module {
+		return cyclopsv1alpha1.Module{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "team-a"},
+			Spec: cyclopsv1alpha1.ModuleSpec{
+				Values:     apiextensionsv1.JSON{Raw: []byte(values)},
+				ValuesFrom: valuesFrom,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		k8sClient = &k8smocks.IKubernetesClient{}
+		k8sClient.On("ModuleNamespace", "team-a").Return("team-a")
+	})
+
+	Describe("valuesFrom merge precedence", func() {
+		BeforeEach(func() {
+			k8sClient.On("GetValuesReference", "team-a", withKey(configMap)).Return(`
+image:
+  repository: nginx
+  tag: "1.25"
+replicas: 2
+database:
+  host: db.team-a
+`, true, nil)
+			k8sClient.On("GetValuesReference", "team-a", withKey(secret)).Return(`
+replicas: 3
+database:
+  password: secret
+`, true, nil)
+		})
+
+		type testCase struct {
+			description string
+			values      string
+			valuesFrom  []cyclopsv1alpha1.ValuesReference
+			out         chartutil.Values
+		}
+
+		testCases := []testCase{
+			{
+				description: "merges referenced values on top of inline values",
+				values:      `{"replicas": 1, "image": {"tag": "1.24", "pullPolicy": "Always"}, "ingress": true}`,
+				valuesFrom:  []cyclopsv1alpha1.ValuesReference{configMap},
+				out: chartutil.Values{
+					"replicas": float64(2),
+					"image":    map[string]interface{}{"repository": "nginx", "tag": "1.25", "pullPolicy": "Always"},
+					"database": map[string]interface{}{"host": "db.team-a"},
+					"ingress":  true,
+				},
+			},
+			{
+				description: "merges a Secret listed after a ConfigMap on top of the ConfigMap",
+				values:      `{"replicas": 1}`,
+				valuesFrom:  []cyclopsv1alpha1.ValuesReference{configMap, secret},
+				out: chartutil.Values{
+					"replicas": float64(3),
+					"image":    map[string]interface{}{"repository": "nginx", "tag": "1.25"},
+					"database": map[string]interface{}{"host": "db.team-a", "password": "secret"},
+				},
+			},
+			{
+				description: "merges a ConfigMap listed after a Secret on top of the Secret",
+				values:      `{"replicas": 1}`,
+				valuesFrom:  []cyclopsv1alpha1.ValuesReference{secret, configMap},
+				out: chartutil.Values{
+					"replicas": float64(2),
+					"image":    map[string]interface{}{"repository": "nginx", "tag": "1.25"},
+					"database": map[string]interface{}{"host": "db.team-a", "password": "secret"},
+				},
+			},
+		}
+
+		for _, t := range testCases {
+			It(t.description, func() {
+				values, err := NewRenderer(k8sClient, nil).moduleValues(module(t.values, t.valuesFrom...))
+				Expect(err).To(BeNil())
+				Expect(values).To(BeEquivalentTo(t.out))
+			})
+		}
+	})
+
+	It("sets keys with a target path as strings", func() {
+		reference := cyclopsv1alpha1.ValuesReference{
+			Kind:       cyclopsv1alpha1.ValuesReferenceKindSecret,
+			Name:       "credentials",
+			Key:        "password",
+			TargetPath: "database.password",
+		}
+		k8sClient.On("GetValuesReference", "team-a", reference).Return("3", true, nil)
+
+		values, err := NewRenderer(k8sClient, nil).moduleValues(module(`{"database": "external"}`, reference))
+		Expect(err).To(BeNil())
+		Expect(values).To(BeEquivalentTo(chartutil.Values{
+			"database": map[string]interface{}{"password": "3"},
+		}))
+	})
+
+	It("skips missing optional references", func() {
+		optional := configMap
+		optional.Optional = true
+		k8sClient.On("GetValuesReference", "team-a", withKey(optional)).Return("", false, nil)
+
+		values, err := NewRenderer(k8sClient, nil).moduleValues(module(`{"replicas": 1}`, optional))
+		Expect(err).To(BeNil())
+		Expect(values).To(BeEquivalentTo(chartutil.Values{"replicas": float64(1)}))
+	})
+
+	It("fails on missing required references", func() {
+		k8sClient.On("GetValuesReference", "team-a", withKey(configMap)).Return("", false, nil)
+
+		_, err := NewRenderer(k8sClient, nil).moduleValues(module(`{}`, configMap))
+		Expect(err).To(MatchError("key values.yaml of ConfigMap defaults not found"))
+	})
+
+	It("fails if a reference can't be read", func() {
+		k8sClient.On("GetValuesReference", "team-a", mock.Anything).Return("", false, errors.New("forbidden"))
+
+		_, err := NewRenderer(k8sClient, nil).moduleValues(module(`{}`, secret))
+		Expect(err).To(MatchError("failed to get values from Secret credentials: forbidden"))
+	})
+})
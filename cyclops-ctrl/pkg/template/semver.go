@@ -6,6 +6,9 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/pkg/errors"
+
+	cyclopsv1alpha1 "github.com/andersan81/cyclops/cyclops-ctrl/api/v1alpha1"
+	"github.com/andersan81/cyclops/cyclops-ctrl/internal/models"
 )
 
 func isValidVersion(v string) bool {
@@ -53,3 +56,77 @@ func filterVersions(versions []string, constraint *semver.Constraints) ([]*semve
 
 	return validVersions, nil
 }
+
+// IsUpgrade checks if available is a newer version than current. Versions that are not semantic versions,
+// like git commits, are an upgrade whenever they differ.
+func IsUpgrade(current, available string) bool {
+	if len(available) == 0 || current == available {
+		return false
+	}
+
+	currentVersion, currentErr := semver.NewVersion(current)
+	availableVersion, availableErr := semver.NewVersion(available)
+	if currentErr != nil || availableErr != nil {
+		return true
+	}
+
+	return availableVersion.GreaterThan(currentVersion)
+}
+
+// IsUpgradeAllowed checks if an upgrade from current to available is within the given upgrade mode. Upgrades
+// between versions that are not semantic versions are allowed by all modes.
+func IsUpgradeAllowed(current, available string, mode cyclopsv1alpha1.UpgradeMode) bool {
+	if mode == cyclopsv1alpha1.UpgradeModeNone || !IsUpgrade(current, available) {
+		return false
+	}
+
+	currentVersion, currentErr := semver.NewVersion(current)
+	availableVersion, availableErr := semver.NewVersion(available)
+	if currentErr != nil || availableErr != nil {
+		return true
+	}
+
+	switch mode {
+	case cyclopsv1alpha1.UpgradeModePatch:
+		return availableVersion.Major() == currentVersion.Major() && availableVersion.Minor() == currentVersion.Minor()
+	case cyclopsv1alpha1.UpgradeModeMinor:
+		return availableVersion.Major() == currentVersion.Major()
+	case cyclopsv1alpha1.UpgradeModeAny:
+		return true
+	}
+
+	return false
+}
+
+// sortRevisions sorts revisions named by semantic versions from the newest to the oldest. Other revisions are
+// placed after them and keep their order. Prereleases are left out unless includePrerelease is set.
+func sortRevisions(revisions []models.TemplateRevision, includePrerelease bool) []models.TemplateRevision {
+	versions := make(map[string]*semver.Version, len(revisions))
+	filtered := make([]models.TemplateRevision, 0, len(revisions))
+
+	for _, revision := range revisions {
+		version, err := semver.NewVersion(revision.Name)
+		if err == nil {
+			if len(version.Prerelease()) != 0 && !includePrerelease {
+				continue
+			}
+
+			versions[revision.Name] = version
+		}
+
+		filtered = append(filtered, revision)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		vi, iOk := versions[filtered[i].Name]
+		vj, jOk := versions[filtered[j].Name]
+
+		if iOk && jOk {
+			return vi.GreaterThan(vj)
+		}
+
+		return iOk && !jOk
+	})
+
+	return filtered
+}
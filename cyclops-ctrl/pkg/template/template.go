@@ -2,7 +2,6 @@ package template
 
 import (
 	"fmt"
-	gitproviders2 "github.com/andersan81/cyclops/cyclops-ctrl/pkg/template/gitproviders"
 
 	"github.com/andersan81/cyclops/cyclops-ctrl/pkg/auth"
 
@@ -30,7 +29,19 @@ type ITemplateRepo interface {
 		version string,
 		source cyclopsv1alpha1.TemplateSourceType,
 	) (map[string]interface{}, error)
-	GetTemplateRevisions(repo, path string) ([]string, error)
+	GetTemplateRevisions(
+		repo string,
+		path string,
+		source cyclopsv1alpha1.TemplateSourceType,
+		includePrerelease bool,
+	) ([]models.TemplateRevision, error)
+	ResolveVersion(
+		repo string,
+		path string,
+		version string,
+		source cyclopsv1alpha1.TemplateSourceType,
+	) (string, error)
+	AssumeTemplateSourceType(repo string) (cyclopsv1alpha1.TemplateSourceType, error)
 	ReturnCache() *ristretto.Cache
 }
 
@@ -63,7 +74,7 @@ func (r Repo) GetTemplate(
 ) (*models.Template, error) {
 	var err error
 	if len(source) == 0 {
-		source, err = r.assumeTemplateSourceType(repo)
+		source, err = r.AssumeTemplateSourceType(repo)
 		if err != nil {
 			return nil, err
 		}
@@ -99,7 +110,7 @@ func (r Repo) GetTemplateInitialValues(
 ) (map[string]interface{}, error) {
 	var err error
 	if len(source) == 0 {
-		source, err = r.assumeTemplateSourceType(repo)
+		source, err = r.AssumeTemplateSourceType(repo)
 		if err != nil {
 			return nil, err
 		}
@@ -126,6 +137,47 @@ func (r Repo) getTemplateInitialValues(
 	}
 }
 
+// ResolveVersion resolves a template version to the version it currently points to, the commit of a git
+// reference or the latest chart version matching a semver constraint
+func (r Repo) ResolveVersion(
+	repo string,
+	path string,
+	version string,
+	source cyclopsv1alpha1.TemplateSourceType,
+) (string, error) {
+	var err error
+	if len(source) == 0 {
+		source, err = r.AssumeTemplateSourceType(repo)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return "", err
+	}
+
+	switch source {
+	case cyclopsv1alpha1.TemplateSourceTypeOCI:
+		if isValidVersion(version) {
+			return version, nil
+		}
+
+		return getOCIStrictVersion(repo, path, version, creds)
+	case cyclopsv1alpha1.TemplateSourceTypeHelm:
+		if isValidVersion(version) {
+			return version, nil
+		}
+
+		return getRepoStrictVersion(repo, path, version, creds)
+	case cyclopsv1alpha1.TemplateSourceTypeGit:
+		return resolveRef(repo, version, creds)
+	default:
+		return "", errors.New(fmt.Sprintf("unsupported template source: %v", source))
+	}
+}
+
 func (r Repo) loadDependencies(metadata *helm.Metadata) ([]*models.Template, error) {
 	deps := make([]*models.Template, 0)
 	for _, dependency := range metadata.Dependencies {
@@ -164,12 +216,19 @@ func (r Repo) loadDependenciesInitialValues(metadata *helm.Metadata) (map[string
 	return initialValues, nil
 }
 
-func (r Repo) assumeTemplateSourceType(repo string) (cyclopsv1alpha1.TemplateSourceType, error) {
+// AssumeTemplateSourceType returns the source type of templates in the repo for template references without
+// a source type
+func (r Repo) AssumeTemplateSourceType(repo string) (cyclopsv1alpha1.TemplateSourceType, error) {
 	if registry.IsOCI(repo) {
 		return cyclopsv1alpha1.TemplateSourceTypeOCI, nil
 	}
 
-	isHelmRepo, err := IsHelmRepo(repo)
+	creds, err := r.credResolver.RepoAuthCredentials(repo)
+	if err != nil {
+		return "", err
+	}
+
+	isHelmRepo, err := IsHelmRepo(repo, creds)
 	if err != nil {
 		return "", err
 	}
@@ -181,13 +240,20 @@ func (r Repo) assumeTemplateSourceType(repo string) (cyclopsv1alpha1.TemplateSou
 	return cyclopsv1alpha1.TemplateSourceTypeGit, nil
 }
 
-func (r Repo) GetTemplateRevisions(repo, path string) ([]string, error) {
-	if registry.IsOCI(repo) {
-		return GetOCIChartTags(repo, path)
-	}
+// GetTemplateRevisions lists versions the template can be referenced by. The source type is assumed from the
+// repo if not set.
+func (r Repo) GetTemplateRevisions(
+	repo, path string,
+	source cyclopsv1alpha1.TemplateSourceType,
+	includePrerelease bool,
+) ([]models.TemplateRevision, error) {
+	if len(source) == 0 {
+		assumed, err := r.AssumeTemplateSourceType(repo)
+		if err != nil {
+			return nil, err
+		}
 
-	if !gitproviders2.IsGitHubSource(repo) {
-		return nil, nil
+		source = assumed
 	}
 
 	creds, err := r.credResolver.RepoAuthCredentials(repo)
@@ -195,7 +261,27 @@ func (r Repo) GetTemplateRevisions(repo, path string) ([]string, error) {
 		return nil, err
 	}
 
-	return r.listRemoteRefs(repo, creds)
+	switch source {
+	case cyclopsv1alpha1.TemplateSourceTypeOCI:
+		tags, err := GetOCIChartTags(repo, path, creds)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions := make([]models.TemplateRevision, 0, len(tags))
+		for _, tag := range tags {
+			revisions = append(revisions, models.TemplateRevision{
+				Name: tag,
+				Type: models.TemplateRevisionTypeVersion,
+			})
+		}
+
+		return sortRevisions(revisions, includePrerelease), nil
+	case cyclopsv1alpha1.TemplateSourceTypeHelm:
+		return listHelmChartVersions(repo, path, creds, includePrerelease)
+	default:
+		return r.listRemoteRefs(repo, creds, includePrerelease)
+	}
 }
 
 func (r Repo) ReturnCache() *ristretto.Cache {
@@ -30,6 +30,7 @@ const ModuleHistory = () => {
   const [diffModal, setDiffModal] = useState({
     open: false,
     generation: 0,
+    manifestHash: "",
   });
 
   const [manifest, setManifest] = useState("");
@@ -68,12 +69,14 @@ const ModuleHistory = () => {
     setDiffModal({
       open: false,
       generation: 0,
+      manifestHash: "",
     });
 
     axios
       .post(`/api/modules/rollback`, {
         moduleName: moduleName,
         generation: diffModal.generation,
+        manifestHash: diffModal.manifestHash,
       })
       .then((res) => {
         window.location.href = "/modules/" + moduleName;
@@ -87,6 +90,7 @@ const ModuleHistory = () => {
     setDiffModal({
       open: false,
       generation: 0,
+      manifestHash: "",
     });
   };
 
@@ -98,18 +102,14 @@ const ModuleHistory = () => {
   };
 
   const openRollbackModal = (text: any, record: any, index: any) => {
-    let target: any = {};
-    historyEntries.forEach((h: any) => {
-      if (h.generation === record.generation) {
-        target = h;
-      }
-    });
 
     setLoadingDiff(true);
     axios
       .post("/api/modules/rollback/manifest", {
         moduleName: moduleName,
-        generation: target.generation,
+        generation: record.generation,
+        // generations can have several revisions, told apart by their manifest hash
+        manifestHash: record.manifestHash,
       })
       .then(function (res) {
         setDiff({
@@ -127,21 +127,18 @@ const ModuleHistory = () => {
     setDiffModal({
       open: true,
       generation: record.generation,
+      manifestHash: record.manifestHash,
     });
   };
 
   const openManifestModal = (text: any, record: any, index: any) => {
-    let target: any = {};
-    historyEntries.forEach((h: any) => {
-      if (h.generation === record.generation) {
-        target = h;
-      }
-    });
 
     axios
       .post("/api/modules/rollback/manifest", {
         moduleName: moduleName,
-        generation: target.generation,
+        generation: record.generation,
+        // generations can have several revisions, told apart by their manifest hash
+        manifestHash: record.manifestHash,
       })
       .then(function (res) {
         setManifest(res.data);
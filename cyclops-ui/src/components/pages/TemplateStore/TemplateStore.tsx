@@ -40,6 +40,12 @@ import dockerLogo from "../../../static/img/docker-mark-blue.png";
 import { useTheme } from "../../theme/ThemeContext";
 import Title from "antd/es/typography/Title";
 
+interface TemplateRevision {
+  name: string;
+  type: "branch" | "tag" | "version";
+  date?: string;
+}
+
 const TemplateStore = () => {
   const { mode } = useTheme();
 
@@ -67,7 +73,9 @@ const TemplateStore = () => {
   const [templateSourceTypeFilter, setTemplateSourceTypeFilter] =
     useState<string[]>(sourceTypeFilter);
 
-  const [repoRevisions, setRepoRevisions] = useState<string[]>([]);
+  const [repoRevisions, setRepoRevisions] = useState<TemplateRevision[]>(
+    [],
+  );
   const [repoRevisionOptions, setRepoRevisionOptions] = useState([]);
 
   const [addForm] = Form.useForm();
@@ -353,29 +361,35 @@ const TemplateStore = () => {
   const fetchRepoRevisions = () => {
     const repo = addForm.getFieldValue(["ref", "repo"]);
     const path = addForm.getFieldValue(["ref", "path"]);
+    const sourceType = addForm.getFieldValue(["ref", "sourceType"]) || "";
 
     axios
-      .get(`/api/templates/revisions?repo=${repo}&path=${path}`)
+      .get(
+        `/api/templates/revisions?repo=${repo}&path=${path}&sourceType=${sourceType}`,
+      )
       .then((res) => {
         setRepoRevisions(res.data);
-
-        const filtered = res.data.map((item) => ({ value: item }));
-        setRepoRevisionOptions(filtered);
+        setRepoRevisionOptions(res.data.map(mapRevisionOption));
       })
       .catch(() => {});
   };
 
+  const mapRevisionOption = (revision: TemplateRevision) => ({
+    value: revision.name,
+    label: revision.date
+      ? `${revision.name} (${revision.type}, ${new Date(revision.date).toLocaleDateString()})`
+      : `${revision.name} (${revision.type})`,
+  });
+
   const handleVersionInput = (value) => {
     if (repoRevisions.length === 0) {
       setRepoRevisionOptions([]);
       return;
     }
 
-    console.log(value);
-
     const filtered = repoRevisions
-      .filter((item) => item.toLowerCase().includes(value.toLowerCase()))
-      .map((item) => ({ value: item }));
+      .filter((item) => item.name.toLowerCase().includes(value.toLowerCase()))
+      .map(mapRevisionOption);
     setRepoRevisionOptions(filtered);
   };
 
@@ -13,7 +13,10 @@ cyctl get modules
 cyctl get templates
 
 # List all template auth rules available in ps format
-cyctl get templateauthrules`
+cyctl get templateauthrules
+
+# List revisions of previous generations of a module
+cyctl get revisions MODULE_NAME`
 )
 
 var getCMD = &cobra.Command{
@@ -29,6 +32,7 @@ func init() {
 	getCMD.AddCommand(get.ListResources)
 	getCMD.AddCommand(get.ListTemplate)
 	getCMD.AddCommand(get.ListTemplateAuthRules)
+	getCMD.AddCommand(get.ListRevisions)
 
 	RootCmd.AddCommand(getCMD)
 }
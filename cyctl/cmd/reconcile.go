@@ -10,8 +10,8 @@ import (
 	"time"
 )
 
-func reconcileModule(clientset *client.CyclopsV1Alpha1Client, moduleName string) {
-	module, err := clientset.Modules("cyclops").Get(moduleName)
+func reconcileModule(clientset *client.CyclopsV1Alpha1Client, namespace, moduleName string) {
+	module, err := clientset.Modules(namespace).Get(moduleName)
 	if err != nil {
 		fmt.Println("Failed to fetch the module ", err)
 		return
@@ -27,7 +27,7 @@ func reconcileModule(clientset *client.CyclopsV1Alpha1Client, moduleName string)
 		Kind:       "Module",
 	}
 
-	_, err = clientset.Modules("cyclops").Update(module)
+	_, err = clientset.Modules(namespace).Update(module)
 	if err != nil {
 		fmt.Println("failed to update module: ", err)
 		return
@@ -38,7 +38,10 @@ func reconcileModule(clientset *client.CyclopsV1Alpha1Client, moduleName string)
 
 var (
 	reconcileExample = `# Reconcile a Module 
-	cyctl reconcile <modulename>`
+	cyctl reconcile <modulename>
+
+	# Reconcile a Module in a specific namespace
+	cyctl reconcile <modulename> -n <namespace>`
 )
 
 var reconcileCMD = &cobra.Command{
@@ -49,11 +52,18 @@ var reconcileCMD = &cobra.Command{
 	Args:    cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		moduleName := args[0]
-		reconcileModule(kubeconfig.Moduleset, moduleName)
+		namespace, err := cmd.Flags().GetString("namespace")
+		if err != nil {
+			fmt.Println("failed to get value of flag --namespace: ", err)
+			return
+		}
+
+		reconcileModule(kubeconfig.Moduleset, namespace, moduleName)
 	},
 }
 
 func init() {
 	RootCmd.AddCommand(reconcileCMD)
+	reconcileCMD.Flags().StringP("namespace", "n", "cyclops", "namespace of the module")
 
 }
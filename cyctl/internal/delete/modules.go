@@ -13,18 +13,26 @@ var (
 cyctl delete modules module1
 
 # Delete multiple modules
-cyctl delete modules module1 module2 module3`
+cyctl delete modules module1 module2 module3
+
+# Delete a module from a specific namespace
+cyctl delete modules module1 -n team-a`
+)
+
+var (
+	// Namespace of the deleted modules
+	namespace string
 )
 
 // DeleteModules deletes a specified module from the Cyclops API.
-func deleteModules(clientset *client.CyclopsV1Alpha1Client, moduleNames []string) {
+func deleteModules(clientset *client.CyclopsV1Alpha1Client, namespace string, moduleNames []string) {
 	if len(moduleNames) == 0 {
 		fmt.Println("Error: module names cannot be empty")
 		return
 	}
 
 	for _, moduleName := range moduleNames {
-		err := clientset.Modules("cyclops").Delete(moduleName)
+		err := clientset.Modules(namespace).Delete(moduleName)
 		if err != nil {
 			fmt.Printf("Error from server (NotFound): %v\n", err)
 		} else {
@@ -42,7 +50,11 @@ var (
 		Aliases: []string{"module"},
 		Args:    cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			deleteModules(kubeconfig.Moduleset, args)
+			deleteModules(kubeconfig.Moduleset, namespace, args)
 		},
 	}
 )
+
+func init() {
+	DeleteModule.Flags().StringVarP(&namespace, "namespace", "n", "cyclops", "Namespace of the modules")
+}
@@ -15,13 +15,21 @@ var (
 cyctl describe modules module1
 
 # Describe multiple modules
-cyctl describe modules module1 module2 module3`
+cyctl describe modules module1 module2 module3
+
+# Describe a module in a specific namespace
+cyctl describe modules module1 -n team-a`
+)
+
+var (
+	// Namespace of the described modules
+	namespace string
 )
 
 // describeModules describe specified module from the Cyclops API.
-func describeModules(clientset *client.CyclopsV1Alpha1Client, moduleNames []string) {
+func describeModules(clientset *client.CyclopsV1Alpha1Client, namespace string, moduleNames []string) {
 	if len(moduleNames) == 0 {
-		modules, err := clientset.Modules("cyclops").List(v1.ListOptions{})
+		modules, err := clientset.Modules(namespace).List(v1.ListOptions{})
 		if err != nil {
 			fmt.Printf("Error fetching modules: %v\n", err)
 			return
@@ -32,7 +40,7 @@ func describeModules(clientset *client.CyclopsV1Alpha1Client, moduleNames []stri
 	}
 
 	for _, moduleName := range moduleNames {
-		module, err := clientset.Modules("cyclops").Get(moduleName)
+		module, err := clientset.Modules(namespace).Get(moduleName)
 		if err != nil {
 			fmt.Printf("Error from server (NotFound): %v\n", err)
 			return
@@ -80,7 +88,11 @@ var (
 		Aliases: []string{"module"},
 		Args:    cobra.MinimumNArgs(0),
 		Run: func(cmd *cobra.Command, args []string) {
-			describeModules(kubeconfig.Moduleset, args)
+			describeModules(kubeconfig.Moduleset, namespace, args)
 		},
 	}
 )
+
+func init() {
+	DescribeModule.Flags().StringVarP(&namespace, "namespace", "n", "cyclops", "Namespace of the modules")
+}
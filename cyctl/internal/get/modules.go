@@ -18,12 +18,30 @@ var (
 cyctl get module MODULE_NAME 
 
 # List all modules available in ps output format
-cyctl get modules`
+cyctl get modules
+
+# List modules in a specific namespace
+cyctl get modules -n team-a
+
+# List modules in all namespaces
+cyctl get modules -A`
+)
+
+var (
+	// Namespace of the listed modules
+	namespace string
+
+	// List modules across all namespaces
+	allNamespaces bool
 )
 
 // listModules retrieves and displays a list of modules from the Cyclops API.
-func listModules(clientset *client.CyclopsV1Alpha1Client, moduleNames []string) {
-	modules, err := clientset.Modules("cyclops").List(metav1.ListOptions{})
+func listModules(clientset *client.CyclopsV1Alpha1Client, namespace string, allNamespaces bool, moduleNames []string) {
+	if allNamespaces {
+		namespace = metav1.NamespaceAll
+	}
+
+	modules, err := clientset.Modules(namespace).List(metav1.ListOptions{})
 	if err != nil {
 		fmt.Printf("Error listing modules: %v\n", err)
 		return
@@ -68,9 +86,19 @@ func listModules(clientset *client.CyclopsV1Alpha1Client, moduleNames []string)
 		filteredModules = foundModules
 	}
 
+	longestNamespace := 20 // minimum column width
+	for _, module := range modules {
+		if len(module.Namespace) > longestNamespace {
+			longestNamespace = len(module.Namespace)
+		}
+	}
+
 	headerSpacing := max(0, longestName-4)
 	output := ""
 	if len(filteredModules) > 0 {
+		if allNamespaces {
+			output += "NAMESPACE" + strings.Repeat(" ", max(0, longestNamespace-9)) + " "
+		}
 		output += "NAME" + strings.Repeat(" ", headerSpacing) + " AGE\n"
 	}
 
@@ -78,6 +106,9 @@ func listModules(clientset *client.CyclopsV1Alpha1Client, moduleNames []string)
 	for _, module := range filteredModules {
 		age := time.Since(module.CreationTimestamp.Time).Round(time.Second)
 		nameSpacing := max(0, longestName-len(module.Name))
+		if allNamespaces {
+			fmt.Printf("%s"+strings.Repeat(" ", max(0, longestNamespace-len(module.Namespace)))+" ", module.Namespace)
+		}
 		fmt.Printf("%s"+strings.Repeat(" ", nameSpacing)+" %s\n", module.Name, age.String())
 	}
 	if len(notFoundModules) > 0 {
@@ -94,7 +125,12 @@ var (
 		Args:    cobra.MinimumNArgs(0),
 		Aliases: []string{"module"},
 		Run: func(cmd *cobra.Command, args []string) {
-			listModules(kubeconfig.Moduleset, args)
+			listModules(kubeconfig.Moduleset, namespace, allNamespaces, args)
 		},
 	}
 )
+
+func init() {
+	ListModule.Flags().StringVarP(&namespace, "namespace", "n", "cyclops", "Namespace of the modules")
+	ListModule.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "List modules across all namespaces")
+}
@@ -0,0 +1,108 @@
+package get
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cyclops-ui/cycops-cyctl/internal/kubeconfig"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+var (
+	revisionsExample = `# List revisions of previous generations of a module
+cyctl get revisions MODULE_NAME
+
+# List revisions of a module in a specific namespace
+cyctl get revisions MODULE_NAME -n team-a`
+)
+
+var (
+	// Namespace of the module the revisions are listed for
+	revisionsNamespace string
+
+	moduleRevisionsGVR = schema.GroupVersionResource{
+		Group:    "cyclops-ui.com",
+		Version:  "v1alpha1",
+		Resource: "modulerevisions",
+	}
+)
+
+// listRevisions retrieves and displays revisions of previous module generations, newest first
+func listRevisions(config *rest.Config, namespace string, moduleNames []string) {
+	if len(moduleNames) != 1 {
+		fmt.Println("Please pass a single module name in args")
+		return
+	}
+
+	dyn, err := dynamic.NewForConfig(config)
+	if err != nil {
+		fmt.Printf("Error creating client: %v\n", err)
+		return
+	}
+
+	revisions, err := dyn.Resource(moduleRevisionsGVR).Namespace(namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("cyclops-ui.com/module=%s", moduleNames[0]),
+	})
+	if err != nil {
+		fmt.Printf("Error listing module revisions: %v\n", err)
+		return
+	}
+
+	if len(revisions.Items) == 0 {
+		fmt.Println("No revisions found.")
+		return
+	}
+
+	items := revisions.Items
+	sort.Slice(items, func(i, j int) bool {
+		return revisionGeneration(items[i]) > revisionGeneration(items[j])
+	})
+
+	maxVersionLen := len("TEMPLATE VERSION")
+	maxAuthorLen := len("AUTHOR")
+	for _, revision := range items {
+		version, _, _ := unstructured.NestedString(revision.Object, "spec", "template", "version")
+		author, _, _ := unstructured.NestedString(revision.Object, "spec", "author")
+		maxVersionLen = max(maxVersionLen, len(version))
+		maxAuthorLen = max(maxAuthorLen, len(author))
+	}
+
+	fmt.Printf("%-12s %-*s %-*s %s\n", "GENERATION", maxVersionLen, "TEMPLATE VERSION", maxAuthorLen, "AUTHOR", "AGE")
+	for _, revision := range items {
+		version, _, _ := unstructured.NestedString(revision.Object, "spec", "template", "version")
+		author, _, _ := unstructured.NestedString(revision.Object, "spec", "author")
+		age := time.Since(revision.GetCreationTimestamp().Time).Round(time.Second)
+
+		fmt.Printf("%-12d %-*s %-*s %s\n", revisionGeneration(revision), maxVersionLen, version, maxAuthorLen, author, age.String())
+	}
+}
+
+func revisionGeneration(revision unstructured.Unstructured) int64 {
+	generation, _, _ := unstructured.NestedInt64(revision.Object, "spec", "generation")
+	return generation
+}
+
+var (
+	ListRevisions = &cobra.Command{
+		Use:     "revisions [module_name]",
+		Short:   "Retrieve list of revisions of a module in ps format",
+		Long:    "Retrieve list of revisions of previous generations of a module in ps format",
+		Example: revisionsExample,
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"revision"},
+		Run: func(cmd *cobra.Command, args []string) {
+			listRevisions(kubeconfig.Config, revisionsNamespace, args)
+		},
+	}
+)
+
+func init() {
+	ListRevisions.Flags().StringVarP(&revisionsNamespace, "namespace", "n", "cyclops", "Namespace of the module")
+}
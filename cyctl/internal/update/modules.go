@@ -17,12 +17,15 @@ var (
 	updateModuleExample = `# updates module values; takes module name as an argument with flag --value
 # to update replicas and version for a module named test
 cyctl update module test --value="scaling.replicas=3" --value="general.version=1.27.1"
+
+# to update a module named test in the team-a namespace
+cyctl update module test -n team-a --value="scaling.replicas=3"
 	`
 )
 
 // updates the given module from cyclops API
-func updateModule(clientset *client.CyclopsV1Alpha1Client, moduleName string, values []string) {
-	module, err := clientset.Modules("cyclops").Get(moduleName)
+func updateModule(clientset *client.CyclopsV1Alpha1Client, namespace, moduleName string, values []string) {
+	module, err := clientset.Modules(namespace).Get(moduleName)
 	if err != nil {
 		fmt.Println("Failed to fetch module ", err)
 		return
@@ -63,7 +66,7 @@ func updateModule(clientset *client.CyclopsV1Alpha1Client, moduleName string, va
 		Kind:       "Module",
 	}
 
-	_, err = clientset.Modules("cyclops").Update(module)
+	_, err = clientset.Modules(namespace).Update(module)
 	if err != nil {
 		fmt.Println("failed to update module: ", err)
 		return
@@ -86,12 +89,19 @@ var (
 				return
 			}
 
-			updateModule(kubeconfig.Moduleset, args[0], values)
+			namespace, err := cmd.Flags().GetString("namespace")
+			if err != nil {
+				fmt.Println("failed to get value of flag --namespace: ", err)
+				return
+			}
+
+			updateModule(kubeconfig.Moduleset, namespace, args[0], values)
 		},
 	}
 )
 
 func init() {
 	UpdateModuleCMD.Flags().StringArrayP("value", "v", []string{}, "key value pair to update module")
+	UpdateModuleCMD.Flags().StringP("namespace", "n", "cyclops", "namespace of the module")
 	UpdateModuleCMD.MarkFlagRequired("value")
 }
@@ -23,6 +23,13 @@ cyclops
 {{- end -}}
 {{- end -}}
 
+{{- define "cyclops-ctrl.watchNamespaces" -}}
+{{- if and (not ((.Values.global).singleNamespaceScope).enabled) (.Values.ctrl).watchNamespaces }}
+- name: WATCH_NAMESPACES
+  value: {{ join "," .Values.ctrl.watchNamespaces | quote }}
+{{- end -}}
+{{- end -}}
+
 {{- define "cyclops-ctrl.moduleTargetNamespace" -}}
 {{- if ((.Values.global).singleNamespaceScope).enabled }}
 - name: MODULE_TARGET_NAMESPACE